@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+var allowedKeys = flag.String("allowed-keys", "", "file holding a JSON skeleton or JSON Schema; it is an error if any value has an object key not present in it")
+
+// A keyTemplate describes the object keys allowed within a value.
+// A nil *keyTemplate allows any keys.
+type keyTemplate struct {
+	// keys holds the allowed keys of an object value
+	// and the templates for their values.
+	keys map[string]*keyTemplate
+	// extra holds whether keys not in keys are allowed,
+	// and extraTemplate holds the template for their values.
+	extra         bool
+	extraTemplate *keyTemplate
+	// elem holds the template for the elements of an array value.
+	elem *keyTemplate
+}
+
+// loadKeyTemplate reads the template for -allowed-keys from the
+// named file. The file holds either a JSON Schema (an object
+// with a $schema member, or a "type" of "object" or "array")
+// or a skeleton value in which each object holds the allowed keys
+// and the first element of each array is the template for all
+// its elements.
+func loadKeyTemplate(file string) (*keyTemplate, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", file, err)
+	}
+	if isSchema(v) {
+		return schemaKeyTemplate(v), nil
+	}
+	return skeletonKeyTemplate(v), nil
+}
+
+func isSchema(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := m["$schema"]; ok {
+		return true
+	}
+	return m["type"] == "object" || m["type"] == "array"
+}
+
+func skeletonKeyTemplate(v interface{}) *keyTemplate {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		t := &keyTemplate{
+			keys: make(map[string]*keyTemplate),
+		}
+		for k, elem := range v {
+			t.keys[k] = skeletonKeyTemplate(elem)
+		}
+		return t
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		return &keyTemplate{
+			extra: true,
+			elem:  skeletonKeyTemplate(v[0]),
+		}
+	}
+	return nil
+}
+
+// schemaKeyTemplate returns the template for a JSON Schema.
+// Unlike in JSON Schema itself, an object schema with properties
+// allows no other keys unless additionalProperties or
+// patternProperties is specified.
+func schemaKeyTemplate(v interface{}) *keyTemplate {
+	schema, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	t := &keyTemplate{
+		extra: true,
+	}
+	constrained := false
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		constrained = true
+		t.keys = make(map[string]*keyTemplate)
+		t.extra = false
+		for k, prop := range props {
+			t.keys[k] = schemaKeyTemplate(prop)
+		}
+	}
+	switch extra := schema["additionalProperties"].(type) {
+	case bool:
+		constrained = constrained || !extra
+		t.extra = extra
+	case map[string]interface{}:
+		constrained = true
+		t.extra = true
+		t.extraTemplate = schemaKeyTemplate(extra)
+	}
+	if _, ok := schema["patternProperties"]; ok {
+		t.extra = true
+		t.extraTemplate = nil
+	}
+	if items, ok := schema["items"]; ok {
+		constrained = true
+		t.elem = schemaKeyTemplate(items)
+	}
+	if !constrained {
+		return nil
+	}
+	return t
+}
+
+// unknownKeys appends to found a description of each object key
+// in v, which is at the given JSON Pointer path, that is not
+// allowed by the template.
+func (t *keyTemplate) unknownKeys(v interface{}, path string, found []string) []string {
+	if t == nil {
+		return found
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(v) {
+			elemPath := path + "/" + pointerEscaper.Replace(k)
+			elemTemplate, ok := t.keys[k]
+			switch {
+			case ok:
+			case t.extra:
+				elemTemplate = t.extraTemplate
+			default:
+				if alt := t.closestKey(k); alt != "" {
+					elemPath += fmt.Sprintf(" (did you mean %q?)", alt)
+				}
+				found = append(found, elemPath)
+				continue
+			}
+			found = elemTemplate.unknownKeys(v[k], elemPath, found)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			found = t.elem.unknownKeys(elem, fmt.Sprintf("%s/%d", path, i), found)
+		}
+	}
+	return found
+}
+
+// closestKey returns the allowed key closest to k, or the empty
+// string if there is none within an edit distance of 2. Short keys
+// need to be closer, so that x is not suggested for y.
+func (t *keyTemplate) closestKey(k string) string {
+	names := make([]string, 0, len(t.keys))
+	for name := range t.keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	best, bestDist := "", 3
+	for _, name := range names {
+		if d := editDistance(k, name); d < bestDist && d <= len(name)/3 {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// checkAllowedKeys returns an error naming any object keys
+// in the values that are not allowed by the template.
+func checkAllowedKeys(vals []interface{}, t *keyTemplate) error {
+	for i, v := range vals {
+		unknown := t.unknownKeys(v, "", nil)
+		if len(unknown) == 0 {
+			continue
+		}
+		what := "value"
+		if len(vals) > 1 {
+			what = fmt.Sprintf("value %d", i)
+		}
+		return fmt.Errorf("%s has unknown keys: %s", what, strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var allowedKeysTests = []struct {
+	testName    string
+	template    string
+	args        string
+	expectError string
+}{{
+	testName: "skeleton-ok",
+	template: `{"spec": {"replicas": 1, "ports": [{"port": 80}]}}`,
+	args:     "spec: [ replicas: 3 ports: .[ [ port: 80 ] [ port: 443 ] ] ]",
+}, {
+	testName:    "skeleton-typo",
+	template:    `{"spec": {"replicas": 1}}`,
+	args:        "spec: [ replcas: 3 ]",
+	expectError: `value has unknown keys: /spec/replcas \(did you mean "replicas"\?\)`,
+}, {
+	testName:    "skeleton-array-element",
+	template:    `{"ports": [{"port": 80}]}`,
+	args:        "ports: .[ [ port: 80 ] [ prot: 443 name: x ] ]",
+	expectError: `value has unknown keys: /ports/1/name, /ports/1/prot \(did you mean "port"\?\)`,
+}, {
+	testName: "skeleton-scalar-allows-anything",
+	template: `{"labels": null}`,
+	args:     "labels: [ a: 1 b: 2 ]",
+}, {
+	testName:    "multiple-values",
+	template:    `{"a": 1}`,
+	args:        "[ a: 1 ] [ b: 2 ]",
+	expectError: `value 1 has unknown keys: /b`,
+}, {
+	testName: "schema-ok",
+	template: `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "object", "properties": {"k": {}}}}
+		}
+	}`,
+	args: "name: x tags: .[ [ k: 1 ] ]",
+}, {
+	testName: "schema-unknown",
+	template: `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "object", "properties": {"k": {}}}}
+		}
+	}`,
+	args:        "tags: .[ [ k: 1 v: 2 ] ]",
+	expectError: `value has unknown keys: /tags/0/v`,
+}, {
+	testName: "schema-additional-properties",
+	template: `{
+		"type": "object",
+		"properties": {"a": {}},
+		"additionalProperties": {"type": "object", "properties": {"x": {}}}
+	}`,
+	args:        "a: 1 b: [ x: 1 ] c: [ y: 2 ]",
+	expectError: `value has unknown keys: /c/y`,
+}, {
+	testName: "schema-additional-properties-true",
+	template: `{"type": "object", "properties": {"a": {}}, "additionalProperties": true}`,
+	args:     "a: 1 b: 2",
+}, {
+	testName:    "schema-additional-properties-false",
+	template:    `{"type": "object", "additionalProperties": false}`,
+	args:        "a: 1",
+	expectError: `value has unknown keys: /a`,
+}, {
+	testName:    "escaped-key",
+	template:    `{"a": 1}`,
+	args:        "a/b: 1",
+	expectError: `value has unknown keys: /a~1b`,
+}}
+
+func TestAllowedKeys(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range allowedKeysTests {
+		c.Run(test.testName, func(c *qt.C) {
+			file := filepath.Join(c.Mkdir(), "template.json")
+			err := ioutil.WriteFile(file, []byte(test.template), 0666)
+			c.Assert(err, qt.Equals, nil)
+			tmpl, err := loadKeyTemplate(file)
+			c.Assert(err, qt.Equals, nil)
+			vals, err := newParser(strings.Fields(test.args)).run()
+			c.Assert(err, qt.Equals, nil)
+			err = checkAllowedKeys(vals, tmpl)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+		})
+	}
+}
+
+func TestLoadKeyTemplateError(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "template.json")
+	err := ioutil.WriteFile(file, []byte(`{`), 0666)
+	c.Assert(err, qt.Equals, nil)
+	_, err = loadKeyTemplate(file)
+	c.Assert(err, qt.ErrorMatches, `cannot parse .*template.json: unexpected end of JSON input`)
+}
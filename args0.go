@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+var args0 = flag.Bool("args0", false, "read arguments from standard input, each terminated by a NUL byte as produced by find -print0, before those on the command line")
+
+// readArgs0 reads NUL-terminated arguments from r.
+// The terminator after the final argument may be omitted.
+func readArgs0(r io.Reader) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00"), nil
+}
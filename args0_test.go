@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var readArgs0Tests = []struct {
+	testName string
+	input    string
+	expect   []string
+}{{
+	testName: "empty",
+	input:    "",
+	expect:   nil,
+}, {
+	testName: "terminated",
+	input:    "a:\x00x y\nz\x00",
+	expect:   []string{"a:", "x y\nz"},
+}, {
+	testName: "unterminated",
+	input:    "a:\x001",
+	expect:   []string{"a:", "1"},
+}, {
+	testName: "empty-argument",
+	input:    "a:\x00\x00",
+	expect:   []string{"a:", ""},
+}}
+
+func TestReadArgs0(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range readArgs0Tests {
+		c.Run(test.testName, func(c *qt.C) {
+			args, err := readArgs0(strings.NewReader(test.input))
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(args, qt.DeepEquals, test.expect)
+		})
+	}
+}
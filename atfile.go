@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// expandArg returns the literal argument a at the given index
+// or, if it has the form @path, the contents of the named file
+// with any single trailing newline removed. A leading "@@"
+// stands for a literal "@", an argument of "stdin" stands
+// for the contents of standard input, "env NAME" stands
+// for the value of the named environment variable,
+// "cmd COMMAND" stands for the output of the command, and
+// "http URL" or "fetch URL" stands for the response body.
+func (p *parser) expandArg(a string, index int) (string, error) {
+	if p.check {
+		return p.checkArg(a, index)
+	}
+	switch a {
+	case "lazy":
+		return "", lazyArgError(index)
+	case "http", "fetch":
+		return p.expandFetch(a, index)
+	case "stdin":
+		return p.readStdin(index)
+	case "env":
+		return p.expandEnv(index)
+	case "cmd":
+		return p.expandCmd(index)
+	}
+	if len(a) < 2 || a[0] != '@' {
+		return a, nil
+	}
+	if a[1] == '@' {
+		return a[1:], nil
+	}
+	if p.hermetic {
+		return "", fmt.Errorf("file reference %q at argument %d is not allowed because it performs I/O", a, index)
+	}
+	v, err := p.readAhead("@", index, func() (interface{}, error) {
+		return readAtFile(a[1:], index)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// readAtFile returns the contents of the file named by an @file
+// reference at the given argument index, with any single trailing
+// newline removed.
+func readAtFile(path string, index int) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read file at argument %d: %v", index, err)
+	}
+	return trimNewline(string(data)), nil
+}
+
+// trimNewline removes any single trailing newline from s.
+func trimNewline(s string) string {
+	if strings.HasSuffix(s, "\r\n") {
+		return s[:len(s)-2]
+	}
+	return strings.TrimSuffix(s, "\n")
+}
+
+// evalFile implements the file keyword. Unlike an @file
+// reference, the contents are used exactly as read.
+func evalFile(args []string, index int, _ interface{}) (interface{}, error) {
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file %q at argument %d: %v", args[0], index, err)
+	}
+	return string(data), nil
+}
+
+// evalBase64File implements the base64file keyword.
+func evalBase64File(args []string, index int, _ interface{}) (interface{}, error) {
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file %q at argument %d: %v", args[0], index, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// evalJSONFile implements the jsonfile keyword. The file is
+// decoded as it is read rather than being read into memory first.
+func evalJSONFile(args []string, index int, _ interface{}) (interface{}, error) {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file %q at argument %d: %v", args[0], index, err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(bufio.NewReader(f))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal json file %q at argument %d: %v", args[0], index, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("cannot unmarshal json file %q at argument %d: unexpected data after value", args[0], index)
+	}
+	return x, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var atFileTests = []struct {
+	testName    string
+	args        string
+	hermetic    bool
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "bare",
+	args:     "a: @text",
+	expect:   []interface{}{map[string]interface{}{"a": "hello [ world"}},
+}, {
+	testName: "bare-number-is-string",
+	args:     "@port",
+	expect:   []interface{}{"8080"},
+}, {
+	testName: "num",
+	args:     "num @port",
+	expect:   []interface{}{json.Number("8080")},
+}, {
+	testName: "json",
+	args:     "json @data.json",
+	expect:   []interface{}{map[string]interface{}{"x": json.Number("1")}},
+}, {
+	testName: "crlf",
+	args:     "@crlf",
+	expect:   []interface{}{"a\r\nb"},
+}, {
+	testName: "escaped",
+	args:     "@@text str @x @",
+	expect:   []interface{}{"@text", "@x", "@"},
+}, {
+	testName: "str-is-literal",
+	args:     "str @text str stdin str env HOME str cmd true str http x",
+	expect:   []interface{}{"@text", "stdin", "env", "HOME", "cmd", true, "http", "x"},
+}, {
+	testName:    "missing",
+	args:        "a: @missing",
+	expectError: `cannot read file at argument 1: open missing: .*`,
+}, {
+	testName:    "hermetic",
+	args:        "@text",
+	hermetic:    true,
+	expectError: `file reference "@text" at argument 0 is not allowed because it performs I/O`,
+}, {
+	testName: "file-keyword",
+	args:     "body: file text",
+	expect:   []interface{}{map[string]interface{}{"body": "hello [ world\n"}},
+}, {
+	testName: "file-keyword-at-name",
+	args:     "file @at",
+	expect:   []interface{}{"at file"},
+}, {
+	testName:    "file-keyword-missing",
+	args:        "file missing",
+	expectError: `cannot read file "missing" at argument 1: open missing: .*`,
+}, {
+	testName:    "file-keyword-hermetic",
+	args:        "file text",
+	hermetic:    true,
+	expectError: `file at argument 0 is not allowed because it performs I/O or is nondeterministic`,
+}, {
+	testName: "base64file",
+	args:     "data: base64file bin",
+	expect:   []interface{}{map[string]interface{}{"data": "AP9hCg=="}},
+}, {
+	testName:    "base64file-missing",
+	args:        "base64file missing",
+	expectError: `cannot read file "missing" at argument 1: open missing: .*`,
+}, {
+	testName:    "base64file-hermetic",
+	args:        "base64file bin",
+	hermetic:    true,
+	expectError: `base64file at argument 0 is not allowed because it performs I/O or is nondeterministic`,
+}, {
+	testName: "jsonfile",
+	args:     "cfg: jsonfile data.json",
+	expect:   []interface{}{map[string]interface{}{"cfg": map[string]interface{}{"x": json.Number("1")}}},
+}, {
+	testName:    "jsonfile-invalid",
+	args:        "jsonfile text",
+	expectError: `cannot unmarshal json file "text" at argument 1: invalid character 'h' looking for beginning of value`,
+}, {
+	testName:    "jsonfile-trailing",
+	args:        "jsonfile two.json",
+	expectError: `cannot unmarshal json file "two.json" at argument 1: unexpected data after value`,
+}, {
+	testName:    "jsonfile-missing",
+	args:        "jsonfile missing",
+	expectError: `cannot read file "missing" at argument 1: open missing: .*`,
+}}
+
+func TestAtFile(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	files := map[string]string{
+		"text":      "hello [ world\n",
+		"port":      "8080\n",
+		"data.json": `{"x": 1}`,
+		"crlf":      "a\r\nb\r\n",
+		"@at":       "at file",
+		"two.json":  "1 2",
+		"bin":       "\x00\xffa\n",
+	}
+	for name, data := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666)
+		c.Assert(err, qt.Equals, nil)
+	}
+	wd, err := os.Getwd()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(os.Chdir(dir), qt.Equals, nil)
+	defer os.Chdir(wd)
+	for _, test := range atFileTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.hermetic = test.hermetic
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,582 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+)
+
+var avroSchemaFile = flag.String("avro-schema", "", "in avro and avro-json output, the file holding the Avro schema of the values")
+
+// avroSchema holds a parsed Avro schema
+// (see https://avro.apache.org/docs/current/specification/).
+type avroSchema struct {
+	// typ holds the primitive type name or one of
+	// record, enum, array, map, fixed or union.
+	typ string
+	// name holds the full name of a named type.
+	name     string
+	fields   []avroField
+	symbols  []string
+	items    *avroSchema
+	values   *avroSchema
+	branches []*avroSchema
+	size     int
+}
+
+type avroField struct {
+	name       string
+	typ        *avroSchema
+	def        interface{}
+	hasDefault bool
+}
+
+var avroPrimitives = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"int":     true,
+	"long":    true,
+	"float":   true,
+	"double":  true,
+	"bytes":   true,
+	"string":  true,
+}
+
+// readAvroSchema reads the Avro schema in the given file.
+func readAvroSchema(file string) (*avroSchema, error) {
+	if file == "" {
+		return nil, errors.New("Avro output requires -avro-schema")
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseAvroSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Avro schema in %s: %v", file, err)
+	}
+	return s, nil
+}
+
+func parseAvroSchema(data []byte) (*avroSchema, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	r := &avroSchemaParser{
+		named: make(map[string]*avroSchema),
+	}
+	return r.parse(v, "")
+}
+
+// avroSchemaParser parses Avro schemas, keeping track
+// of the named types defined so far so that they can
+// be referred to by name.
+type avroSchemaParser struct {
+	named map[string]*avroSchema
+}
+
+func (r *avroSchemaParser) parse(v interface{}, namespace string) (*avroSchema, error) {
+	switch v := v.(type) {
+	case string:
+		if avroPrimitives[v] {
+			return &avroSchema{typ: v}, nil
+		}
+		if s := r.named[avroFullName(v, namespace)]; s != nil {
+			return s, nil
+		}
+		if s := r.named[v]; s != nil {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", v)
+	case []interface{}:
+		s := &avroSchema{typ: "union"}
+		for _, b := range v {
+			branch, err := r.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if branch.typ == "union" {
+				return nil, fmt.Errorf("unions may not immediately contain other unions")
+			}
+			s.branches = append(s.branches, branch)
+		}
+		return s, nil
+	case map[string]interface{}:
+		return r.parseComplex(v, namespace)
+	}
+	return nil, fmt.Errorf("unexpected %T in schema", v)
+}
+
+func (r *avroSchemaParser) parseComplex(m map[string]interface{}, namespace string) (*avroSchema, error) {
+	typ, ok := m["type"].(string)
+	if !ok {
+		return r.parse(m["type"], namespace)
+	}
+	s := &avroSchema{typ: typ}
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		name, _ := m["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%s has no name", typ)
+		}
+		if ns, ok := m["namespace"].(string); ok && !strings.Contains(name, ".") {
+			namespace = ns
+		}
+		s.name = avroFullName(name, namespace)
+		if i := strings.LastIndex(s.name, "."); i >= 0 {
+			namespace = s.name[:i]
+		}
+		if r.named[s.name] != nil {
+			return nil, fmt.Errorf("type %q is defined more than once", s.name)
+		}
+		// Register the type before parsing its fields
+		// so that records can refer to themselves.
+		r.named[s.name] = s
+	}
+	switch typ {
+	case "record", "error":
+		s.typ = "record"
+		fields, _ := m["fields"].([]interface{})
+		for _, f := range fields {
+			fm, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid field in record %q", s.name)
+			}
+			name, _ := fm["name"].(string)
+			if name == "" {
+				return nil, fmt.Errorf("field with no name in record %q", s.name)
+			}
+			ft, err := r.parse(fm["type"], namespace)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %v", name, err)
+			}
+			def, hasDefault := fm["default"]
+			s.fields = append(s.fields, avroField{
+				name:       name,
+				typ:        ft,
+				def:        def,
+				hasDefault: hasDefault,
+			})
+		}
+	case "enum":
+		symbols, _ := m["symbols"].([]interface{})
+		for _, sym := range symbols {
+			sym, ok := sym.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid symbol in enum %q", s.name)
+			}
+			s.symbols = append(s.symbols, sym)
+		}
+	case "fixed":
+		size, ok := m["size"].(float64)
+		if !ok || size < 0 || size != math.Trunc(size) {
+			return nil, fmt.Errorf("invalid size in fixed %q", s.name)
+		}
+		s.size = int(size)
+	case "array":
+		items, err := r.parse(m["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		s.items = items
+	case "map":
+		values, err := r.parse(m["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		s.values = values
+	default:
+		if !avroPrimitives[typ] {
+			return r.parse(typ, namespace)
+		}
+	}
+	return s, nil
+}
+
+func avroFullName(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// typeName returns the name used for s in the Avro
+// JSON encoding of unions.
+func (s *avroSchema) typeName() string {
+	if s.name != "" {
+		return s.name
+	}
+	return s.typ
+}
+
+// matches reports whether v can be encoded as s.
+// It's used to choose the branch of a union.
+func (s *avroSchema) matches(v interface{}) bool {
+	switch s.typ {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "int":
+		i, ok := intValue(v)
+		return ok && i >= math.MinInt32 && i <= math.MaxInt32
+	case "long":
+		_, ok := intValue(v)
+		return ok
+	case "float", "double":
+		switch v.(type) {
+		case float64, json.Number:
+			return true
+		}
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "bytes", "fixed":
+		str, ok := v.(string)
+		if !ok {
+			return false
+		}
+		b, err := avroBytes(str)
+		return err == nil && (s.typ == "bytes" || len(b) == s.size)
+	case "enum":
+		str, _ := v.(string)
+		return s.symbolIndex(str) >= 0
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "map":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "record":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for _, f := range s.fields {
+			if _, ok := m[f.name]; !ok && !f.hasDefault {
+				return false
+			}
+		}
+		return len(m) <= len(s.fields) && s.unknownField(m) == ""
+	}
+	return false
+}
+
+// check returns an error if v cannot be encoded as s.
+// Missing record fields are reported when the
+// record is encoded.
+func (s *avroSchema) check(v interface{}) error {
+	if m, ok := v.(map[string]interface{}); ok && s.typ == "record" {
+		if k := s.unknownField(m); k != "" {
+			return fmt.Errorf("unknown field %q in record %q", k, s.name)
+		}
+		return nil
+	}
+	if !s.matches(v) {
+		return fmt.Errorf("cannot encode %s as Avro %s", valueKind(v), s.typeName())
+	}
+	return nil
+}
+
+func (s *avroSchema) symbolIndex(sym string) int {
+	for i, s := range s.symbols {
+		if s == sym {
+			return i
+		}
+	}
+	return -1
+}
+
+// unknownField returns the name of a member of m that
+// isn't a field of the record s, or the empty string if
+// there is none.
+func (s *avroSchema) unknownField(m map[string]interface{}) string {
+	for _, k := range sortedKeys(m) {
+		found := false
+		for _, f := range s.fields {
+			found = found || f.name == k
+		}
+		if !found {
+			return k
+		}
+	}
+	return ""
+}
+
+// branch returns the index of the first branch
+// of the union s that matches v.
+func (s *avroSchema) branch(v interface{}) (int, error) {
+	for i, b := range s.branches {
+		if b.matches(v) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%s does not match any type in union", valueKind(v))
+}
+
+// fieldValue returns the value of the field f in the record
+// value m, using the default value if it's not present.
+// Default values for unions correspond to the first
+// branch, so the returned schema is that branch and
+// the returned index is 0 in that case. Otherwise the
+// returned index is -1.
+func (f avroField) value(m map[string]interface{}) (*avroSchema, interface{}, int, error) {
+	if v, ok := m[f.name]; ok {
+		return f.typ, v, -1, nil
+	}
+	if !f.hasDefault {
+		return nil, nil, 0, fmt.Errorf("missing field %q", f.name)
+	}
+	if f.typ.typ == "union" {
+		return f.typ.branches[0], f.def, 0, nil
+	}
+	return f.typ, f.def, -1, nil
+}
+
+// avroBytes returns the bytes represented by s in the
+// Avro JSON encoding, where each byte is represented
+// by the character with that code point.
+func avroBytes(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return nil, fmt.Errorf("character %q out of range for bytes", r)
+		}
+		b = append(b, byte(r))
+	}
+	return b, nil
+}
+
+// avroEncoder encodes values in the Avro binary encoding
+// using the schema from -avro-schema. Values are written
+// one after another with no container file framing.
+type avroEncoder struct {
+	w      io.Writer
+	schema *avroSchema
+	err    error
+	buf    []byte
+}
+
+func newAvroEncoder(w io.Writer) encoder {
+	schema, err := readAvroSchema(*avroSchemaFile)
+	return &avroEncoder{
+		w:      w,
+		schema: schema,
+		err:    err,
+	}
+}
+
+func (e *avroEncoder) Encode(v interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	var err error
+	e.buf, err = appendAvro(e.buf[:0], e.schema, v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(e.buf)
+	return err
+}
+
+func appendAvro(buf []byte, s *avroSchema, v interface{}) ([]byte, error) {
+	if s.typ == "union" {
+		i, err := s.branch(v)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendAvroLong(buf, int64(i))
+		return appendAvro(buf, s.branches[i], v)
+	}
+	if err := s.check(v); err != nil {
+		return nil, err
+	}
+	switch s.typ {
+	case "null":
+	case "boolean":
+		if v.(bool) {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	case "int", "long":
+		i, _ := intValue(v)
+		buf = appendAvroLong(buf, i)
+	case "float":
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(floatValue(v))))
+		buf = append(buf, b[:]...)
+	case "double":
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(floatValue(v)))
+		buf = append(buf, b[:]...)
+	case "string":
+		buf = appendAvroLong(buf, int64(len(v.(string))))
+		buf = append(buf, v.(string)...)
+	case "bytes":
+		b, _ := avroBytes(v.(string))
+		buf = appendAvroLong(buf, int64(len(b)))
+		buf = append(buf, b...)
+	case "fixed":
+		b, _ := avroBytes(v.(string))
+		buf = append(buf, b...)
+	case "enum":
+		buf = appendAvroLong(buf, int64(s.symbolIndex(v.(string))))
+	case "array":
+		elems := v.([]interface{})
+		if len(elems) > 0 {
+			buf = appendAvroLong(buf, int64(len(elems)))
+			for _, elem := range elems {
+				var err error
+				if buf, err = appendAvro(buf, s.items, elem); err != nil {
+					return nil, err
+				}
+			}
+		}
+		buf = appendAvroLong(buf, 0)
+	case "map":
+		m := v.(map[string]interface{})
+		if len(m) > 0 {
+			buf = appendAvroLong(buf, int64(len(m)))
+			for _, k := range sortedKeys(m) {
+				buf = appendAvroLong(buf, int64(len(k)))
+				buf = append(buf, k...)
+				var err error
+				if buf, err = appendAvro(buf, s.values, m[k]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		buf = appendAvroLong(buf, 0)
+	case "record":
+		m := v.(map[string]interface{})
+		for _, f := range s.fields {
+			ft, fv, branch, err := f.value(m)
+			if err != nil {
+				return nil, fmt.Errorf("record %q: %v", s.name, err)
+			}
+			if branch >= 0 {
+				buf = appendAvroLong(buf, int64(branch))
+			}
+			if buf, err = appendAvro(buf, ft, fv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// appendAvroLong appends i as a zig-zag encoded variable length integer.
+func appendAvroLong(buf []byte, i int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], i)
+	return append(buf, b[:n]...)
+}
+
+// avroJSONEncoder encodes values in the Avro JSON encoding
+// using the schema from -avro-schema. This is the same as
+// the usual JSON encoding except that non-null union values
+// are wrapped in an object that names their type.
+type avroJSONEncoder struct {
+	enc    encoder
+	schema *avroSchema
+	err    error
+}
+
+func newAvroJSONEncoder(w io.Writer) encoder {
+	schema, err := readAvroSchema(*avroSchemaFile)
+	return &avroJSONEncoder{
+		enc:    newJSONEncoder(w),
+		schema: schema,
+		err:    err,
+	}
+}
+
+func (e *avroJSONEncoder) Encode(v interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	v, err := avroJSONValue(e.schema, v)
+	if err != nil {
+		return err
+	}
+	return e.enc.Encode(v)
+}
+
+// avroJSONValue returns v converted to the Avro JSON encoding of s.
+func avroJSONValue(s *avroSchema, v interface{}) (interface{}, error) {
+	if s.typ == "union" {
+		i, err := s.branch(v)
+		if err != nil {
+			return nil, err
+		}
+		return avroJSONBranch(s.branches[i], v)
+	}
+	if err := s.check(v); err != nil {
+		return nil, err
+	}
+	switch s.typ {
+	case "array":
+		elems := v.([]interface{})
+		r := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			var err error
+			if r[i], err = avroJSONValue(s.items, elem); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	case "map":
+		m := v.(map[string]interface{})
+		r := make(map[string]interface{})
+		for k, elem := range m {
+			var err error
+			if r[k], err = avroJSONValue(s.values, elem); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	case "record":
+		m := v.(map[string]interface{})
+		r := make(map[string]interface{})
+		for _, f := range s.fields {
+			ft, fv, branch, err := f.value(m)
+			if err != nil {
+				return nil, fmt.Errorf("record %q: %v", s.name, err)
+			}
+			if branch >= 0 {
+				r[f.name], err = avroJSONBranch(ft, fv)
+			} else {
+				r[f.name], err = avroJSONValue(ft, fv)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	}
+	return v, nil
+}
+
+// avroJSONBranch returns the Avro JSON encoding of the
+// value v of the given branch of a union.
+func avroJSONBranch(branch *avroSchema, v interface{}) (interface{}, error) {
+	jv, err := avroJSONValue(branch, v)
+	if err != nil {
+		return nil, err
+	}
+	if branch.typ == "null" {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		branch.typeName(): jv,
+	}, nil
+}
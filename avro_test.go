@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const avroUserSchema = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "test",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"},
+		{"name": "email", "type": ["null", "string"], "default": null},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}, "default": "A"},
+		{"name": "score", "type": "double", "default": 0}
+	]
+}`
+
+const avroListSchema = `{
+	"type": "record",
+	"name": "List",
+	"namespace": "test",
+	"fields": [
+		{"name": "value", "type": "int"},
+		{"name": "next", "type": ["null", "List"]}
+	]
+}`
+
+var avroTests = []struct {
+	testName     string
+	schema       string
+	val          interface{}
+	expectBinary string
+	expectJSON   string
+	expectError  string
+}{{
+	testName: "record-with-defaults",
+	schema:   avroUserSchema,
+	val: map[string]interface{}{
+		"name":  "bob",
+		"age":   30.0,
+		"email": "b@x",
+		"kind":  "B",
+	},
+	expectBinary: "06626f62" + "3c" + "0206624078" + "00" + "02" + "0000000000000000",
+	expectJSON:   `{"age":30,"email":{"string":"b@x"},"kind":"B","name":"bob","score":0,"tags":[]}`,
+}, {
+	testName: "recursive-record",
+	schema:   avroListSchema,
+	val: map[string]interface{}{
+		"value": 1.0,
+		"next": map[string]interface{}{
+			"value": 2.0,
+			"next":  nil,
+		},
+	},
+	expectBinary: "0202" + "0400",
+	expectJSON:   `{"next":{"test.List":{"next":null,"value":2}},"value":1}`,
+}, {
+	testName:     "map-of-long",
+	schema:       `{"type": "map", "values": "long"}`,
+	val:          map[string]interface{}{"b": json.Number("-2"), "a": 4294967296.0},
+	expectBinary: "04" + "0261" + "8080808020" + "0262" + "03" + "00",
+	expectJSON:   `{"a":4294967296,"b":-2}`,
+}, {
+	testName:     "float-bytes-fixed",
+	schema:       `{"type": "array", "items": ["float", {"type": "fixed", "name": "two", "size": 2}, "bytes"]}`,
+	val:          []interface{}{1.5, "ÿ", "ab"},
+	expectBinary: "06" + "00" + "0000c03f" + "0402ff" + "026162" + "00",
+	expectJSON:   `[{"float":1.5},{"bytes":"ÿ"},{"two":"ab"}]`,
+}, {
+	testName:    "unknown-field",
+	schema:      avroUserSchema,
+	val:         map[string]interface{}{"name": "x", "age": 1.0, "other": true},
+	expectError: `unknown field "other" in record "test.User"`,
+}, {
+	testName:    "missing-field",
+	schema:      avroUserSchema,
+	val:         map[string]interface{}{"name": "x"},
+	expectError: `record "test.User": missing field "age"`,
+}, {
+	testName:    "int-out-of-range",
+	schema:      avroUserSchema,
+	val:         map[string]interface{}{"name": "x", "age": 1e10},
+	expectError: `cannot encode number as Avro int`,
+}, {
+	testName:    "no-union-match",
+	schema:      `["null", "string"]`,
+	val:         true,
+	expectError: `bool does not match any type in union`,
+}, {
+	testName:    "unknown-type",
+	schema:      `{"type": "array", "items": "Foo"}`,
+	val:         []interface{}{},
+	expectError: `invalid Avro schema in .*: unknown type "Foo"`,
+}}
+
+func TestAvroEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range avroTests {
+		c.Run(test.testName, func(c *qt.C) {
+			file := filepath.Join(c.Mkdir(), "schema.avsc")
+			err := ioutil.WriteFile(file, []byte(test.schema), 0666)
+			c.Assert(err, qt.Equals, nil)
+			c.Patch(avroSchemaFile, file)
+
+			var buf bytes.Buffer
+			err = newAvroEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+			} else {
+				c.Assert(err, qt.Equals, nil)
+				c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, test.expectBinary)
+			}
+
+			buf.Reset()
+			err = newAvroJSONEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+			} else {
+				c.Assert(err, qt.Equals, nil)
+				c.Assert(buf.String(), qt.Equals, test.expectJSON+"\n")
+			}
+		})
+	}
+}
+
+func TestAvroEncodeNoSchema(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(avroSchemaFile, "")
+	err := newAvroEncoder(ioutil.Discard).Encode(nil)
+	c.Assert(err, qt.ErrorMatches, `Avro output requires -avro-schema`)
+}
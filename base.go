@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+)
+
+var baseFile = flag.String("base", "", "file holding a JSON or YAML document to which each value is applied as a JSON merge patch (RFC 7396), so that objects are merged and null deletes a member")
+
+// loadBase reads the base document for -base from the named file.
+func loadBase(file string) (interface{}, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err == nil {
+		return v, nil
+	}
+	v, err = parseYAML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s as JSON or YAML: %v", file, err)
+	}
+	return v, nil
+}
+
+// mergePatch returns the result of applying patch to target
+// as described in RFC 7396: members of a patch object replace
+// the corresponding members of the target, recursively, and
+// a null member deletes the corresponding member. Any other
+// patch replaces the target entirely. The target is not modified.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, _ := target.(map[string]interface{})
+	result := make(map[string]interface{}, len(t)+len(p))
+	for k, v := range t {
+		result[k] = v
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = mergePatch(result[k], v)
+	}
+	return result
+}
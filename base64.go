@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// evalBase64 implements the base64 keyword. The argument
+// is encoded exactly as given, even if it looks like a number.
+func evalBase64(args []string, _ int, _ interface{}) (interface{}, error) {
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// evalB64d implements the b64d keyword. Both the standard and
+// URL-safe alphabets are accepted, with or without padding.
+func evalB64d(args []string, index int, _ interface{}) (interface{}, error) {
+	s := args[0]
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") && len(s)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	data, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 %q at argument %d: %v", s, index, err)
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("base64 %q at argument %d does not decode to valid UTF-8 text", s, index)
+	}
+	return string(data), nil
+}
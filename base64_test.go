@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var base64Tests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "encode",
+	args:     "auth: base64 user:secret",
+	expect:   map[string]interface{}{"auth": "dXNlcjpzZWNyZXQ="},
+}, {
+	testName:    "encode-missing",
+	args:        "a: base64",
+	expectError: `unexpected end of arguments \(expected string to encode\)`,
+}, {
+	testName: "encode-number",
+	args:     "base64 1234",
+	expect:   "MTIzNA==",
+}, {
+	testName: "encode-keyword-name",
+	args:     "base64 true",
+	expect:   "dHJ1ZQ==",
+}, {
+	testName: "decode",
+	args:     "b64d aGVsbG8gd29ybGQ=",
+	expect:   "hello world",
+}, {
+	testName: "decode-unpadded",
+	args:     "b64d aGVsbG8",
+	expect:   "hello",
+}, {
+	testName: "decode-url-safe",
+	args:     "b64d PD8_Pz4-",
+	expect:   "<?\x3f?>>",
+}, {
+	testName:    "decode-digits",
+	args:        "b64d 1234",
+	expectError: `base64 "1234" at argument 1 does not decode to valid UTF-8 text`,
+}, {
+	testName:    "decode-invalid",
+	args:        "b64d !!!!",
+	expectError: `invalid base64 "!!!!" at argument 1: illegal base64 data at input byte 0`,
+}, {
+	testName:    "decode-binary",
+	args:        "b64d AP8=",
+	expectError: `base64 "AP8=" at argument 1 does not decode to valid UTF-8 text`,
+}}
+
+func TestBase64(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range base64Tests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// mergePatchTests holds the examples from RFC 7396 appendix A.
+var mergePatchTests = []struct {
+	target string
+	patch  string
+	expect string
+}{
+	{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+	{`{"a":"b"}`, `{"a":null}`, `{}`},
+	{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+	{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+	{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+	{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+	{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+	{`{"a":"b"}`, `["c"]`, `["c"]`},
+	{`{"a":"foo"}`, `null`, `null`},
+	{`{"a":"foo"}`, `"bar"`, `"bar"`},
+	{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+	{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+	{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+}
+
+func TestMergePatch(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range mergePatchTests {
+		c.Run(test.target+"+"+test.patch, func(c *qt.C) {
+			target := unmarshalJSON(c, test.target)
+			targetCopy := unmarshalJSON(c, test.target)
+			result := mergePatch(target, unmarshalJSON(c, test.patch))
+			c.Assert(result, qt.DeepEquals, unmarshalJSON(c, test.expect))
+			c.Assert(target, qt.DeepEquals, targetCopy)
+		})
+	}
+}
+
+func TestLoadBase(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	write := func(name, data string) string {
+		file := filepath.Join(dir, name)
+		err := ioutil.WriteFile(file, []byte(data), 0666)
+		c.Assert(err, qt.Equals, nil)
+		return file
+	}
+	v, err := loadBase(write("base.json", `{"a": 1, "b": [true]}`))
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.DeepEquals, map[string]interface{}{
+		"a": json.Number("1"),
+		"b": []interface{}{true},
+	})
+	v, err = loadBase(write("base.yaml", "a:\n  b: x\n"))
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.DeepEquals, map[string]interface{}{
+		"a": map[string]interface{}{"b": "x"},
+	})
+	_, err = loadBase(write("bad.yaml", "a: [\n"))
+	c.Assert(err, qt.ErrorMatches, `cannot parse .*bad.yaml as JSON or YAML: .*`)
+}
+
+func unmarshalJSON(c *qt.C, s string) interface{} {
+	var v interface{}
+	err := json.Unmarshal([]byte(s), &v)
+	c.Assert(err, qt.Equals, nil)
+	return v
+}
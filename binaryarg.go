@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// decodeBinaryKeyword decodes the binary data in the given
+// argument of the named keyword using decode.
+func decodeBinaryKeyword(name string, decode func([]byte) (interface{}, error), arg string, index int) (interface{}, error) {
+	data, err := decodeBinaryArg(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s data at argument %d: %v", name, index, err)
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s at argument %d: %v", name, index, err)
+	}
+	return v, nil
+}
+
+// decodeBinaryArg decodes an argument holding binary data. The data
+// may be explicitly marked as hex or base64 with a "hex:" or "base64:"
+// prefix; otherwise it's treated as hex if it's valid hex and as
+// base64 (standard or URL-safe, with or without padding) otherwise.
+func decodeBinaryArg(s string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(s, "hex:"):
+		return hex.DecodeString(s[len("hex:"):])
+	case strings.HasPrefix(s, "base64:"):
+		return decodeBase64(s[len("base64:"):])
+	}
+	if data, err := hex.DecodeString(s); err == nil {
+		return data, nil
+	}
+	data, err := decodeBase64(s)
+	if err != nil {
+		return nil, errors.New("data is neither hex nor base64")
+	}
+	return data, nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return enc.DecodeString(s)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var decodeBinaryArgTests = []struct {
+	testName    string
+	arg         string
+	expect      string
+	expectError string
+}{{
+	testName: "hex",
+	arg:      "c0ff",
+	expect:   "\xc0\xff",
+}, {
+	testName: "base64",
+	arg:      "wP8=",
+	expect:   "\xc0\xff",
+}, {
+	testName: "base64-unpadded",
+	arg:      "wP8",
+	expect:   "\xc0\xff",
+}, {
+	testName: "base64-url",
+	arg:      "-_8",
+	expect:   "\xfb\xff",
+}, {
+	testName: "explicit-base64",
+	arg:      "base64:abcd",
+	expect:   "i\xb7\x1d",
+}, {
+	testName: "explicit-hex",
+	arg:      "hex:abcd",
+	expect:   "\xab\xcd",
+}, {
+	testName:    "invalid",
+	arg:         "!!",
+	expectError: `data is neither hex nor base64`,
+}, {
+	testName:    "invalid-explicit-hex",
+	arg:         "hex:xy",
+	expectError: `encoding/hex: invalid byte: U\+0078 'x'`,
+}}
+
+func TestDecodeBinaryArg(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range decodeBinaryArgTests {
+		c.Run(test.testName, func(c *qt.C) {
+			data, err := decodeBinaryArg(test.arg)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(string(data), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BSON element types (see http://bsonspec.org/spec.html).
+const (
+	bsonDouble   = 0x01
+	bsonString   = 0x02
+	bsonDocument = 0x03
+	bsonArray    = 0x04
+	bsonBool     = 0x08
+	bsonNull     = 0x0a
+	bsonInt32    = 0x10
+	bsonInt64    = 0x12
+)
+
+// bsonEncoder encodes objects as BSON documents.
+// As BSON documents must be objects, an array value
+// is encoded as a sequence of documents, one for each element,
+// which is the form read by mongorestore.
+// Whole numbers are encoded as int32 when they fit, otherwise int64;
+// other numbers are encoded as doubles.
+type bsonEncoder struct {
+	w io.Writer
+}
+
+func newBSONEncoder(w io.Writer) encoder {
+	return &bsonEncoder{w: w}
+}
+
+func (e *bsonEncoder) Encode(v interface{}) error {
+	docs, ok := v.([]interface{})
+	if !ok {
+		if _, ok := v.(map[string]interface{}); !ok {
+			return fmt.Errorf("BSON output requires an object or an array of objects")
+		}
+		docs = []interface{}{v}
+	}
+	for i, doc := range docs {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("BSON output requires an object or an array of objects but element %d is %T", i, doc)
+		}
+		buf, err := appendBSONDocument(nil, obj)
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendBSONDocument(buf []byte, obj map[string]interface{}) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, 0, 0, 0, 0)
+	for _, k := range sortedKeys(obj) {
+		var err error
+		buf, err = appendBSONElement(buf, k, obj[k])
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf = append(buf, 0)
+	binary.LittleEndian.PutUint32(buf[start:], uint32(len(buf)-start))
+	return buf, nil
+}
+
+func appendBSONElement(buf []byte, name string, v interface{}) ([]byte, error) {
+	if strings.Contains(name, "\x00") {
+		return nil, fmt.Errorf("cannot encode key %q containing a NUL byte as BSON", name)
+	}
+	appendHeader := func(typ byte) {
+		buf = append(buf, typ)
+		buf = append(buf, name...)
+		buf = append(buf, 0)
+	}
+	switch v := v.(type) {
+	case nil:
+		appendHeader(bsonNull)
+	case bool:
+		appendHeader(bsonBool)
+		if v {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	case float64, json.Number:
+		i, ok := intValue(v)
+		switch {
+		case ok && i >= math.MinInt32 && i <= math.MaxInt32:
+			appendHeader(bsonInt32)
+			buf = appendUint32LE(buf, uint32(i))
+		case ok:
+			appendHeader(bsonInt64)
+			buf = appendUint64LE(buf, uint64(i))
+		default:
+			appendHeader(bsonDouble)
+			buf = appendUint64LE(buf, math.Float64bits(floatValue(v)))
+		}
+	case string:
+		appendHeader(bsonString)
+		buf = appendUint32LE(buf, uint32(len(v)+1))
+		buf = append(buf, v...)
+		buf = append(buf, 0)
+	case map[string]interface{}:
+		appendHeader(bsonDocument)
+		return appendBSONDocument(buf, v)
+	case []interface{}:
+		appendHeader(bsonArray)
+		// An array is encoded as a document with keys "0", "1", etc.
+		start := len(buf)
+		buf = append(buf, 0, 0, 0, 0)
+		for i, elem := range v {
+			var err error
+			buf, err = appendBSONElement(buf, strconv.Itoa(i), elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		buf = append(buf, 0)
+		binary.LittleEndian.PutUint32(buf[start:], uint32(len(buf)-start))
+	default:
+		return nil, fmt.Errorf("cannot encode %T as BSON", v)
+	}
+	return buf, nil
+}
+
+func appendUint32LE(buf []byte, x uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], x)
+	return append(buf, b[:]...)
+}
+
+func appendUint64LE(buf []byte, x uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	return append(buf, b[:]...)
+}
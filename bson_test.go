@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var bsonTests = []struct {
+	testName    string
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "hello-world",
+	val:      map[string]interface{}{"hello": "world"},
+	// From the examples at http://bsonspec.org/faq.html.
+	expect: "160000000268656c6c6f0006000000776f726c640000",
+}, {
+	testName: "numbers",
+	val: map[string]interface{}{
+		"a": 1.0,
+		"b": json.Number("5000000000"),
+		"c": 0.5,
+	},
+	expect: "" +
+		"22000000" +
+		"10610001000000" +
+		"12620000f2052a01000000" +
+		"016300000000000000e03f" +
+		"00",
+}, {
+	testName: "null-bool-array",
+	val: map[string]interface{}{
+		"x": []interface{}{nil, true},
+	},
+	expect: "" +
+		"14000000" +
+		"0478000c000000" +
+		"0a30000831000100" +
+		"00",
+}, {
+	testName: "array-of-documents",
+	val: []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{},
+	},
+	expect: "0500000000" + "0500000000",
+}, {
+	testName:    "not-an-object",
+	val:         "x",
+	expectError: `BSON output requires an object or an array of objects`,
+}, {
+	testName:    "array-of-non-objects",
+	val:         []interface{}{map[string]interface{}{}, 1.0},
+	expectError: `BSON output requires an object or an array of objects but element 1 is float64`,
+}}
+
+func TestBSONEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range bsonTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newBSONEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var bundle = flag.Bool("bundle", false, "like -ref-resolve, but copy the values that $ref members in files refer to into the $defs member of each value and refer to them there, so that each value is self-contained")
+
+// bundler copies the values referred to by references
+// to files into a $defs object.
+type bundler struct {
+	r *refResolver
+	// defs holds the copied values by name.
+	defs map[string]interface{}
+	// names holds the name in defs of each referenced
+	// value, keyed by file name and fragment.
+	names map[string]string
+}
+
+// bundleRefs returns v with every $ref member that refers to a file,
+// directly or indirectly, replaced by a reference to a copy of the
+// value it refers to in the $defs member of v. References to parts
+// of v itself are left as they are. The value v is not modified.
+func bundleRefs(v interface{}, hermetic bool) (interface{}, error) {
+	b := &bundler{
+		r: &refResolver{
+			hermetic: hermetic,
+			docs:     make(map[string]interface{}),
+		},
+		defs:  make(map[string]interface{}),
+		names: make(map[string]string),
+	}
+	root, _ := v.(map[string]interface{})
+	if existing, ok := root["$defs"].(map[string]interface{}); ok {
+		for name, def := range existing {
+			b.defs[name] = def
+		}
+	}
+	v, err := b.bundle(v, v, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(b.names) == 0 {
+		return v, nil
+	}
+	if root == nil {
+		return nil, fmt.Errorf("cannot bundle references into %s; must be object", valueKind(v))
+	}
+	m := v.(map[string]interface{})
+	defs := make(map[string]interface{}, len(b.defs))
+	for name, def := range b.defs {
+		defs[name] = def
+	}
+	// Values that were already in $defs have been bundled as part of v.
+	if existing, ok := m["$defs"].(map[string]interface{}); ok {
+		for name, def := range existing {
+			defs[name] = def
+		}
+	}
+	m["$defs"] = defs
+	return m, nil
+}
+
+// bundle returns a copy of v, which is within the given root value
+// read from the given file, with its references rewritten.
+func (b *bundler) bundle(v, root interface{}, file string) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		// Visit the members in order so that names
+		// are chosen deterministically.
+		for _, k := range sortedKeys(v) {
+			elem := v[k]
+			if ref, ok := elem.(string); ok && k == "$ref" {
+				ref, err := b.rewrite(ref, root, file)
+				if err != nil {
+					return nil, err
+				}
+				m[k] = ref
+				continue
+			}
+			elem, err := b.bundle(elem, root, file)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = elem
+		}
+		return m, nil
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, elem := range v {
+			elem, err := b.bundle(elem, root, file)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = elem
+		}
+		return a, nil
+	}
+	return v, nil
+}
+
+// rewrite returns the reference to use in place of ref.
+func (b *bundler) rewrite(ref string, root interface{}, file string) (string, error) {
+	u, root, file, err := b.r.load(ref, root, file)
+	if err != nil {
+		return "", err
+	}
+	if file == "" {
+		return ref, nil
+	}
+	key := file + "#" + u.Fragment
+	name, ok := b.names[key]
+	if !ok {
+		target, err := lookupPointer(root, u.Fragment)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve $ref %q: %v", ref, err)
+		}
+		name = b.newName(file, u.Fragment)
+		b.names[key] = name
+		// Reserve the name before bundling the target so
+		// that circular references refer to it.
+		b.defs[name] = nil
+		def, err := b.bundle(target, root, file)
+		if err != nil {
+			return "", err
+		}
+		b.defs[name] = def
+	}
+	return "#/$defs/" + pointerEscaper.Replace(name), nil
+}
+
+// newName returns an unused name in $defs for the value
+// at the given fragment within the given file. It is the
+// last token of the fragment or, if the fragment is empty,
+// the file name without its extension.
+func (b *bundler) newName(file, fragment string) string {
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if i := strings.LastIndex(fragment, "/"); i >= 0 && i < len(fragment)-1 {
+		base = strings.Replace(strings.Replace(fragment[i+1:], "~1", "/", -1), "~0", "~", -1)
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := b.defs[name]; !ok {
+			return name
+		}
+		name = base + "-" + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var bundleRefsTests = []struct {
+	testName    string
+	value       string
+	hermetic    bool
+	expect      string
+	expectError string
+}{{
+	testName: "shared",
+	value:    `{"a": {"$ref": "defs/common.json#/port"}, "b": {"$ref": "defs/common.json#/port", "description": "b"}}`,
+	expect: `{
+		"$defs": {"port": {"type": "integer"}},
+		"a": {"$ref": "#/$defs/port"},
+		"b": {"$ref": "#/$defs/port", "description": "b"}
+	}`,
+}, {
+	testName: "nested-file-and-local-refs",
+	value:    `{"a": {"$ref": "defs/common.json"}}`,
+	expect: `{
+		"$defs": {
+			"common": {"port": {"type": "integer"}, "name": {"$ref": "#/$defs/name"}, "alias": {"$ref": "#/$defs/port"}},
+			"name": {"type": "string"},
+			"port": {"type": "integer"}
+		},
+		"a": {"$ref": "#/$defs/common"}
+	}`,
+}, {
+	testName: "colliding-names",
+	value:    `{"b": {"$ref": "defs/other.json#/port"}, "a": {"$ref": "defs/common.json#/port"}, "c": {"$ref": "defs/other.json#/port"}}`,
+	expect: `{
+		"$defs": {"port": {"type": "integer"}, "port-2": {"type": "string"}},
+		"a": {"$ref": "#/$defs/port"},
+		"b": {"$ref": "#/$defs/port-2"},
+		"c": {"$ref": "#/$defs/port-2"}
+	}`,
+}, {
+	testName: "local-unchanged",
+	value:    `{"x": 1, "a": {"$ref": "#/x"}}`,
+	expect:   `{"x": 1, "a": {"$ref": "#/x"}}`,
+}, {
+	testName: "existing-defs",
+	value:    `{"$defs": {"port": {"$ref": "defs/common.json#/port"}}, "a": {"$ref": "#/$defs/port"}}`,
+	expect: `{
+		"$defs": {"port": {"$ref": "#/$defs/port-2"}, "port-2": {"type": "integer"}},
+		"a": {"$ref": "#/$defs/port"}
+	}`,
+}, {
+	testName: "circular",
+	value:    `{"a": {"$ref": "defs/tree.json"}}`,
+	expect: `{
+		"$defs": {"tree": {"children": {"items": {"$ref": "#/$defs/tree"}}}},
+		"a": {"$ref": "#/$defs/tree"}
+	}`,
+}, {
+	testName:    "not-object",
+	value:       `[{"$ref": "defs/common.json#/port"}]`,
+	expectError: `cannot bundle references into array; must be object`,
+}, {
+	testName:    "missing",
+	value:       `{"a": {"$ref": "defs/common.json#/nothere"}}`,
+	expectError: `cannot resolve \$ref "defs/common.json#/nothere": no member "nothere"`,
+}, {
+	testName:    "hermetic",
+	value:       `{"a": {"$ref": "defs/common.json"}}`,
+	hermetic:    true,
+	expectError: `\$ref "defs/common.json" is not allowed because it performs I/O`,
+}}
+
+func TestBundleRefs(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	err := os.Mkdir(filepath.Join(dir, "defs"), 0777)
+	c.Assert(err, qt.Equals, nil)
+	files := map[string]string{
+		"defs/common.json": `{"port": {"type": "integer"}, "name": {"$ref": "types.json#/name"}, "alias": {"$ref": "#/port"}}`,
+		"defs/types.json":  `{"name": {"type": "string"}}`,
+		"defs/tree.json":   `{"children": {"items": {"$ref": "#"}}}`,
+		"defs/other.json":  `{"port": {"type": "string"}}`,
+	}
+	for name, data := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666)
+		c.Assert(err, qt.Equals, nil)
+	}
+	wd, err := os.Getwd()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(os.Chdir(dir), qt.Equals, nil)
+	defer os.Chdir(wd)
+	for _, test := range bundleRefsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v := unmarshalJSON(c, test.value)
+			orig := unmarshalJSON(c, test.value)
+			got, err := bundleRefs(v, test.hermetic)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(got, qt.DeepEquals, unmarshalJSON(c, test.expect))
+			c.Assert(v, qt.DeepEquals, orig)
+			// The result doesn't depend on map iteration order.
+			for i := 0; i < 20; i++ {
+				again, err := bundleRefs(unmarshalJSON(c, test.value), test.hermetic)
+				c.Assert(err, qt.Equals, nil)
+				c.Assert(again, qt.DeepEquals, got)
+			}
+		})
+	}
+}
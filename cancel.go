@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var partial = flag.String("partial", "keep", "what to do with partially written output files when the command fails or is interrupted: keep or delete")
+
+var errInterrupted = errors.New("interrupted")
+
+// interruptContext returns a context that is canceled when
+// the process receives an interrupt or termination signal,
+// which abandons any request in progress while parsing and
+// stops output before the next value. A second signal
+// exits immediately, honoring the -partial flag.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		cancel()
+		<-c
+		removePartialOutput()
+		os.Exit(1)
+	}()
+	return ctx
+}
+
+// context returns the context used for I/O performed by keywords.
+func (p *parser) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// partialOutput holds the paths of the output files
+// that are currently being written.
+var partialOutput struct {
+	mu    sync.Mutex
+	paths map[*os.File]string
+}
+
+// createOutput creates an output file. Until it is closed with
+// closeOutput, it will be removed by removePartialOutput.
+func createOutput(path string) (*os.File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	partialOutput.mu.Lock()
+	defer partialOutput.mu.Unlock()
+	if partialOutput.paths == nil {
+		partialOutput.paths = make(map[*os.File]string)
+	}
+	partialOutput.paths[f] = path
+	return f, nil
+}
+
+// closeOutput closes a file created by createOutput.
+func closeOutput(f *os.File) error {
+	if err := f.Close(); err != nil {
+		return err
+	}
+	partialOutput.mu.Lock()
+	defer partialOutput.mu.Unlock()
+	delete(partialOutput.paths, f)
+	return nil
+}
+
+// removePartialOutput removes any output files that have not been
+// completely written if -partial=delete.
+func removePartialOutput() {
+	if *partial != "delete" {
+		return
+	}
+	partialOutput.mu.Lock()
+	defer partialOutput.mu.Unlock()
+	for f, path := range partialOutput.paths {
+		f.Close()
+		os.Remove(path)
+		delete(partialOutput.paths, f)
+	}
+}
+
+// sleepContext sleeps for the given duration or until
+// the context is canceled, in which case it returns
+// the context's error.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
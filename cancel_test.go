@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRemovePartialOutput(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	c.Patch(partial, "delete")
+	done := filepath.Join(dir, "done")
+	f, err := createOutput(done)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(closeOutput(f), qt.Equals, nil)
+
+	unfinished := filepath.Join(dir, "unfinished")
+	_, err = createOutput(unfinished)
+	c.Assert(err, qt.Equals, nil)
+
+	removePartialOutput()
+	_, err = os.Stat(done)
+	c.Assert(err, qt.Equals, nil)
+	_, err = os.Stat(unfinished)
+	c.Assert(os.IsNotExist(err), qt.Equals, true)
+}
+
+func TestRemovePartialOutputKeep(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	c.Patch(partial, "keep")
+	unfinished := filepath.Join(dir, "unfinished")
+	f, err := createOutput(unfinished)
+	c.Assert(err, qt.Equals, nil)
+	defer closeOutput(f)
+
+	removePartialOutput()
+	_, err = os.Stat(unfinished)
+	c.Assert(err, qt.Equals, nil)
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// CBOR major types (see RFC 8949, section 3.1).
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+	cborSimple   = 7 << 5
+)
+
+// cborEncoder encodes values in CBOR format (RFC 8949).
+// Numbers that hold whole values are encoded as CBOR integers;
+// all other numbers are encoded as 64-bit floats.
+type cborEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+func newCBOREncoder(w io.Writer) encoder {
+	return &cborEncoder{w: w}
+}
+
+func (e *cborEncoder) Encode(v interface{}) error {
+	e.buf = e.buf[:0]
+	if err := e.encode(v); err != nil {
+		return err
+	}
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *cborEncoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, cborSimple|22)
+	case bool:
+		if v {
+			e.buf = append(e.buf, cborSimple|21)
+		} else {
+			e.buf = append(e.buf, cborSimple|20)
+		}
+	case float64, json.Number:
+		if i, ok := intValue(v); ok {
+			if i >= 0 {
+				e.encodeHead(cborUnsigned, uint64(i))
+			} else {
+				e.encodeHead(cborNegative, uint64(-1-i))
+			}
+			break
+		}
+		e.buf = append(e.buf, cborSimple|27)
+		e.buf = appendUint64(e.buf, math.Float64bits(floatValue(v)))
+	case string:
+		e.encodeHead(cborText, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	case []interface{}:
+		e.encodeHead(cborArray, uint64(len(v)))
+		for _, elem := range v {
+			if err := e.encode(elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		e.encodeHead(cborMap, uint64(len(v)))
+		for _, k := range sortedKeys(v) {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as CBOR", v)
+	}
+	return nil
+}
+
+// encodeHead appends the initial bytes of a data item
+// with the given major type and argument, using
+// the shortest possible encoding.
+func (e *cborEncoder) encodeHead(major byte, n uint64) {
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, major|25)
+		e.buf = appendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, major|26)
+		e.buf = appendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, major|27)
+		e.buf = appendUint64(e.buf, n)
+	}
+}
+
+// CBOR major types not produced by cborEncoder.
+const (
+	cborBytes = 2 << 5
+	cborTag   = 6 << 5
+)
+
+// cborBreak is the "break" stop code that terminates
+// indefinite-length items.
+const cborBreak = 0xff
+
+// decodeCBOR decodes a single CBOR data item from data and returns
+// the equivalent JSON value. Byte strings are converted to base64
+// strings, numbers to json.Number, undefined to null and map keys
+// that aren't strings to their textual representation. Tags are
+// ignored.
+func decodeCBOR(data []byte) (interface{}, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) > 0 {
+		return nil, fmt.Errorf("%d unexpected bytes after value", len(d.data))
+	}
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+}
+
+func (d *cborDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// head reads the initial bytes of a data item and returns its major
+// type, its additional information and its argument. The argument
+// is not meaningful for indefinite-length items (info 31).
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	b, err := d.take(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]&0xe0, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err := d.take(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			arg = arg<<8 | uint64(c)
+		}
+		return major, info, arg, nil
+	case info == 31:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, fmt.Errorf("invalid CBOR additional information %d", info)
+}
+
+func (d *cborDecoder) decode() (interface{}, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if info == 31 && (major == cborUnsigned || major == cborNegative || major == cborTag) {
+		return nil, fmt.Errorf("invalid indefinite length for major type %d", major>>5)
+	}
+	switch major {
+	case cborUnsigned:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case cborNegative:
+		n := new(big.Int).SetUint64(arg)
+		return json.Number(n.Neg(n.Add(n, big.NewInt(1))).String()), nil
+	case cborBytes, cborText:
+		data, err := d.decodeString(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborBytes {
+			return base64.StdEncoding.EncodeToString(data), nil
+		}
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("invalid UTF-8 in text string")
+		}
+		return string(data), nil
+	case cborArray:
+		elems := []interface{}{}
+		for i := uint64(0); info == 31 || i < arg; i++ {
+			if info == 31 && d.atBreak() {
+				break
+			}
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+		return elems, nil
+	case cborMap:
+		obj := make(map[string]interface{})
+		for i := uint64(0); info == 31 || i < arg; i++ {
+			if info == 31 && d.atBreak() {
+				break
+			}
+			k, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			key, err := scalarText(k)
+			if err != nil {
+				return nil, fmt.Errorf("cannot use %s as an object key", valueKind(k))
+			}
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+		return obj, nil
+	case cborTag:
+		return d.decode()
+	}
+	// Major type 7: simple values and floats.
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return floatNumber(halfFloat(uint16(arg)))
+	case 26:
+		return floatNumber(float64(math.Float32frombits(uint32(arg))))
+	case 27:
+		return floatNumber(math.Float64frombits(arg))
+	case 31:
+		return nil, fmt.Errorf("unexpected break")
+	}
+	return nil, fmt.Errorf("unsupported CBOR simple value %d", arg)
+}
+
+// decodeString returns the contents of a byte or text string,
+// concatenating the chunks of an indefinite-length string.
+func (d *cborDecoder) decodeString(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		return d.take(arg)
+	}
+	var data []byte
+	for !d.atBreak() {
+		chunkMajor, chunkInfo, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, fmt.Errorf("invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+// atBreak reports whether the next byte is a break stop code,
+// and consumes it if so.
+func (d *cborDecoder) atBreak() bool {
+	if len(d.data) > 0 && d.data[0] == cborBreak {
+		d.data = d.data[1:]
+		return true
+	}
+	return false
+}
+
+// halfFloat returns the value of the IEEE 754 half-precision
+// floating point number with the given bits.
+func halfFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// Expected encodings are taken from RFC 8949, appendix A where possible.
+var cborTests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "simple-values",
+	val:      []interface{}{false, true, nil},
+	expect:   "83f4f5f6",
+}, {
+	testName: "unsigned",
+	val:      []interface{}{0.0, 23.0, 24.0, 1000.0, 1000000.0},
+	expect:   "85001718181903e81a000f4240",
+}, {
+	testName: "negative",
+	val:      []interface{}{-1.0, -100.0, -1000.0},
+	expect:   "832038633903e7",
+}, {
+	testName: "json-number-integer",
+	val:      json.Number("1000000000000"),
+	expect:   "1b000000e8d4a51000",
+}, {
+	testName: "json-number-integer-with-exponent",
+	val:      json.Number("1e3"),
+	expect:   "1903e8",
+}, {
+	testName: "float",
+	val:      1.1,
+	expect:   "fb3ff199999999999a",
+}, {
+	testName: "text",
+	val:      "IETF",
+	expect:   "6449455446",
+}, {
+	testName: "map",
+	val: map[string]interface{}{
+		"b": []interface{}{2.0, 3.0},
+		"a": 1.0,
+	},
+	expect: "a26161016162820203",
+}}
+
+func TestCBOREncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range cborTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newCBOREncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, test.expect)
+		})
+	}
+}
+
+// Most inputs are taken from RFC 8949, appendix A.
+var cborDecodeTests = []struct {
+	testName    string
+	data        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "integers",
+	data:     "8500187b3903e71bffffffffffffffff3bffffffffffffffff",
+	expect: []interface{}{
+		json.Number("0"),
+		json.Number("123"),
+		json.Number("-1000"),
+		json.Number("18446744073709551615"),
+		json.Number("-18446744073709551616"),
+	},
+}, {
+	testName: "floats",
+	data:     "86f93c00f97bfffa47c35000f90001f9c400fb3ff199999999999a",
+	expect: []interface{}{
+		json.Number("1"),
+		json.Number("65504"),
+		json.Number("100000"),
+		json.Number("5.960464477539063e-08"),
+		json.Number("-4"),
+		json.Number("1.1"),
+	},
+}, {
+	testName: "simple-values",
+	data:     "84f4f5f6f7",
+	expect:   []interface{}{false, true, nil, nil},
+}, {
+	testName: "tagged",
+	data:     "c074323031332d30332d32315432303a30343a30305a",
+	expect:   "2013-03-21T20:04:00Z",
+}, {
+	testName: "indefinite-strings",
+	data:     "825f42010243030405ff7f657374726561646d696e67ff",
+	expect:   []interface{}{"AQIDBAU=", "streaming"},
+}, {
+	testName: "indefinite-array",
+	data:     "9f018202039f0405ffff",
+	expect: []interface{}{
+		json.Number("1"),
+		[]interface{}{json.Number("2"), json.Number("3")},
+		[]interface{}{json.Number("4"), json.Number("5")},
+	},
+}, {
+	testName: "indefinite-map",
+	data:     "bf61610161629f0203ffff",
+	expect: map[string]interface{}{
+		"a": json.Number("1"),
+		"b": []interface{}{json.Number("2"), json.Number("3")},
+	},
+}, {
+	testName: "non-string-keys",
+	data:     "a201020304",
+	expect: map[string]interface{}{
+		"1": json.Number("2"),
+		"3": json.Number("4"),
+	},
+}, {
+	testName:    "unexpected-break",
+	data:        "ff",
+	expectError: `unexpected break`,
+}, {
+	testName:    "reserved-info",
+	data:        "1c",
+	expectError: `invalid CBOR additional information 28`,
+}, {
+	testName:    "trailing-data",
+	data:        "0000",
+	expectError: `1 unexpected bytes after value`,
+}, {
+	testName:    "truncated",
+	data:        "6261",
+	expectError: `unexpected EOF`,
+}, {
+	testName:    "infinity",
+	data:        "f97c00",
+	expectError: `\+Inf cannot be represented in JSON`,
+}, {
+	testName:    "array-key",
+	data:        "a18001",
+	expectError: `cannot use array as an object key`,
+}}
+
+func TestCBORDecode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range cborDecodeTests {
+		c.Run(test.testName, func(c *qt.C) {
+			data, err := hex.DecodeString(test.data)
+			c.Assert(err, qt.Equals, nil)
+			v, err := decodeCBOR(data)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
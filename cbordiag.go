@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cborDiagEncoder encodes values in CBOR diagnostic notation
+// (RFC 8949, section 8), one value per line. It describes
+// exactly the data items produced by the cbor format, so
+// whole numbers are shown as integers and other numbers
+// as floating point.
+type cborDiagEncoder struct {
+	w io.Writer
+}
+
+func newCBORDiagEncoder(w io.Writer) encoder {
+	return &cborDiagEncoder{w: w}
+}
+
+func (e *cborDiagEncoder) Encode(v interface{}) error {
+	var buf bytes.Buffer
+	if err := writeCBORDiag(&buf, v); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func writeCBORDiag(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case float64, json.Number:
+		if i, ok := intValue(v); ok {
+			buf.WriteString(strconv.FormatInt(i, 10))
+			break
+		}
+		buf.WriteString(cborDiagFloat(floatValue(v)))
+	case string:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeCBORDiag(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeCBORDiag(buf, k); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := writeCBORDiag(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot encode %T as CBOR", v)
+	}
+	return nil
+}
+
+// cborDiagFloat returns the diagnostic notation for f.
+// The notation always includes a decimal point or exponent
+// so that it's distinct from an integer.
+func cborDiagFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var cborDiagTests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "scalars",
+	val:      []interface{}{nil, true, false, "a\"b"},
+	expect:   `[null, true, false, "a\"b"]`,
+}, {
+	testName: "numbers",
+	val:      []interface{}{0.0, -1.0, 1.5, json.Number("4294967296"), json.Number("0.25"), 1e100, 1e19, math.Inf(-1)},
+	expect:   `[0, -1, 1.5, 4294967296, 0.25, 1e+100, 1e+19, -Infinity]`,
+}, {
+	testName: "map-with-sorted-keys",
+	val: map[string]interface{}{
+		"b": map[string]interface{}{},
+		"a": []interface{}{},
+	},
+	expect: `{"a": [], "b": {}}`,
+}}
+
+func TestCBORDiagEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range cborDiagTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newCBORDiagEncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect+"\n")
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func evalChoice(_ []string, index int, v interface{}) (interface{}, error) {
+	elems, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("choice value at argument %d is %s, not array", index, valueKind(v))
+	}
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("choice value at argument %d has no elements", index)
+	}
+	return elems[valueRand.Intn(len(elems))], nil
+}
+
+// evalWChoice implements the wchoice keyword. Each element of
+// the array holds a weight and a value separated by a colon;
+// the value is interpreted in the same way as a plain
+// argument, as a number if it looks like one and a string
+// otherwise.
+func evalWChoice(_ []string, index int, v interface{}) (interface{}, error) {
+	elems, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("wchoice value at argument %d is %s, not array", index, valueKind(v))
+	}
+	weights := make([]float64, len(elems))
+	choices := make([]interface{}, len(elems))
+	total := 0.0
+	for i, elem := range elems {
+		s, ok := elem.(string)
+		colon := strings.Index(s, ":")
+		if !ok || colon < 0 {
+			return nil, fmt.Errorf("wchoice element %d at argument %d is not of the form WEIGHT:VALUE", i, index)
+		}
+		w, err := strconv.ParseFloat(s[:colon], 64)
+		if err != nil || !(w >= 0) {
+			return nil, fmt.Errorf("invalid weight %q in wchoice element %d at argument %d", s[:colon], i, index)
+		}
+		weights[i] = w
+		choices[i] = s[colon+1:]
+		if n, err := strconv.ParseFloat(s[colon+1:], 64); err == nil {
+			choices[i] = n
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("wchoice value at argument %d has no elements with positive weight", index)
+	}
+	x := valueRand.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return choices[i], nil
+		}
+		x -= w
+	}
+	// Rounding error; choose the last possible element.
+	for i := len(weights) - 1; ; i-- {
+		if weights[i] > 0 {
+			return choices[i], nil
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	mathrand "math/rand"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var choiceErrorTests = []struct {
+	testName    string
+	args        string
+	expectError string
+}{{
+	testName:    "choice-not-array",
+	args:        "choice a",
+	expectError: `choice value at argument 1 is string, not array`,
+}, {
+	testName:    "choice-empty",
+	args:        "choice .[ ]",
+	expectError: `choice value at argument 1 has no elements`,
+}, {
+	testName:    "wchoice-no-colon",
+	args:        "wchoice .[ 1:a b ]",
+	expectError: `wchoice element 1 at argument 1 is not of the form WEIGHT:VALUE`,
+}, {
+	testName:    "wchoice-bad-weight",
+	args:        "wchoice .[ -1:a ]",
+	expectError: `invalid weight "-1" in wchoice element 0 at argument 1`,
+}, {
+	testName:    "wchoice-zero-weights",
+	args:        "wchoice .[ 0:a 0:b ]",
+	expectError: `wchoice value at argument 1 has no elements with positive weight`,
+}, {
+	testName:    "hermetic",
+	args:        "choice .[ a ]",
+	expectError: `choice at argument 0 is not allowed because it performs I/O or is nondeterministic`,
+}}
+
+func TestChoiceErrors(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range choiceErrorTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.hermetic = test.testName == "hermetic"
+			_, err := p.run()
+			c.Assert(err, qt.ErrorMatches, test.expectError)
+		})
+	}
+}
+
+func TestChoice(t *testing.T) {
+	c := qt.New(t)
+	counts := make(map[interface{}]int)
+	for i := 0; i < 300; i++ {
+		vals, err := newParser(strings.Fields("choice .[ a 2 [ x: 1 ] ]")).run()
+		c.Assert(err, qt.Equals, nil)
+		switch v := vals[0].(type) {
+		case map[string]interface{}:
+			c.Assert(v, qt.DeepEquals, map[string]interface{}{"x": 1.0})
+			counts["object"]++
+		default:
+			counts[v]++
+		}
+	}
+	c.Assert(counts, qt.HasLen, 3)
+	c.Assert(counts["a"] > 0 && counts[2.0] > 0 && counts["object"] > 0, qt.Equals, true)
+}
+
+func TestWChoice(t *testing.T) {
+	c := qt.New(t)
+	counts := make(map[interface{}]int)
+	for i := 0; i < 1000; i++ {
+		vals, err := newParser(strings.Fields("wchoice .[ 3:a 1:2.5 0:never ]")).run()
+		c.Assert(err, qt.Equals, nil)
+		counts[vals[0]]++
+	}
+	c.Assert(counts, qt.HasLen, 2)
+	c.Assert(counts["a"] > 600 && counts["a"] < 900, qt.Equals, true, qt.Commentf("%v", counts))
+	c.Assert(counts[2.5] > 100, qt.Equals, true, qt.Commentf("%v", counts))
+}
+
+func TestChoiceSeeded(t *testing.T) {
+	c := qt.New(t)
+	defer func(oldIDs *idSource, oldRand *mathrand.Rand) {
+		ids, valueRand = oldIDs, oldRand
+	}(ids, valueRand)
+	args := strings.Fields("a: choice .[ 1 2 3 4 5 6 ] b: wchoice .[ 1:x 1:y 1:z ]")
+	run := func() []interface{} {
+		err := setSeed("3")
+		c.Assert(err, qt.Equals, nil)
+		vals, err := newParser(args).run()
+		c.Assert(err, qt.Equals, nil)
+		return vals
+	}
+	c.Assert(run(), qt.DeepEquals, run())
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	chunkElems = flag.Int("chunk", 0, "split each array value into chunks of at most this many elements")
+	chunkBytes = flag.String("chunk-bytes", "", "split each array value into chunks whose compact JSON encoding is at most this size, for example 512KB or 5MB")
+)
+
+// chunkEncoder is an encoder that splits array values into chunks,
+// each of which is passed to emit.
+type chunkEncoder struct {
+	maxElems int
+	maxBytes int
+	emit     func(chunk []interface{}) error
+}
+
+func (e *chunkEncoder) Encode(v interface{}) error {
+	elems, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("chunked output requires array values")
+	}
+	chunks, err := splitChunks(elems, e.maxElems, e.maxBytes)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := e.emit(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkFileEmitter returns a function that encodes each chunk
+// to a new numbered file derived from path: the chunks for out.json
+// are written to out.1.json, out.2.json and so on.
+func chunkFileEmitter(path string, newEncoder func(io.Writer) encoder) func([]interface{}) error {
+	n := 0
+	return func(chunk []interface{}) error {
+		n++
+		f, err := createOutput(chunkPath(path, n))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w := bufio.NewWriter(f)
+		if err := newEncoder(w).Encode(chunk); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return closeOutput(f)
+	}
+}
+
+func chunkPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + strconv.Itoa(n) + ext
+}
+
+// splitChunks splits elems into chunks of at most maxElems elements
+// whose compact JSON encoding is at most maxBytes bytes long.
+// A zero limit means no limit.
+func splitChunks(elems []interface{}, maxElems, maxBytes int) ([][]interface{}, error) {
+	var chunks [][]interface{}
+	start := 0
+	// size holds the encoded size of the current chunk, including the
+	// enclosing brackets but not the final newline.
+	size := 2
+	for i, elem := range elems {
+		elemSize := 0
+		if maxBytes > 0 {
+			data, err := json.Marshal(elem)
+			if err != nil {
+				return nil, err
+			}
+			elemSize = len(data)
+			if 2+elemSize > maxBytes {
+				return nil, fmt.Errorf("array element %d is too large (%d bytes) to fit in a chunk", i, elemSize)
+			}
+			if i > start {
+				// Allow for the separating comma.
+				elemSize++
+			}
+		}
+		if i > start && (maxElems > 0 && i-start >= maxElems || maxBytes > 0 && size+elemSize > maxBytes) {
+			chunks = append(chunks, elems[start:i])
+			start = i
+			size = 2
+			if elemSize > 0 {
+				// No comma before the first element.
+				elemSize--
+			}
+		}
+		size += elemSize
+	}
+	if start < len(elems) {
+		chunks = append(chunks, elems[start:])
+	}
+	return chunks, nil
+}
+
+// parseSize parses a size in bytes with an optional unit suffix:
+// KB, MB and GB are powers of 1000 and KiB, MiB and GiB are powers of 1024.
+func parseSize(s string) (int, error) {
+	units := []struct {
+		suffix string
+		size   int
+	}{
+		{"KiB", 1 << 10},
+		{"MiB", 1 << 20},
+		{"GiB", 1 << 30},
+		{"KB", 1e3},
+		{"MB", 1e6},
+		{"GB", 1e9},
+		{"B", 1},
+	}
+	unit := 1
+	num := s
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			unit = u.size
+			num = strings.TrimSuffix(s, u.suffix)
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int(n * float64(unit)), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func nums(ns ...float64) []interface{} {
+	v := make([]interface{}, len(ns))
+	for i, n := range ns {
+		v[i] = n
+	}
+	return v
+}
+
+var splitChunksTests = []struct {
+	testName    string
+	elems       []interface{}
+	maxElems    int
+	maxBytes    int
+	expect      [][]interface{}
+	expectError string
+}{{
+	testName: "by-count",
+	elems:    nums(1, 2, 3, 4, 5),
+	maxElems: 2,
+	expect:   [][]interface{}{nums(1, 2), nums(3, 4), nums(5)},
+}, {
+	testName: "by-size",
+	elems:    nums(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11),
+	maxBytes: len(`[1,2,3,4]`),
+	expect:   [][]interface{}{nums(1, 2, 3, 4), nums(5, 6, 7, 8), nums(9, 10, 11)},
+}, {
+	testName: "by-count-and-size",
+	elems:    nums(1, 2, 3, 100, 200),
+	maxElems: 3,
+	maxBytes: len(`[1,100]`),
+	expect:   [][]interface{}{nums(1, 2, 3), nums(100), nums(200)},
+}, {
+	testName: "empty",
+	elems:    nil,
+	maxElems: 3,
+	expect:   nil,
+}, {
+	testName:    "element-too-large",
+	elems:       []interface{}{"a", "bcdefgh"},
+	maxBytes:    6,
+	expectError: `array element 1 is too large \(9 bytes\) to fit in a chunk`,
+}}
+
+func TestSplitChunks(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range splitChunksTests {
+		c.Run(test.testName, func(c *qt.C) {
+			chunks, err := splitChunks(test.elems, test.maxElems, test.maxBytes)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(chunks, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+var parseSizeTests = []struct {
+	size        string
+	expect      int
+	expectError string
+}{
+	{size: "100", expect: 100},
+	{size: "100B", expect: 100},
+	{size: "5MB", expect: 5000000},
+	{size: "1.5KiB", expect: 1536},
+	{size: "2 GiB", expect: 2 << 30},
+	{size: "MB", expectError: `invalid size "MB"`},
+	{size: "-1KB", expectError: `invalid size "-1KB"`},
+}
+
+func TestParseSize(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseSizeTests {
+		c.Run(test.size, func(c *qt.C) {
+			n, err := parseSize(test.size)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(n, qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestChunkPath(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(chunkPath("dir/out.json", 3), qt.Equals, "dir/out.3.json")
+	c.Assert(chunkPath("out", 1), qt.Equals, "out.1")
+}
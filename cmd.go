@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var cmdTrim = flag.Bool("cmd-trim", false, "remove all trailing newlines from the output of commands run by the cmd keyword")
+
+// runCmd runs the command held in the argument at the given
+// index and returns its standard output. The command is split
+// into words at white space and run directly rather than by a shell.
+func runCmd(command string, index int) (string, error) {
+	words := strings.Fields(command)
+	if len(words) == 0 {
+		return "", fmt.Errorf("empty command at argument %d", index)
+	}
+	// The output depends on the directory the command runs in,
+	// so that's part of the input when it's memoized.
+	dir, _ := os.Getwd()
+	out, err := memoize("cmd", dir+"\x00"+command, func() (string, error) {
+		c := exec.Command(words[0], words[1:]...)
+		var stderr bytes.Buffer
+		c.Stderr = &stderr
+		out, err := c.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%v: %s", err, msg)
+			}
+			return "", fmt.Errorf("command %q at argument %d failed: %v", command, index, err)
+		}
+		return string(out), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if *cmdTrim {
+		return strings.TrimRight(out, "\r\n"), nil
+	}
+	return out, nil
+}
+
+// expandCmd reads the command that follows a cmd argument at
+// the given index that's used as the argument of another keyword,
+// and returns its output.
+func (p *parser) expandCmd(index int) (string, error) {
+	if p.hermetic {
+		return "", fmt.Errorf("cmd at argument %d is not allowed because it performs I/O or is nondeterministic", index)
+	}
+	command, err := p.mustNext("command")
+	if err != nil {
+		return "", err
+	}
+	return runCmd(command, p.index-1)
+}
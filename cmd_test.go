@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var cmdTests = []struct {
+	testName    string
+	args        []string
+	trim        bool
+	hermetic    bool
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "output",
+	args:     []string{"out:", "cmd", "echo hello   world"},
+	expect:   []interface{}{map[string]interface{}{"out": "hello world\n"}},
+}, {
+	testName: "trim",
+	args:     []string{"cmd", "printf a\\n\\n"},
+	trim:     true,
+	expect:   []interface{}{"a"},
+}, {
+	testName: "no-shell",
+	args:     []string{"cmd", "echo $HOME | x"},
+	expect:   []interface{}{"$HOME | x\n"},
+}, {
+	testName: "keyword-argument",
+	args:     []string{"n:", "json", "cmd", "echo [1]"},
+	expect:   []interface{}{map[string]interface{}{"n": []interface{}{json.Number("1")}}},
+}, {
+	testName: "str-argument",
+	args:     []string{"str", "cmd", "echo hello"},
+	expect:   []interface{}{"cmd", "echo hello"},
+}, {
+	testName:    "command-not-expanded",
+	args:        []string{"cmd", "cmd", "echo hello"},
+	expectError: `command "cmd" at argument 1 failed: .*`,
+}, {
+	testName:    "failure",
+	args:        []string{"cmd", "false"},
+	expectError: `command "false" at argument 1 failed: exit status 1`,
+}, {
+	testName:    "stderr",
+	args:        []string{"cmd", "ls /nonexistent-json-test"},
+	expectError: `command "ls /nonexistent-json-test" at argument 1 failed: exit status [0-9]+: .*nonexistent-json-test.*`,
+}, {
+	testName:    "empty",
+	args:        []string{"cmd", " "},
+	expectError: `empty command at argument 1`,
+}, {
+	testName:    "hermetic",
+	args:        []string{"cmd", "echo"},
+	hermetic:    true,
+	expectError: `cmd at argument 0 is not allowed because it performs I/O or is nondeterministic`,
+}, {
+	testName:    "hermetic-keyword-argument",
+	args:        []string{"json", "cmd", "echo"},
+	hermetic:    true,
+	expectError: `cmd at argument 1 is not allowed because it performs I/O or is nondeterministic`,
+}}
+
+func TestCmd(t *testing.T) {
+	c := qt.New(t)
+	defer func(trim bool) {
+		*cmdTrim = trim
+	}(*cmdTrim)
+	for _, test := range cmdTests {
+		c.Run(test.testName, func(c *qt.C) {
+			*cmdTrim = test.trim
+			p := newParser(test.args)
+			p.hermetic = test.hermetic
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, test.expect)
+		})
+	}
+}
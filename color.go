@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var colorFormat = flag.String("color-format", "hex", "representation of values produced by the color keyword: hex (#rrggbb) or rgb (rgb(r, g, b))")
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	rgbColorPattern = regexp.MustCompile(`^rgb\(\s*(\d+)\s*,\s*(\d+)\s*,\s*(\d+)\s*\)$`)
+)
+
+// parseColor parses a color in #rgb, #rrggbb or rgb(r, g, b) form
+// and returns its red, green and blue components.
+func parseColor(s string) ([3]uint8, error) {
+	var rgb [3]uint8
+	if m := hexColorPattern.FindStringSubmatch(s); m != nil {
+		hex := m[1]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		for i := range rgb {
+			n, _ := strconv.ParseUint(hex[i*2:i*2+2], 16, 8)
+			rgb[i] = uint8(n)
+		}
+		return rgb, nil
+	}
+	if m := rgbColorPattern.FindStringSubmatch(strings.ToLower(s)); m != nil {
+		for i := range rgb {
+			n, err := strconv.ParseUint(m[i+1], 10, 8)
+			if err != nil {
+				return rgb, fmt.Errorf("component %s is out of range 0-255", m[i+1])
+			}
+			rgb[i] = uint8(n)
+		}
+		return rgb, nil
+	}
+	return rgb, fmt.Errorf("not in #rgb, #rrggbb or rgb(r, g, b) form")
+}
+
+// formatColor returns the given color in the given format,
+// which must be "hex" or "rgb".
+func formatColor(rgb [3]uint8, format string) string {
+	if format == "rgb" {
+		return fmt.Sprintf("rgb(%d, %d, %d)", rgb[0], rgb[1], rgb[2])
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
+
+func evalColor(_ []string, index int, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("color value at argument %d is %s, not string", index, valueKind(v))
+	}
+	rgb, err := parseColor(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q at argument %d: %v", s, index, err)
+	}
+	return formatColor(rgb, *colorFormat), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var colorTests = []struct {
+	testName    string
+	arg         string
+	format      string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "short-hex",
+	arg:      "#F0a",
+	format:   "hex",
+	expect:   "#ff00aa",
+}, {
+	testName: "long-hex",
+	arg:      "#1E90FF",
+	format:   "hex",
+	expect:   "#1e90ff",
+}, {
+	testName: "rgb-to-hex",
+	arg:      "RGB(30,144, 255)",
+	format:   "hex",
+	expect:   "#1e90ff",
+}, {
+	testName: "hex-to-rgb",
+	arg:      "#1e90ff",
+	format:   "rgb",
+	expect:   "rgb(30, 144, 255)",
+}, {
+	testName:    "rgb-out-of-range",
+	arg:         "rgb(256, 0, 0)",
+	format:      "hex",
+	expectError: `invalid color "rgb\(256, 0, 0\)" at argument 1: component 256 is out of range 0-255`,
+}, {
+	testName:    "named",
+	arg:         "red",
+	format:      "hex",
+	expectError: `invalid color "red" at argument 1: not in #rgb, #rrggbb or rgb\(r, g, b\) form`,
+}, {
+	testName:    "bad-hex-length",
+	arg:         "#abcd",
+	format:      "hex",
+	expectError: `invalid color "#abcd" at argument 1: not in #rgb, #rrggbb or rgb\(r, g, b\) form`,
+}}
+
+func TestColor(t *testing.T) {
+	c := qt.New(t)
+	defer func(format string) {
+		*colorFormat = format
+	}(*colorFormat)
+	for _, test := range colorTests {
+		c.Run(test.testName, func(c *qt.C) {
+			*colorFormat = test.format
+			vals, err := newParser([]string{"color", test.arg}).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+)
+
+var compactArrays = flag.Bool("compact-arrays", false, "in json and jsonc output, indent objects but print arrays that hold no objects or arrays on a single line; implies -indent")
+
+// compactArraysEncoder encodes values as indented JSON
+// but with arrays of scalars on a single line.
+type compactArraysEncoder struct {
+	w io.Writer
+}
+
+func (e *compactArraysEncoder) Encode(v interface{}) error {
+	var buf bytes.Buffer
+	if err := writeJSONCValue(&buf, v, "", "", nil); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// isScalarArray reports whether a holds no objects or arrays.
+func isScalarArray(a []interface{}) bool {
+	for _, elem := range a {
+		switch elem.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// writeCompactArray writes the array of scalars a on a single line.
+func writeCompactArray(buf *bytes.Buffer, a []interface{}) error {
+	buf.WriteString("[")
+	for i, elem := range a {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		data, err := json.Marshal(elem)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	buf.WriteString("]")
+	return nil
+}
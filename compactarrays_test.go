@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var compactArraysTests = []struct {
+	testName string
+	args     string
+	expect   string
+}{{
+	testName: "object",
+	args:     "name: web ports: .[ 80 443 ] tags: .[ ] env: .[ [ k: a ] ] m: [ x: .[ a null true ] ]",
+	expect: `{
+	"env": [
+		{
+			"k": "a"
+		}
+	],
+	"m": {
+		"x": ["a", null, true]
+	},
+	"name": "web",
+	"ports": [80, 443],
+	"tags": []
+}
+`,
+}, {
+	testName: "nested-arrays",
+	args:     ".[ .[ 1 2 ] .[ 3 ] ]",
+	expect: `[
+	[1, 2],
+	[3]
+]
+`,
+}, {
+	testName: "multiple-values",
+	args:     ".[ a ] 1",
+	expect: `["a"]
+1
+`,
+}}
+
+func TestCompactArraysEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range compactArraysTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(compactArrays, true)
+			vals, err := newParser(strings.Fields(test.args)).run()
+			c.Assert(err, qt.Equals, nil)
+			var buf bytes.Buffer
+			enc := newJSONEncoder(&buf)
+			for _, v := range vals {
+				err := enc.Encode(v)
+				c.Assert(err, qt.Equals, nil)
+			}
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var contactTests = []struct {
+	testName    string
+	args        []string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "email",
+	args:     []string{"email", "Jo.Bloggs@Example.COM"},
+	expect:   "Jo.Bloggs@example.com",
+}, {
+	testName:    "email-no-domain",
+	args:        []string{"email", "jo"},
+	expectError: `invalid email address "jo" at argument 1: mail: missing '@' or angle-addr`,
+}, {
+	testName:    "email-with-name",
+	args:        []string{"email", "Jo <jo@example.com>"},
+	expectError: `invalid email address "Jo <jo@example.com>" at argument 1: address must not include a name or angle brackets`,
+}, {
+	testName:    "email-not-string",
+	args:        []string{"email", "true"},
+	expectError: `email value at argument 1 is bool, not string`,
+}, {
+	testName: "phone-national",
+	args:     []string{"phone", "GB", "07700 900123"},
+	expect:   "+447700900123",
+}, {
+	testName: "phone-international",
+	args:     []string{"phone", "gb", "+1 (212) 555-0100"},
+	expect:   "+12125550100",
+}, {
+	testName: "phone-double-zero",
+	args:     []string{"phone", "DE", "0049 30 1234567"},
+	expect:   "+49301234567",
+}, {
+	testName: "phone-nanp-trunk",
+	args:     []string{"phone", "US", "1-212-555-0100"},
+	expect:   "+12125550100",
+}, {
+	testName: "phone-keep-zero",
+	args:     []string{"phone", "IT", "06 1234 5678"},
+	expect:   "+390612345678",
+}, {
+	testName:    "phone-too-short",
+	args:        []string{"phone", "GB", "+44 12"},
+	expectError: `invalid phone number "\+44 12" at argument 2: number has 4 digits, not between 8 and 15`,
+}, {
+	testName:    "phone-bad-character",
+	args:        []string{"phone", "GB", "0770x"},
+	expectError: `invalid phone number "0770x" at argument 2: unexpected character 'x'`,
+}, {
+	testName:    "phone-bad-region",
+	args:        []string{"phone", "XY", "123"},
+	expectError: `invalid region "XY" at argument 1`,
+}, {
+	testName: "phone-digits-only",
+	args:     []string{"phone", "GB", "07700900123"},
+	expect:   "+447700900123",
+}}
+
+func TestContact(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range contactTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(test.args).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
+
+func TestCallingCodesCoverCountries(t *testing.T) {
+	c := qt.New(t)
+	for code := range isoCountries {
+		c.Check(callingCodes[code], qt.Not(qt.Equals), "", qt.Commentf("%s", code))
+	}
+	c.Assert(len(callingCodes), qt.Equals, len(isoCountries))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// decoders holds the input formats available to the convert
+// subcommand, keyed by the name used with its -from flag.
+var decoders = map[string]func(data []byte) (interface{}, error){
+	"cbor":    decodeCBOR,
+	"csv":     textDecoder(func(s string) (interface{}, error) { return parseCSV(s) }),
+	"hjson":   textDecoder(parseHjson),
+	"json":    decodeJSON,
+	"json5":   textDecoder(parseJSON5),
+	"msgpack": decodeMsgpack,
+	"ndjson":  textDecoder(parseNDJSON),
+	"query":   textDecoder(parseQuery),
+	"toml":    textDecoder(parseTOML),
+	"xml":     textDecoder(parseXML),
+	"yaml":    textDecoder(parseYAML),
+}
+
+func textDecoder(parse func(s string) (interface{}, error)) func(data []byte) (interface{}, error) {
+	return func(data []byte) (interface{}, error) {
+		return parse(string(data))
+	}
+}
+
+// decodeJSON decodes data, which must hold a single JSON value.
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after value")
+	}
+	return v, nil
+}
+
+func decoderNames() string {
+	names := make([]string, 0, len(decoders))
+	for name := range decoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// runConvert implements the convert subcommand, which reads
+// a document from the file named in args, or from standard
+// input, and writes it in another format.
+func runConvert(args []string) {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := flags.String("from", "json", "input format; one of "+decoderNames())
+	to := flags.String("to", "json", "output format; one of "+formatNames())
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "json convert [flags] [file]\n")
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nThe convert subcommand reads a document from the named file or from\nstandard input and writes it in another format.\n")
+		os.Exit(2)
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+	}
+	decode, ok := decoders[*from]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "json: unknown input format %q\n", *from)
+		os.Exit(2)
+	}
+	newEncoder, ok := formats[*to]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "json: unknown output format %q\n", *to)
+		os.Exit(2)
+	}
+	var data []byte
+	var err error
+	if flags.NArg() == 1 {
+		data, err = ioutil.ReadFile(flags.Arg(0))
+	} else {
+		data, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+	v, err := decode(data)
+	if err != nil {
+		fatalf("cannot decode %s: %v", *from, err)
+	}
+	if err := writeOutput(interruptContext(), args, []interface{}{v}, nil, newEncoder, 0, 0); err != nil {
+		removePartialOutput()
+		fatalf("%v", err)
+	}
+}
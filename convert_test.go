@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var decodersTests = []struct {
+	testName    string
+	from        string
+	data        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "json",
+	from:     "json",
+	data:     `{"a": [1, "x"]}`,
+	expect:   map[string]interface{}{"a": []interface{}{json.Number("1"), "x"}},
+}, {
+	testName:    "json-trailing",
+	from:        "json",
+	data:        `1 2`,
+	expectError: `unexpected data after value`,
+}, {
+	testName:    "json-invalid",
+	from:        "json",
+	data:        `{`,
+	expectError: `unexpected EOF`,
+}, {
+	testName: "yaml",
+	from:     "yaml",
+	data:     "a: [x, 2]\n",
+	expect:   map[string]interface{}{"a": []interface{}{"x", json.Number("2")}},
+}, {
+	testName: "ndjson",
+	from:     "ndjson",
+	data:     "1\n\"x\"\n",
+	expect:   []interface{}{json.Number("1"), "x"},
+}, {
+	testName: "csv",
+	from:     "csv",
+	data:     "a,b\n1,x\n",
+	expect:   []interface{}{map[string]interface{}{"a": "1", "b": "x"}},
+}, {
+	testName: "query",
+	from:     "query",
+	data:     "a=1&a=2",
+	expect:   map[string]interface{}{"a": []interface{}{"1", "2"}},
+}, {
+	testName: "msgpack",
+	from:     "msgpack",
+	data:     "\x81\xa1a\x01",
+	expect:   map[string]interface{}{"a": json.Number("1")},
+}}
+
+func TestDecoders(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range decodersTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := decoders[test.from]([]byte(test.data))
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestDecodersRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	v := map[string]interface{}{
+		"a": []interface{}{json.Number("1"), "x", true, nil},
+		"b": map[string]interface{}{"c": "d"},
+	}
+	for _, name := range []string{"json", "json5", "msgpack", "cbor"} {
+		c.Run(name, func(c *qt.C) {
+			var buf bytes.Buffer
+			enc := formats[name](&buf)
+			c.Assert(enc.Encode(v), qt.Equals, nil)
+			if closer, ok := enc.(encoderCloser); ok {
+				c.Assert(closer.Close(), qt.Equals, nil)
+			}
+			got, err := decoders[name](buf.Bytes())
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(got, qt.DeepEquals, v)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var counterFile = flag.String("counter-file", "", "file in which the values of the counters used by the counter keyword are kept between runs")
+
+// defaultCounter holds the name of the counter used
+// by the counter keyword when no name is given.
+const defaultCounter = "default"
+
+// counterValue implements the counter keyword at the given
+// argument index. The name of the counter is optional: if
+// the keyword is followed by a key, a closing ] or nothing
+// at all, the default counter is used.
+func (p *parser) counterValue(start int) (interface{}, error) {
+	name := defaultCounter
+	if next, ok := p.peek(); ok && isCounterName(next) {
+		name = next
+		p.next()
+	}
+	return p.nextCounter(name), nil
+}
+
+// isCounterName reports whether the argument following
+// a counter keyword is the name of the counter.
+func isCounterName(arg string) bool {
+	return arg != "]" && !isKey(arg)
+}
+
+// nextCounter returns the next value of the named counter,
+// starting at 1.
+func (p *parser) nextCounter(name string) float64 {
+	if p.counters == nil {
+		p.counters = make(map[string]int64)
+	}
+	p.counters[name]++
+	return float64(p.counters[name])
+}
+
+// loadCounters reads the counter values from the named file,
+// which holds a JSON object mapping counter names to their
+// most recent values. A missing file holds no counters.
+func loadCounters(file string) (map[string]int64, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return make(map[string]int64), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	counters := make(map[string]int64)
+	if err := json.Unmarshal(data, &counters); err != nil {
+		return nil, fmt.Errorf("cannot parse counter file %s: %v", file, err)
+	}
+	return counters, nil
+}
+
+// saveCounters writes the counter values to the named file.
+// The file is replaced atomically so that it's not left
+// partially written.
+func saveCounters(file string, counters map[string]int64) error {
+	data, err := json.MarshalIndent(counters, "", "\t")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), ".counters")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var counterTests = []struct {
+	testName    string
+	args        string
+	counters    map[string]int64
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "sequence",
+	args:     "counter a counter a counter b counter a",
+	expect:   []interface{}{1.0, 2.0, 1.0, 3.0},
+}, {
+	testName: "objects",
+	args:     ".[ [ id: counter rec ] [ id: counter rec ] ]",
+	expect: []interface{}{[]interface{}{
+		map[string]interface{}{"id": 1.0},
+		map[string]interface{}{"id": 2.0},
+	}},
+}, {
+	testName: "existing",
+	args:     "counter a counter b",
+	counters: map[string]int64{"a": 41},
+	expect:   []interface{}{42.0, 1.0},
+}, {
+	testName: "default",
+	args:     "a: counter b: counter c: .[ counter counter ] d: counter",
+	counters: map[string]int64{"default": 2},
+	expect: []interface{}{map[string]interface{}{
+		"a": 3.0,
+		"b": 4.0,
+		"c": []interface{}{1.0},
+		"d": 5.0,
+	}},
+}, {
+	testName: "default-at-end",
+	args:     "counter",
+	expect:   []interface{}{1.0},
+}}
+
+func TestCounter(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range counterTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.counters = test.counters
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestCounterFile(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "counters.json")
+	counters, err := loadCounters(file)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(counters, qt.DeepEquals, map[string]int64{})
+
+	counters["rec"] = 3
+	err = saveCounters(file, counters)
+	c.Assert(err, qt.Equals, nil)
+	counters, err = loadCounters(file)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(counters, qt.DeepEquals, map[string]int64{"rec": 3})
+
+	err = ioutil.WriteFile(file, []byte(`{"rec": "x"}`), 0666)
+	c.Assert(err, qt.Equals, nil)
+	_, err = loadCounters(file)
+	c.Assert(err, qt.ErrorMatches, `cannot parse counter file .*counters.json: .*`)
+}
@@ -0,0 +1,49 @@
+//go:build cshared
+// +build cshared
+
+// This file exports a C API for embedding the parser in other
+// programs. Build it with:
+//
+//	go build -tags cshared -buildmode=c-shared -o libjsonargs.so
+//
+// which also produces the header file libjsonargs.h.
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import "unsafe"
+
+// json_build parses the argc arguments in argv as the json command
+// would and stores in *out a newly allocated string holding the JSON
+// output. It returns 0 on success. On failure, it returns 1 and
+// *out holds the error message instead. In both cases, the caller
+// must free *out with json_free.
+//
+//export json_build
+func json_build(argc C.int, argv **C.char, out **C.char) C.int {
+	args := make([]string, argc)
+	if argc > 0 {
+		cargs := (*[1 << 28]*C.char)(unsafe.Pointer(argv))[:argc:argc]
+		for i, a := range cargs {
+			args[i] = C.GoString(a)
+		}
+	}
+	output, _, err := evalArgs(args, false)
+	if err != nil {
+		*out = C.CString(err.Error())
+		return 1
+	}
+	*out = C.CString(output)
+	return 0
+}
+
+// json_free frees a string returned by json_build.
+//
+//export json_free
+func json_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
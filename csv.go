@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvEncoder encodes arrays as CSV. See tableRows for details.
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) encoder {
+	return &csvEncoder{
+		w: csv.NewWriter(w),
+	}
+}
+
+func (e *csvEncoder) Encode(v interface{}) error {
+	rows, err := tableRows(v, "CSV")
+	if err != nil {
+		return err
+	}
+	return e.w.WriteAll(rows)
+}
+
+// tsvEncoder encodes arrays as tab-separated values. See tableRows
+// for details. Fields never contain tabs or newlines: they are
+// escaped as \t and \n (and backslash as \\) so that the output
+// can be processed by line- and tab-oriented tools such as cut and awk.
+type tsvEncoder struct {
+	w io.Writer
+}
+
+func newTSVEncoder(w io.Writer) encoder {
+	return &tsvEncoder{
+		w: w,
+	}
+}
+
+var tsvEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\t", `\t`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+func (e *tsvEncoder) Encode(v interface{}) error {
+	rows, err := tableRows(v, "TSV")
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for i, field := range row {
+			row[i] = tsvEscaper.Replace(field)
+		}
+		if _, err := io.WriteString(e.w, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableRows returns the rows of a table representing v, which must
+// be an array. An array of scalars is represented as one value per row.
+// An array of flat objects is represented as a header row holding the
+// union of the keys of all the objects in sorted order followed by
+// one row per object, with empty fields for absent keys.
+// The format name is used in error messages.
+func tableRows(v interface{}, format string) ([][]string, error) {
+	elems, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s output requires an array of objects or scalars", format)
+	}
+	if len(elems) == 0 {
+		return nil, nil
+	}
+	if _, ok := elems[0].(map[string]interface{}); !ok {
+		rows := make([][]string, len(elems))
+		for i, elem := range elems {
+			if isComposite(elem) {
+				return nil, fmt.Errorf("%s output requires an array of objects or scalars but element %d is %T", format, i, elem)
+			}
+			text, err := scalarText(elem)
+			if err != nil {
+				return nil, err
+			}
+			rows[i] = []string{text}
+		}
+		return rows, nil
+	}
+	objs := make([]map[string]interface{}, len(elems))
+	union := make(map[string]interface{})
+	for i, elem := range elems {
+		obj, ok := elem.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s output requires an array of objects or scalars but element %d is %T", format, i, elem)
+		}
+		for k := range obj {
+			union[k] = nil
+		}
+		objs[i] = obj
+	}
+	header := sortedKeys(union)
+	rows := [][]string{header}
+	for i, obj := range objs {
+		row := make([]string, len(header))
+		for j, k := range header {
+			if isComposite(obj[k]) {
+				return nil, fmt.Errorf("cannot encode non-flat value of %q in element %d as %s", k, i, format)
+			}
+			text, err := scalarText(obj[k])
+			if err != nil {
+				return nil, err
+			}
+			row[j] = text
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseCSV parses s as CSV with a header row and returns
+// an array holding an object for each record, keyed by the
+// names in the header. All values are strings.
+func parseCSV(s string) ([]interface{}, error) {
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no header row")
+	}
+	header := records[0]
+	for i, name := range header {
+		for _, prev := range header[:i] {
+			if name == prev {
+				return nil, fmt.Errorf("duplicate column name %q", name)
+			}
+		}
+	}
+	rows := make([]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{})
+		for i, field := range record {
+			row[header[i]] = field
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var csvTests = []struct {
+	testName    string
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "union-of-keys",
+	val: []interface{}{
+		map[string]interface{}{"b": "x,y", "a": 1.0},
+		map[string]interface{}{"c": true, "a": nil},
+	},
+	expect: "a,b,c\n1,\"x,y\",\n,,true\n",
+}, {
+	testName: "scalars",
+	val:      []interface{}{"a b", 1.5, nil, true},
+	expect:   "a b\n1.5\n\ntrue\n",
+}, {
+	testName: "empty-array",
+	val:      []interface{}{},
+	expect:   "",
+}, {
+	testName:    "not-an-array",
+	val:         map[string]interface{}{},
+	expectError: `CSV output requires an array of objects or scalars`,
+}, {
+	testName:    "not-an-object",
+	val:         []interface{}{map[string]interface{}{}, "x"},
+	expectError: `CSV output requires an array of objects or scalars but element 1 is string`,
+}, {
+	testName:    "not-a-scalar",
+	val:         []interface{}{"x", []interface{}{}},
+	expectError: `CSV output requires an array of objects or scalars but element 1 is \[\]interface \{\}`,
+}, {
+	testName: "nested-value",
+	val: []interface{}{
+		map[string]interface{}{"a": []interface{}{}},
+	},
+	expectError: `cannot encode non-flat value of "a" in element 0 as CSV`,
+}}
+
+func TestCSVEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range csvTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newCSVEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
+
+var tsvTests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "objects",
+	val: []interface{}{
+		map[string]interface{}{"b": "x\ty", "a": 1.0},
+		map[string]interface{}{"c": "line1\nline2", "a": `back\slash`},
+	},
+	expect: "a\tb\tc\n1\tx\\ty\t\nback\\\\slash\t\tline1\\nline2\n",
+}, {
+	testName: "scalars",
+	val:      []interface{}{"a b", 2.0, false},
+	expect:   "a b\n2\nfalse\n",
+}}
+
+func TestTSVEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range tsvTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newTSVEncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
+
+var parseCSVTests = []struct {
+	testName    string
+	csv         string
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "records",
+	csv:      "name,age\nbob,42\n\"a, b\",\n",
+	expect: []interface{}{
+		map[string]interface{}{"name": "bob", "age": "42"},
+		map[string]interface{}{"name": "a, b", "age": ""},
+	},
+}, {
+	testName: "header-only",
+	csv:      "name,age\n",
+	expect:   []interface{}{},
+}, {
+	testName:    "empty",
+	csv:         "",
+	expectError: `no header row`,
+}, {
+	testName:    "duplicate-column",
+	csv:         "a,b,a\n1,2,3\n",
+	expectError: `duplicate column name "a"`,
+}, {
+	testName:    "wrong-field-count",
+	csv:         "a,b\n1\n",
+	expectError: `record on line 2: wrong number of fields`,
+}}
+
+func TestParseCSV(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseCSVTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseCSV(test.csv)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var ednKeywords = flag.Bool("edn-keywords", false, "in EDN output, encode object keys as keywords where possible rather than as strings")
+
+// ednEncoder encodes values as EDN (see https://github.com/edn-format/edn),
+// one value per line. Objects become maps and arrays become vectors.
+type ednEncoder struct {
+	w io.Writer
+}
+
+func newEDNEncoder(w io.Writer) encoder {
+	return &ednEncoder{w: w}
+}
+
+func (e *ednEncoder) Encode(v interface{}) error {
+	var buf bytes.Buffer
+	if err := writeEDNValue(&buf, v); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func writeEDNValue(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("nil")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeEDNString(buf, v)
+	case float64, json.Number:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			if err := writeEDNValue(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if *ednKeywords && isEDNKeywordName(k) {
+				buf.WriteString(":" + k)
+			} else {
+				writeEDNString(buf, k)
+			}
+			buf.WriteByte(' ')
+			if err := writeEDNValue(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot encode %T as EDN", v)
+	}
+	return nil
+}
+
+func writeEDNString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// isEDNKeywordName reports whether s can be used as
+// the name of an EDN keyword without a namespace.
+func isEDNKeywordName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9':
+			if i == 0 || i == 1 && (s[0] == '-' || s[0] == '+' || s[0] == '.') {
+				return false
+			}
+		case c == '#' || c == ':':
+			if i == 0 {
+				return false
+			}
+		default:
+			if !isEDNSymbolPunct(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isEDNSymbolPunct(c byte) bool {
+	switch c {
+	case '.', '*', '+', '!', '-', '_', '?', '$', '%', '&', '=', '<', '>':
+		return true
+	}
+	return false
+}
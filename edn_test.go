@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var ednTests = []struct {
+	testName string
+	keywords bool
+	val      interface{}
+	expect   string
+}{{
+	testName: "scalars",
+	val:      []interface{}{nil, true, false, 1.0, 0.5, 1e100, json.Number("12"), "a\"b\n\x01"},
+	expect:   `[nil true false 1 0.5 1e+100 12 "a\"b\n\u0001"]` + "\n",
+}, {
+	testName: "string-keys",
+	val: map[string]interface{}{
+		"b": []interface{}{},
+		"a": map[string]interface{}{},
+	},
+	expect: `{"a" {}, "b" []}` + "\n",
+}, {
+	testName: "keyword-keys",
+	keywords: true,
+	val: map[string]interface{}{
+		"name":     "x",
+		"is-ok?":   true,
+		"1a":       1.0,
+		"-1":       2.0,
+		"a b":      3.0,
+		"a/b":      4.0,
+		"":         5.0,
+		"x:y":      6.0,
+		"with#sym": 7.0,
+	},
+	expect: `{"" 5, "-1" 2, "1a" 1, "a b" 3, "a/b" 4, :is-ok? true, :name "x", :with#sym 7, :x:y 6}` + "\n",
+}}
+
+func TestEDNEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range ednTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(ednKeywords, test.keywords)
+			var buf bytes.Buffer
+			err := newEDNEncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "bytes"
+
+// evalArgs returns the JSON encoding of the values specified
+// by args, as printed by the json command with no flags, and
+// the class of each argument. It's used when the parser is
+// embedded in another program. If hermetic is true, impure
+// keywords are rejected.
+func evalArgs(args []string, hermetic bool) (string, []argClass, error) {
+	p := newParser(args)
+	p.hermetic = hermetic
+	vals, err := p.run()
+	if err != nil {
+		return "", p.classes, err
+	}
+	var buf bytes.Buffer
+	enc := newJSONEncoder(&buf)
+	for _, v := range vals {
+		if err := enc.Encode(v); err != nil {
+			return "", p.classes, err
+		}
+	}
+	return buf.String(), p.classes, nil
+}
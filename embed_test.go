@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestEvalArgs(t *testing.T) {
+	c := qt.New(t)
+	out, classes, err := evalArgs([]string{"a:", ".[", "1", "]"}, false)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(out, qt.Equals, "{\"a\":[1]}\n")
+	c.Assert(classes, qt.DeepEquals, []argClass{classKey, classDelim, classValue, classDelim})
+
+	out, _, err = evalArgs([]string{"num", "x"}, false)
+	c.Assert(err, qt.ErrorMatches, `invalid number "x" at argument 1`)
+	c.Assert(out, qt.Equals, "")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var emits emitFlag
+
+func init() {
+	flag.Var(&emits, "emit", "also write the output in another format to a file, specified as FORMAT=FILE; may be repeated")
+}
+
+// emitTarget holds a format and the file that
+// output in that format is written to.
+type emitTarget struct {
+	format string
+	path   string
+}
+
+// emitFlag implements flag.Value for the -emit flag.
+type emitFlag []emitTarget
+
+func (f *emitFlag) String() string {
+	var parts []string
+	for _, t := range *f {
+		parts = append(parts, t.format+"="+t.path)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f *emitFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return fmt.Errorf("expected FORMAT=FILE")
+	}
+	format, path := s[:i], s[i+1:]
+	if _, ok := formats[format]; !ok {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	*f = append(*f, emitTarget{
+		format: format,
+		path:   path,
+	})
+	return nil
+}
+
+// emitEncoder is an encoder that encodes each value
+// to the primary encoder and to each of the -emit files.
+type emitEncoder struct {
+	enc     encoder
+	outputs []*emitOutput
+}
+
+type emitOutput struct {
+	f   *os.File
+	w   *bufio.Writer
+	enc encoder
+}
+
+// newEmitEncoder returns an encoder that writes to enc
+// and to the files for the given targets, which
+// are created immediately. The comments are printed
+// by any jsonc target, as for withComments.
+func newEmitEncoder(enc encoder, targets []emitTarget, comments []map[string]string) (*emitEncoder, error) {
+	e := &emitEncoder{
+		enc: enc,
+	}
+	for _, t := range targets {
+		f, err := createOutput(t.path)
+		if err != nil {
+			return nil, err
+		}
+		w := bufio.NewWriter(f)
+		e.outputs = append(e.outputs, &emitOutput{
+			f:   f,
+			w:   w,
+			enc: withComments(formats[t.format], comments)(w),
+		})
+	}
+	return e, nil
+}
+
+func (e *emitEncoder) Encode(v interface{}) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	for _, out := range e.outputs {
+		if err := out.enc.Encode(v); err != nil {
+			return fmt.Errorf("cannot write %s: %v", out.f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Close closes the primary encoder if it's an encoderCloser
+// and finishes writing all the files.
+func (e *emitEncoder) Close() error {
+	if closer, ok := e.enc.(encoderCloser); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	for _, out := range e.outputs {
+		if closer, ok := out.enc.(encoderCloser); ok {
+			if err := closer.Close(); err != nil {
+				return err
+			}
+		}
+		if err := out.w.Flush(); err != nil {
+			return err
+		}
+		if err := closeOutput(out.f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var emitFlagTests = []struct {
+	testName    string
+	arg         string
+	expect      emitTarget
+	expectError string
+}{{
+	testName: "ok",
+	arg:      "edn=out=1.edn",
+	expect:   emitTarget{format: "edn", path: "out=1.edn"},
+}, {
+	testName: "schema",
+	arg:      "schema=out.schema.json",
+	expect:   emitTarget{format: "schema", path: "out.schema.json"},
+}, {
+	testName:    "no-file",
+	arg:         "json=",
+	expectError: `expected FORMAT=FILE`,
+}, {
+	testName:    "no-equals",
+	arg:         "json",
+	expectError: `expected FORMAT=FILE`,
+}, {
+	testName:    "unknown-format",
+	arg:         "foo=out",
+	expectError: `unknown output format "foo"`,
+}}
+
+func TestEmitFlag(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range emitFlagTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var f emitFlag
+			err := f.Set(test.arg)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(f, qt.HasLen, 1)
+			c.Assert(f[0], qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestEmitEncoder(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	ednFile := filepath.Join(dir, "out.edn")
+	envFile := filepath.Join(dir, "out.env")
+	var buf bytes.Buffer
+	enc, err := newEmitEncoder(newJSONEncoder(&buf), []emitTarget{{
+		format: "edn",
+		path:   ednFile,
+	}, {
+		format: "env",
+		path:   envFile,
+	}}, nil)
+	c.Assert(err, qt.Equals, nil)
+	for _, v := range []interface{}{
+		map[string]interface{}{"a": 1.0},
+		map[string]interface{}{"b": "x"},
+	} {
+		err := enc.Encode(v)
+		c.Assert(err, qt.Equals, nil)
+	}
+	err = enc.Close()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(buf.String(), qt.Equals, "{\"a\":1}\n{\"b\":\"x\"}\n")
+	data, err := ioutil.ReadFile(ednFile)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(string(data), qt.Equals, "{\"a\" 1}\n{\"b\" \"x\"}\n")
+	data, err = ioutil.ReadFile(envFile)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(string(data), qt.Equals, "a=1\nb=x\n")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// envEncoder encodes objects as lines of KEY=value assignments
+// as found in .env files, quoting values so that the output
+// can also be sourced by a POSIX shell. Nested objects and
+// arrays are flattened, joining the keys and array
+// indexes on the path to each value with underscores.
+type envEncoder struct {
+	w io.Writer
+}
+
+func newEnvEncoder(w io.Writer) encoder {
+	return &envEncoder{w: w}
+}
+
+func (e *envEncoder) Encode(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("env output requires an object")
+	}
+	var buf bytes.Buffer
+	if err := writeEnvMembers(&buf, "", obj); err != nil {
+		return err
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func writeEnvMembers(buf *bytes.Buffer, prefix string, obj map[string]interface{}) error {
+	for _, k := range sortedKeys(obj) {
+		if err := writeEnvValue(buf, prefix+k, obj[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeEnvValue(buf *bytes.Buffer, name string, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return writeEnvMembers(buf, name+"_", v)
+	case []interface{}:
+		for i, elem := range v {
+			if err := writeEnvValue(buf, name+"_"+strconv.Itoa(i), elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !isEnvName(name) {
+		return fmt.Errorf("cannot use %q as an environment variable name", name)
+	}
+	text, err := scalarText(v)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "%s=%s\n", name, shellQuote(text))
+	return nil
+}
+
+// shellQuote quotes s so that it is interpreted literally
+// by a POSIX shell. Strings that need no quoting are
+// returned unchanged.
+func shellQuote(s string) string {
+	safe := s != ""
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.ContainsRune("_-.,/:@%+=", c)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// isEnvName reports whether s is a valid environment variable name.
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
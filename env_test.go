@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var envTests = []struct {
+	testName    string
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "flat",
+	val: map[string]interface{}{
+		"PORT":    8080.0,
+		"HOST":    "example.com",
+		"MESSAGE": "it's $HOME",
+		"EMPTY":   "",
+		"UNSET":   nil,
+		"DEBUG":   true,
+	},
+	expect: "" +
+		"DEBUG=true\n" +
+		"EMPTY=''\n" +
+		"HOST=example.com\n" +
+		"MESSAGE='it'\\''s $HOME'\n" +
+		"PORT=8080\n" +
+		"UNSET=''\n",
+}, {
+	testName: "flattened",
+	val: map[string]interface{}{
+		"DB": map[string]interface{}{
+			"USER":  "admin",
+			"HOSTS": []interface{}{"a", "b c"},
+		},
+	},
+	expect: "" +
+		"DB_HOSTS_0=a\n" +
+		"DB_HOSTS_1='b c'\n" +
+		"DB_USER=admin\n",
+}, {
+	testName:    "not-an-object",
+	val:         "x",
+	expectError: `env output requires an object`,
+}, {
+	testName:    "invalid-name",
+	val:         map[string]interface{}{"a": map[string]interface{}{"b-c": 1.0}},
+	expectError: `cannot use "a_b-c" as an environment variable name`,
+}}
+
+func TestEnvEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range envTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newEnvEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// lookupEnv returns the value of the named environment
+// variable, given at the argument with the given index.
+func lookupEnv(name string, index int) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q at argument %d is not set", name, index)
+	}
+	return v, nil
+}
+
+// expandEnv reads the variable name that follows an env
+// argument at the given index that's used as the argument
+// of another keyword, and returns the variable's value.
+func (p *parser) expandEnv(index int) (string, error) {
+	if p.hermetic {
+		return "", fmt.Errorf("env at argument %d is not allowed because it performs I/O or is nondeterministic", index)
+	}
+	name, err := p.mustNext("environment variable name")
+	if err != nil {
+		return "", err
+	}
+	return lookupEnv(name, p.index-1)
+}
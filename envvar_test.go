@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var envVarTests = []struct {
+	testName    string
+	args        string
+	hermetic    bool
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "value",
+	args:     "home: env JSON_TEST_HOME",
+	expect:   []interface{}{map[string]interface{}{"home": "/home/x y"}},
+}, {
+	testName: "number-is-string",
+	args:     "env JSON_TEST_PORT",
+	expect:   []interface{}{"8080"},
+}, {
+	testName: "keyword-argument",
+	args:     "port: num env JSON_TEST_PORT",
+	expect:   []interface{}{map[string]interface{}{"port": json.Number("8080")}},
+}, {
+	testName: "empty",
+	args:     "env JSON_TEST_EMPTY",
+	expect:   []interface{}{""},
+}, {
+	testName:    "unset",
+	args:        "a: env JSON_TEST_UNSET",
+	expectError: `environment variable "JSON_TEST_UNSET" at argument 2 is not set`,
+}, {
+	testName:    "keyword-argument-unset",
+	args:        "num env JSON_TEST_UNSET",
+	expectError: `environment variable "JSON_TEST_UNSET" at argument 2 is not set`,
+}, {
+	testName:    "missing-name",
+	args:        "num env",
+	expectError: `unexpected end of arguments \(expected environment variable name\)`,
+}, {
+	testName:    "hermetic",
+	args:        "env JSON_TEST_PORT",
+	hermetic:    true,
+	expectError: `env at argument 0 is not allowed because it performs I/O or is nondeterministic`,
+}, {
+	testName:    "hermetic-keyword-argument",
+	args:        "num env JSON_TEST_PORT",
+	hermetic:    true,
+	expectError: `env at argument 1 is not allowed because it performs I/O or is nondeterministic`,
+}}
+
+func TestEnvVar(t *testing.T) {
+	c := qt.New(t)
+	c.Setenv("JSON_TEST_HOME", "/home/x y")
+	c.Setenv("JSON_TEST_PORT", "8080")
+	c.Setenv("JSON_TEST_EMPTY", "")
+	for _, test := range envVarTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.hermetic = test.hermetic
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var simpleEscapes = map[byte]string{
+	'0':  "\x00",
+	'a':  "\a",
+	'b':  "\b",
+	'f':  "\f",
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'v':  "\v",
+	'\\': "\\",
+	'"':  "\"",
+	'\'': "'",
+	'/':  "/",
+}
+
+// expandEscapes returns s with its backslash escapes interpreted.
+// As well as the usual single-character escapes, \xHH, \uHHHH
+// (including UTF-16 surrogate pairs) and \UHHHHHHHH stand for
+// the Unicode code point with the given hexadecimal value.
+func expandEscapes(s string) (string, error) {
+	var buf strings.Builder
+	for {
+		i := strings.IndexByte(s, '\\')
+		if i < 0 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		buf.WriteString(s[:i])
+		s = s[i:]
+		if len(s) < 2 {
+			return "", fmt.Errorf("trailing backslash")
+		}
+		if e, ok := simpleEscapes[s[1]]; ok {
+			buf.WriteString(e)
+			s = s[2:]
+			continue
+		}
+		var n int
+		switch s[1] {
+		case 'x':
+			n = 2
+		case 'u':
+			n = 4
+		case 'U':
+			n = 8
+		default:
+			r, _ := utf8.DecodeRuneInString(s[1:])
+			return "", fmt.Errorf("unknown escape sequence \\%c", r)
+		}
+		r, err := hexRune(s[:2], s[2:], n)
+		if err != nil {
+			return "", err
+		}
+		s = s[2+n:]
+		if utf16.IsSurrogate(r) {
+			if !strings.HasPrefix(s, `\u`) {
+				return "", fmt.Errorf("unpaired surrogate \\u%04X", r)
+			}
+			r2, err := hexRune(`\u`, s[2:], 4)
+			if err != nil {
+				return "", err
+			}
+			if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+				return "", fmt.Errorf("invalid surrogate pair \\u%04X", r2)
+			}
+			s = s[6:]
+		}
+		if !utf8.ValidRune(r) {
+			return "", fmt.Errorf("invalid code point U+%X", r)
+		}
+		buf.WriteRune(r)
+	}
+}
+
+// hexRune returns the code point held in the n hexadecimal
+// digits at the start of s, which follow the given escape.
+func hexRune(escape, s string, n int) (rune, error) {
+	if len(s) < n {
+		return 0, fmt.Errorf("%s escape needs %d hexadecimal digits", escape, n)
+	}
+	v, err := strconv.ParseUint(s[:n], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s escape needs %d hexadecimal digits", escape, n)
+	}
+	return rune(v), nil
+}
+
+func evalEsc(_ []string, index int, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("esc value at argument %d is %s, not string", index, valueKind(v))
+	}
+	s, err := expandEscapes(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid escape in %q at argument %d: %v", v, index, err)
+	}
+	return s, nil
+}
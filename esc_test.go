@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var expandEscapesTests = []struct {
+	testName    string
+	s           string
+	expect      string
+	expectError string
+}{{
+	testName: "none",
+	s:        "plain text",
+	expect:   "plain text",
+}, {
+	testName: "simple",
+	s:        `a\nb\tc\\d\"e\'f\/g\0`,
+	expect:   "a\nb\tc\\d\"e'f/g\x00",
+}, {
+	testName: "unicode",
+	s:        `caf\u00e9 \x41 \U0001F600`,
+	expect:   "café A \U0001F600",
+}, {
+	testName: "surrogate-pair",
+	s:        `\uD83D\uDE00!`,
+	expect:   "\U0001F600!",
+}, {
+	testName:    "unpaired-surrogate",
+	s:           `\uD83Dx`,
+	expectError: `unpaired surrogate \\uD83D`,
+}, {
+	testName:    "invalid-surrogate-pair",
+	s:           `\uD83D\u0041`,
+	expectError: `invalid surrogate pair \\u0041`,
+}, {
+	testName:    "short-hex",
+	s:           `\u12`,
+	expectError: `\\u escape needs 4 hexadecimal digits`,
+}, {
+	testName:    "bad-hex",
+	s:           `\xzz`,
+	expectError: `\\x escape needs 2 hexadecimal digits`,
+}, {
+	testName:    "out-of-range",
+	s:           `\U00110000`,
+	expectError: `invalid code point U\+110000`,
+}, {
+	testName:    "unknown",
+	s:           `\é`,
+	expectError: `unknown escape sequence \\é`,
+}, {
+	testName:    "trailing-backslash",
+	s:           `a\`,
+	expectError: `trailing backslash`,
+}}
+
+func TestExpandEscapes(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range expandEscapesTests {
+		c.Run(test.testName, func(c *qt.C) {
+			s, err := expandEscapes(test.s)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(s, qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestEsc(t *testing.T) {
+	c := qt.New(t)
+	vals, err := newParser([]string{"a:", "esc", `x\ny`}).run()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(vals, qt.DeepEquals, []interface{}{map[string]interface{}{"a": "x\ny"}})
+
+	_, err = newParser([]string{"esc", `\q`}).run()
+	c.Assert(err, qt.ErrorMatches, `invalid escape in "\\\\q" at argument 1: unknown escape sequence \\q`)
+
+	_, err = newParser([]string{"esc", "1"}).run()
+	c.Assert(err, qt.ErrorMatches, `esc value at argument 1 is number, not string`)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	mathrand "math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// valueRand is the source of randomness for generated values,
+// such as fake data and random choices.
+var valueRand = mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+
+var (
+	fakeFirstNames = strings.Fields(`
+		Alice Amara Ben Carlos Chloe Daniel Elena Fatima George Hana
+		Ivan Jamal Julia Kenji Laura Liam Maria Mohammed Nora Olivia
+		Pedro Priya Quinn Rosa Sam Sofia Tom Uma Victor Wei Yara Zoe`)
+	fakeLastNames = strings.Fields(`
+		Adams Brown Chen Costa Davies Dubois Evans Garcia Hansen Ito
+		Jones Kim Kowalski Lopez Martin Meyer Nguyen Novak Okafor Patel
+		Rossi Schmidt Silva Smith Tanaka Taylor Walker Williams Yilmaz`)
+	fakeStreets = strings.Fields(`
+		Acacia Birch Cedar Elm Highfield Hill Lake Maple Meadow Mill
+		Oak Park Pine River Station Victoria Willow`)
+	fakeStreetTypes = strings.Fields(`Avenue Close Drive Lane Road Street Way`)
+	fakeCities      = strings.Fields(`
+		Amsterdam Berlin Boston Cairo Denver Dublin Glasgow Lagos Lima
+		Lisbon London Madrid Melbourne Mumbai Nairobi Osaka Oslo Paris
+		Seattle Seoul Sydney Toronto Vienna`)
+	fakeCompanySuffixes = strings.Fields(`Corp Group Holdings Industries Labs Ltd Systems`)
+	fakeDomains         = strings.Fields(`example.com example.net example.org`)
+	fakeCountries       = func() []string {
+		codes := make([]string, 0, len(isoCountries))
+		for code := range isoCountries {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return codes
+	}()
+	fakeWords = strings.Fields(`
+		alpha amber anchor apple arrow basket beacon bright bridge cable
+		candle canyon cloud copper coral crystal delta desert echo ember
+		falcon feather field forest garden glass harbor hollow island
+		jasmine lantern lemon marble meadow mirror morning orbit paper
+		pebble planet quiet river silver simple spark stone summer thunder
+		timber velvet violet winter yellow`)
+)
+
+// fakeKinds holds the generators for each kind of fake data.
+var fakeKinds = map[string]func(r *mathrand.Rand) string{
+	"firstname": func(r *mathrand.Rand) string {
+		return pick(r, fakeFirstNames)
+	},
+	"lastname": func(r *mathrand.Rand) string {
+		return pick(r, fakeLastNames)
+	},
+	"name": func(r *mathrand.Rand) string {
+		return pick(r, fakeFirstNames) + " " + pick(r, fakeLastNames)
+	},
+	"username": func(r *mathrand.Rand) string {
+		return strings.ToLower(pick(r, fakeFirstNames)) + fmt.Sprint(r.Intn(1000))
+	},
+	"email": func(r *mathrand.Rand) string {
+		return strings.ToLower(pick(r, fakeFirstNames)+"."+pick(r, fakeLastNames)) + "@" + pick(r, fakeDomains)
+	},
+	"company": func(r *mathrand.Rand) string {
+		return pick(r, fakeLastNames) + " " + pick(r, fakeCompanySuffixes)
+	},
+	"address": func(r *mathrand.Rand) string {
+		return fmt.Sprintf("%d %s %s, %s", 1+r.Intn(200), pick(r, fakeStreets), pick(r, fakeStreetTypes), pick(r, fakeCities))
+	},
+	"city": func(r *mathrand.Rand) string {
+		return pick(r, fakeCities)
+	},
+	"country": func(r *mathrand.Rand) string {
+		return pick(r, fakeCountries)
+	},
+	"word": func(r *mathrand.Rand) string {
+		return pick(r, fakeWords)
+	},
+	"sentence": func(r *mathrand.Rand) string {
+		words := make([]string, 4+r.Intn(8))
+		for i := range words {
+			words[i] = pick(r, fakeWords)
+		}
+		s := strings.Join(words, " ")
+		return strings.ToUpper(s[:1]) + s[1:] + "."
+	},
+	"ipv4": func(r *mathrand.Rand) string {
+		return fmt.Sprintf("%d.%d.%d.%d", 1+r.Intn(223), r.Intn(256), r.Intn(256), 1+r.Intn(254))
+	},
+	"ipv6": func(r *mathrand.Rand) string {
+		ip := make(net.IP, net.IPv6len)
+		// Use the documentation prefix 2001:db8::/32.
+		copy(ip, []byte{0x20, 0x01, 0x0d, 0xb8})
+		r.Read(ip[4:])
+		return ip.String()
+	},
+	"url": func(r *mathrand.Rand) string {
+		return "https://www." + pick(r, fakeDomains) + "/" + pick(r, fakeWords)
+	},
+}
+
+func pick(r *mathrand.Rand, choices []string) string {
+	return choices[r.Intn(len(choices))]
+}
+
+func evalFake(args []string, index int, _ interface{}) (interface{}, error) {
+	gen := fakeKinds[args[0]]
+	if gen == nil {
+		kinds := make([]string, 0, len(fakeKinds))
+		for kind := range fakeKinds {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		return nil, fmt.Errorf("unknown fake data kind %q at argument %d; valid kinds are %s", args[0], index, strings.Join(kinds, ", "))
+	}
+	return gen(valueRand), nil
+}
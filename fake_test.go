@@ -0,0 +1,74 @@
+package main
+
+import (
+	mathrand "math/rand"
+	"net"
+	"regexp"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var fakePatterns = map[string]string{
+	"firstname": `[A-Z][a-z]+`,
+	"lastname":  `[A-Z][a-z]+`,
+	"name":      `[A-Z][a-z]+ [A-Z][a-z]+`,
+	"username":  `[a-z]+[0-9]+`,
+	"email":     `[a-z]+\.[a-z]+@example\.(com|net|org)`,
+	"company":   `[A-Z][a-z]+ [A-Z][a-z]+`,
+	"address":   `[0-9]+ [A-Z][a-z]+ [A-Z][a-z]+, [A-Z][a-z]+`,
+	"city":      `[A-Z][a-z]+`,
+	"country":   `[A-Z]{2}`,
+	"word":      `[a-z]+`,
+	"sentence":  `[A-Z][a-z]+( [a-z]+)+\.`,
+	"ipv4":      `[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+`,
+	"ipv6":      `2001:db8:[0-9a-f:]+`,
+	"url":       `https://www\.example\.(com|net|org)/[a-z]+`,
+}
+
+func TestFakeKinds(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(len(fakePatterns), qt.Equals, len(fakeKinds))
+	for kind, pattern := range fakePatterns {
+		c.Run(kind, func(c *qt.C) {
+			for i := 0; i < 20; i++ {
+				vals, err := newParser([]string{"fake", kind}).run()
+				c.Assert(err, qt.Equals, nil)
+				s := vals[0].(string)
+				c.Assert(regexp.MustCompile("^"+pattern+"$").MatchString(s), qt.Equals, true, qt.Commentf("%q", s))
+				if kind == "ipv4" || kind == "ipv6" {
+					c.Assert(net.ParseIP(s), qt.Not(qt.IsNil))
+				}
+				if kind == "country" {
+					c.Assert(isoCountries[s], qt.Equals, true)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeSeeded(t *testing.T) {
+	c := qt.New(t)
+	defer func(oldIDs *idSource, oldRand *mathrand.Rand) {
+		ids, valueRand = oldIDs, oldRand
+	}(ids, valueRand)
+	args := []string{"name:", "fake", "name", "email:", "fake", "email", "ip:", "fake", "ipv6"}
+	run := func() []interface{} {
+		err := setSeed("7")
+		c.Assert(err, qt.Equals, nil)
+		vals, err := newParser(args).run()
+		c.Assert(err, qt.Equals, nil)
+		return vals
+	}
+	c.Assert(run(), qt.DeepEquals, run())
+}
+
+func TestFakeErrors(t *testing.T) {
+	c := qt.New(t)
+	_, err := newParser([]string{"fake", "nmae"}).run()
+	c.Assert(err, qt.ErrorMatches, `unknown fake data kind "nmae" at argument 1; valid kinds are address, city, .*, word`)
+	p := newParser([]string{"fake", "name"})
+	p.hermetic = true
+	_, err = p.run()
+	c.Assert(err, qt.ErrorMatches, `fake at argument 0 is not allowed because it performs I/O or is nondeterministic`)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var httpTimeout = flag.Duration("http-timeout", 30*time.Second, "timeout for requests made by the http and fetch keywords")
+
+// fetchURL makes a GET request to the URL held in the argument
+// at the given index and returns the response body. The request
+// is abandoned if the context is canceled.
+func fetchURL(ctx context.Context, url string, index int) (string, error) {
+	return memoize("http", url, func() (string, error) {
+		return httpGet(ctx, url, index)
+	})
+}
+
+// httpGet makes the request for fetchURL.
+func httpGet(ctx context.Context, url string, index int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch URL at argument %d: %v", index, err)
+	}
+	client := &http.Client{
+		Timeout: *httpTimeout,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch URL at argument %d: %v", index, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch URL at argument %d: %v", index, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("cannot fetch %q at argument %d: %s", url, index, resp.Status)
+	}
+	return string(data), nil
+}
+
+// fetchValue implements the http and fetch keywords at the
+// given argument index.
+func (p *parser) fetchValue(start int) (interface{}, error) {
+	url, err := p.mustNext("URL")
+	if err != nil {
+		return nil, err
+	}
+	index := p.index - 1
+	body, err := fetchURL(p.context(), url, index)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal json from %q at argument %d: %v", url, index, err)
+	}
+	return x, nil
+}
+
+// expandFetch reads the URL that follows an http or fetch
+// argument at the given index that's used as the argument
+// of another keyword, and returns the response body.
+func (p *parser) expandFetch(a string, index int) (string, error) {
+	if p.hermetic {
+		return "", fmt.Errorf("%s at argument %d is not allowed because it performs I/O or is nondeterministic", a, index)
+	}
+	url, err := p.mustNext("URL")
+	if err != nil {
+		return "", err
+	}
+	return fetchURL(p.context(), url, p.index-1)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var fetchTests = []struct {
+	testName    string
+	args        []string
+	hermetic    bool
+	canceled    bool
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "json",
+	args:     []string{"user:", "http", "/user"},
+	expect: []interface{}{map[string]interface{}{
+		"user": map[string]interface{}{"id": json.Number("1")},
+	}},
+}, {
+	testName: "keyword-argument",
+	args:     []string{"yaml", "http", "/text"},
+	expect:   []interface{}{"hello"},
+}, {
+	testName:    "not-json",
+	args:        []string{"http", "/text"},
+	expectError: `cannot unmarshal json from ".*/text" at argument 1: invalid character 'h' looking for beginning of value`,
+}, {
+	testName:    "not-found",
+	args:        []string{"http", "/missing"},
+	expectError: `cannot fetch ".*/missing" at argument 1: 404 Not Found`,
+}, {
+	testName:    "timeout",
+	args:        []string{"http", "/slow"},
+	expectError: `cannot fetch URL at argument 1: .*Timeout.*`,
+}, {
+	testName:    "canceled",
+	args:        []string{"http", "/slow"},
+	canceled:    true,
+	expectError: `cannot fetch URL at argument 1: .*context canceled`,
+}, {
+	testName:    "hermetic",
+	args:        []string{"yaml", "fetch", "/text"},
+	hermetic:    true,
+	expectError: `fetch at argument 1 is not allowed because it performs I/O or is nondeterministic`,
+}}
+
+func TestFetch(t *testing.T) {
+	c := qt.New(t)
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/user":
+			fmt.Fprintf(w, `{"id": 1}`)
+		case "/text":
+			fmt.Fprintf(w, "hello\n")
+		case "/slow":
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+	defer func(timeout time.Duration) {
+		*httpTimeout = timeout
+	}(*httpTimeout)
+	*httpTimeout = 100 * time.Millisecond
+	for _, test := range fetchTests {
+		c.Run(test.testName, func(c *qt.C) {
+			args := make([]string, len(test.args))
+			for i, a := range test.args {
+				if a[0] == '/' {
+					a = srv.URL + a
+				}
+				args[i] = a
+			}
+			p := newParser(args)
+			p.hermetic = test.hermetic
+			if test.canceled {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				p.ctx = ctx
+			}
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// floatFormatPattern matches the formats allowed
+// by the float keyword.
+var floatFormatPattern = regexp.MustCompile(`^%[-+ #0]*[0-9]*(\.[0-9]*)?[eEfFgG]$`)
+
+// floatArg parses the arguments following a float keyword at the
+// given index: an optional format starting with %, followed by
+// the number.
+func (p *parser) floatArg(start int) (interface{}, error) {
+	arg, err := p.mustNext("numeric value or format")
+	if err != nil {
+		return nil, err
+	}
+	format := ""
+	if isFloatFormat(arg) {
+		format = arg
+		if !floatFormatPattern.MatchString(format) {
+			return nil, fmt.Errorf("invalid float format %q at argument %d", format, p.index-1)
+		}
+		if arg, err = p.mustNext("numeric value"); err != nil {
+			return nil, err
+		}
+	}
+	index := p.index - 1
+	if arg, err = p.expandArg(arg, index); err != nil {
+		return nil, err
+	}
+	return formatFloat(format, arg, index)
+}
+
+// isFloatFormat reports whether the argument following a
+// float keyword is a format rather than the number.
+func isFloatFormat(arg string) bool {
+	return len(arg) > 1 && arg[0] == '%'
+}
+
+// formatFloat returns the number in a, which is at the given
+// argument index, formatted with the given format, or in the
+// shortest form that represents it exactly if format is empty.
+func formatFloat(format, a string, index int) (json.Number, error) {
+	f, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %q at argument %d", a, index)
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("%q is not a regular floating point number and cannot be encoded to JSON", a)
+	}
+	if format == "" {
+		return floatNumber(f)
+	}
+	s := fmt.Sprintf(format, f)
+	if !isJSONNumber(s) {
+		return "", fmt.Errorf("float format %q produces %q for argument %d, which is not a valid JSON number", format, s, index)
+	}
+	return json.Number(s), nil
+}
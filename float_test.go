@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var floatTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "shortest",
+	args:     "float 1.50",
+	expect:   json.Number("1.5"),
+}, {
+	testName: "integer",
+	args:     "float 3",
+	expect:   json.Number("3"),
+}, {
+	testName: "fixed",
+	args:     "float %.2f 3.14159",
+	expect:   json.Number("3.14"),
+}, {
+	testName: "exponent",
+	args:     "float %.3e 12345",
+	expect:   json.Number("1.234e+04"),
+}, {
+	testName: "general",
+	args:     "float %g 0.000001",
+	expect:   json.Number("1e-06"),
+}, {
+	testName: "in-object",
+	args:     "a: float %.1f 2 b: 1",
+	expect:   map[string]interface{}{"a": json.Number("2.0"), "b": 1.0},
+}, {
+	testName:    "invalid-number",
+	args:        "float %.2f x",
+	expectError: `invalid number "x" at argument 2`,
+}, {
+	testName:    "infinity",
+	args:        "float Inf",
+	expectError: `"Inf" is not a regular floating point number and cannot be encoded to JSON`,
+}, {
+	testName:    "invalid-format",
+	args:        "float %d 3",
+	expectError: `invalid float format "%d" at argument 1`,
+}, {
+	testName:    "format-not-json",
+	args:        "float %+.1f 3",
+	expectError: `float format "%\+.1f" produces "\+3.0" for argument 2, which is not a valid JSON number`,
+}, {
+	testName:    "missing-number",
+	args:        "float %.2f",
+	expectError: `unexpected end of arguments \(expected numeric value\)`,
+}}
+
+func TestFloat(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range floatTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An encoder writes a sequence of values in some output format.
+type encoder interface {
+	Encode(v interface{}) error
+}
+
+// encoderCloser is implemented by encoders that write
+// output only after all the values have been encoded.
+type encoderCloser interface {
+	encoder
+	Close() error
+}
+
+// formats holds the available output formats, keyed
+// by the name used with the -format flag.
+var formats = map[string]func(w io.Writer) encoder{
+	"avro":      newAvroEncoder,
+	"avro-json": newAvroJSONEncoder,
+	"bson":      newBSONEncoder,
+	"cbor":      newCBOREncoder,
+	"cbor-diag": newCBORDiagEncoder,
+	"csv":       newCSVEncoder,
+	"edn":       newEDNEncoder,
+	"env":       newEnvEncoder,
+	"go":        newGoEncoder,
+	"hcl":       newHCLEncoder,
+	"json":      newJSONEncoder,
+	"json5":     newJSON5Encoder,
+	"jsonc":     newJSONCEncoder,
+	"msgpack":   newMsgpackEncoder,
+	"protobuf":  newProtobufEncoder,
+	"query":     newQueryEncoder,
+	"schema":    newSchemaEncoder,
+	"smile":     newSmileEncoder,
+	"tsv":       newTSVEncoder,
+	"xml":       newXMLEncoder,
+	"yaml":      newYAMLEncoder,
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func newJSONEncoder(w io.Writer) encoder {
+	if *compactArrays {
+		return &compactArraysEncoder{w: w}
+	}
+	enc := json.NewEncoder(w)
+	if *indent {
+		enc.SetIndent("", "\t")
+	}
+	return enc
+}
+
+// sortedKeys returns the keys of m in sorted order,
+// so that binary encodings are deterministic in the
+// same way as the JSON output.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// intValue reports whether the number n (a float64 or a json.Number)
+// holds an integer that fits in an int64, and returns it if so.
+// Formats that distinguish integers from floating point
+// use this to avoid encoding whole numbers as floats.
+func intValue(n interface{}) (int64, bool) {
+	switch n := n.(type) {
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	case json.Number:
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			return i, true
+		}
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return 0, false
+		}
+		return intValue(f)
+	}
+	return 0, false
+}
+
+// floatValue returns the value of the number n (a float64 or a json.Number)
+// as a float64.
+func floatValue(n interface{}) float64 {
+	switch n := n.(type) {
+	case float64:
+		return n
+	case json.Number:
+		f, _ := strconv.ParseFloat(string(n), 64)
+		return f
+	}
+	return math.NaN()
+}
+
+// floatNumber returns f as a json.Number, or an
+// error if it cannot be represented in JSON.
+func floatNumber(f float64) (json.Number, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("%v cannot be represented in JSON", f)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+// valueKind returns the name of the JSON type of v.
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// scalarText returns the text used to represent a non-composite
+// value in textual formats that have no types of their own.
+// Null is represented as the empty string.
+func scalarText(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64, json.Number:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+	return "", fmt.Errorf("cannot encode %T as text", v)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// parseCoord parses the coordinate at the given argument index,
+// which must be within [-max, max]. The what argument
+// names the coordinate, for example "latitude".
+func parseCoord(s, what string, max float64, index int) (float64, error) {
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+		return 0, fmt.Errorf("invalid %s %q at argument %d", what, s, index)
+	}
+	if x < -max || x > max {
+		return 0, fmt.Errorf("%s %v at argument %d is out of range [%v, %v]", what, x, index, -max, max)
+	}
+	return x, nil
+}
+
+// parseLatLon parses a latitude and a longitude at
+// the given argument index.
+func parseLatLon(lat, lon string, index int) (float64, float64, error) {
+	y, err := parseCoord(lat, "latitude", 90, index)
+	if err != nil {
+		return 0, 0, err
+	}
+	x, err := parseCoord(lon, "longitude", 180, index+1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return y, x, nil
+}
+
+func evalLatLon(args []string, index int, _ interface{}) (interface{}, error) {
+	lat, lon, err := parseLatLon(args[0], args[1], index)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"lat": lat,
+		"lon": lon,
+	}, nil
+}
+
+// evalGeoPoint returns a GeoJSON Point geometry. Note that
+// GeoJSON positions hold the longitude first.
+func evalGeoPoint(args []string, index int, _ interface{}) (interface{}, error) {
+	lat, lon, err := parseLatLon(args[0], args[1], index)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{lon, lat},
+	}, nil
+}
+
+// evalGeoBBox returns a GeoJSON Polygon geometry covering the
+// bounding box given by its west, south, east and north edges,
+// the same order as a GeoJSON bbox member. A box whose west
+// edge is east of its east edge crosses the antimeridian.
+func evalGeoBBox(args []string, index int, _ interface{}) (interface{}, error) {
+	var edges [4]float64
+	for i, arg := range args {
+		what, max := "longitude", 180.0
+		if i%2 == 1 {
+			what, max = "latitude", 90.0
+		}
+		x, err := parseCoord(arg, what, max, index+i)
+		if err != nil {
+			return nil, err
+		}
+		edges[i] = x
+	}
+	w, s, e, n := edges[0], edges[1], edges[2], edges[3]
+	if s > n {
+		return nil, fmt.Errorf("south latitude %v at argument %d is north of north latitude %v", s, index+1, n)
+	}
+	pos := func(x, y float64) interface{} {
+		return []interface{}{x, y}
+	}
+	return map[string]interface{}{
+		"type": "Polygon",
+		"bbox": []interface{}{w, s, e, n},
+		"coordinates": []interface{}{
+			[]interface{}{pos(w, s), pos(e, s), pos(e, n), pos(w, n), pos(w, s)},
+		},
+	}, nil
+}
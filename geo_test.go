@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var geoTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "latlon",
+	args:     "latlon 51.5 -0.12",
+	expect: map[string]interface{}{
+		"lat": 51.5,
+		"lon": -0.12,
+	},
+}, {
+	testName:    "latlon-out-of-range",
+	args:        "latlon 91 0",
+	expectError: `latitude 91 at argument 1 is out of range \[-90, 90\]`,
+}, {
+	testName:    "latlon-invalid-longitude",
+	args:        "latlon 0 east",
+	expectError: `invalid longitude "east" at argument 2`,
+}, {
+	testName:    "latlon-nan",
+	args:        "latlon NaN 0",
+	expectError: `invalid latitude "NaN" at argument 1`,
+}, {
+	testName: "geopoint",
+	args:     "geopoint 51.5 -0.12",
+	expect: map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{-0.12, 51.5},
+	},
+}, {
+	testName:    "geopoint-longitude-out-of-range",
+	args:        "geopoint 0 180.5",
+	expectError: `longitude 180.5 at argument 2 is out of range \[-180, 180\]`,
+}, {
+	testName: "geobbox",
+	args:     "geobbox -1 50 1 52",
+	expect: map[string]interface{}{
+		"type": "Polygon",
+		"bbox": []interface{}{-1.0, 50.0, 1.0, 52.0},
+		"coordinates": []interface{}{
+			[]interface{}{
+				[]interface{}{-1.0, 50.0},
+				[]interface{}{1.0, 50.0},
+				[]interface{}{1.0, 52.0},
+				[]interface{}{-1.0, 52.0},
+				[]interface{}{-1.0, 50.0},
+			},
+		},
+	},
+}, {
+	testName:    "geobbox-south-of-north",
+	args:        "geobbox -1 52 1 50",
+	expectError: `south latitude 52 at argument 2 is north of north latitude 50`,
+}, {
+	testName:    "geobbox-invalid-north",
+	args:        "geobbox -1 50 1 99",
+	expectError: `latitude 99 at argument 4 is out of range \[-90, 90\]`,
+}}
+
+func TestGeo(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range geoTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
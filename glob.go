@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// evalGlob returns the names of the files that match the
+// pattern, in sorted order, using the syntax of filepath.Match.
+func evalGlob(_ []string, index int, v interface{}) (interface{}, error) {
+	pattern, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("glob value at argument %d is %s, not string", index, valueKind(v))
+	}
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q at argument %d: %v", pattern, index, err)
+	}
+	elems := make([]interface{}, len(names))
+	for i, name := range names {
+		elems[i] = name
+	}
+	return elems, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var globTests = []struct {
+	testName    string
+	args        string
+	hermetic    bool
+	expect      interface{}
+	expectError string
+}{{
+	testName: "match",
+	args:     "glob *.txt",
+	expect:   []interface{}{"a.txt", "b.txt"},
+}, {
+	testName: "subdirectory",
+	args:     "glob sub/?.json",
+	expect:   []interface{}{filepath.Join("sub", "c.json")},
+}, {
+	testName: "no-match",
+	args:     "glob *.yaml",
+	expect:   []interface{}{},
+}, {
+	testName: "literal",
+	args:     "glob a.txt",
+	expect:   []interface{}{"a.txt"},
+}, {
+	testName:    "bad-pattern",
+	args:        "glob a[",
+	expectError: `invalid glob pattern "a\[" at argument 1: syntax error in pattern`,
+}, {
+	testName:    "not-string",
+	args:        "glob 1",
+	expectError: `glob value at argument 1 is number, not string`,
+}, {
+	testName:    "hermetic",
+	args:        "glob *.txt",
+	hermetic:    true,
+	expectError: `glob at argument 0 is not allowed because it performs I/O or is nondeterministic`,
+}}
+
+func TestGlob(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	err := os.Mkdir(filepath.Join(dir, "sub"), 0777)
+	c.Assert(err, qt.Equals, nil)
+	for _, name := range []string{"b.txt", "a.txt", "sub/c.json"} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0666)
+		c.Assert(err, qt.Equals, nil)
+	}
+	wd, err := os.Getwd()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(os.Chdir(dir), qt.Equals, nil)
+	defer os.Chdir(wd)
+	for _, test := range globTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.hermetic = test.hermetic
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
@@ -3,6 +3,9 @@ module github.com/rogpeppe/json
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/frankban/quicktest v1.5.0
-	github.com/google/go-cmp v0.3.1
+	github.com/google/go-cmp v0.5.0
+	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v2 v2.2.8
 )
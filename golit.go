@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	goformat "go/format"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// goEncoder encodes values as gofmt-formatted Go expressions with the
+// same dynamic types as produced by unmarshaling JSON into an
+// interface{} value: map[string]interface{}, []interface{}, float64
+// and so on. Numbers from num and json assertions are
+// encoded as json.Number.
+type goEncoder struct {
+	w io.Writer
+}
+
+func newGoEncoder(w io.Writer) encoder {
+	return &goEncoder{w: w}
+}
+
+func (e *goEncoder) Encode(v interface{}) error {
+	var buf bytes.Buffer
+	if err := writeGoValue(&buf, v); err != nil {
+		return err
+	}
+	src, err := goformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("cannot format Go literal: %v", err)
+	}
+	_, err = fmt.Fprintf(e.w, "%s\n", src)
+	return err
+}
+
+func writeGoValue(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("nil")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		buf.WriteString(strconv.Quote(v))
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			// Ensure that the constant is a floating point constant
+			// so that it has type float64 in an interface{}.
+			s += ".0"
+		}
+		buf.WriteString(s)
+	case json.Number:
+		fmt.Fprintf(buf, "json.Number(%q)", v)
+	case []interface{}:
+		// Put each element on its own line if any
+		// of them is composite.
+		sep := ""
+		for _, elem := range v {
+			if isComposite(elem) {
+				sep = "\n"
+			}
+		}
+		buf.WriteString("[]interface{}{")
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(sep)
+			if err := writeGoValue(buf, elem); err != nil {
+				return err
+			}
+		}
+		if sep != "" {
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("}")
+	case map[string]interface{}:
+		buf.WriteString("map[string]interface{}{")
+		for _, k := range sortedKeys(v) {
+			fmt.Fprintf(buf, "\n%s: ", strconv.Quote(k))
+			if err := writeGoValue(buf, v[k]); err != nil {
+				return err
+			}
+			buf.WriteString(",")
+		}
+		if len(v) > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("}")
+	default:
+		return fmt.Errorf("cannot encode %T as a Go literal", v)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var goTests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "scalars",
+	val:      []interface{}{nil, true, "a\"b", 1.0, 1e100, 0.5, json.Number("12")},
+	expect:   `[]interface{}{nil, true, "a\"b", 1.0, 1e+100, 0.5, json.Number("12")}` + "\n",
+}, {
+	testName: "nested",
+	val: map[string]interface{}{
+		"b":      []interface{}{"x", map[string]interface{}{}},
+		"a_long": 1.0,
+		"c":      []interface{}{},
+	},
+	expect: `map[string]interface{}{
+	"a_long": 1.0,
+	"b": []interface{}{
+		"x",
+		map[string]interface{}{},
+	},
+	"c": []interface{}{},
+}
+`,
+}}
+
+func TestGoEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range goTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newGoEncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
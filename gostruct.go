@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	goformat "go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var goStruct = flag.Bool("gostruct", false, "instead of printing the values, print a Go type declaration, with json tags, describing all of them")
+
+func newGoStructEncoder(w io.Writer) encoder {
+	return &inferEncoder{
+		t: newInferredType(),
+		print: func(t *inferredType) error {
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "type %s ", *typeName)
+			writeGoType(&buf, t)
+			src, err := goformat.Source(buf.Bytes())
+			if err != nil {
+				return fmt.Errorf("cannot format Go type: %v", err)
+			}
+			_, err = fmt.Fprintf(w, "%s\n", src)
+			return err
+		},
+	}
+}
+
+func writeGoType(buf *bytes.Buffer, t *inferredType) {
+	if t.kinds() != 1 {
+		// Either there are no non-null values or there
+		// is more than one kind of value.
+		buf.WriteString("interface{}")
+		return
+	}
+	switch {
+	case t.array != nil:
+		buf.WriteString("[]")
+		writeGoType(buf, t.array)
+		return
+	case t.null:
+		buf.WriteString("*")
+	}
+	switch {
+	case t.bool:
+		buf.WriteString("bool")
+	case t.string:
+		buf.WriteString("string")
+	case t.number && t.integer:
+		buf.WriteString("int64")
+	case t.number:
+		buf.WriteString("float64")
+	case t.object != nil:
+		writeGoStruct(buf, t.object)
+	}
+}
+
+func writeGoStruct(buf *bytes.Buffer, o *objectType) {
+	buf.WriteString("struct {\n")
+	used := make(map[string]bool)
+	for _, k := range o.keys() {
+		name := goFieldName(k)
+		for i := 2; used[name]; i++ {
+			name = goFieldName(k) + strconv.Itoa(i)
+		}
+		used[name] = true
+		buf.WriteString(name + " ")
+		writeGoType(buf, o.fields[k])
+		tag := k
+		if o.optional(k) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(buf, " `json:%s`\n", strconv.Quote(tag))
+	}
+	buf.WriteString("}")
+}
+
+// goInitialisms holds words that are conventionally
+// written in upper case in Go identifiers.
+var goInitialisms = map[string]bool{
+	"API":  true,
+	"CPU":  true,
+	"DNS":  true,
+	"HTML": true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"SQL":  true,
+	"TCP":  true,
+	"TLS":  true,
+	"TTL":  true,
+	"UDP":  true,
+	"UID":  true,
+	"URI":  true,
+	"URL":  true,
+	"UUID": true,
+	"XML":  true,
+}
+
+// goFieldName returns an exported Go identifier derived from
+// the object key k.
+func goFieldName(k string) string {
+	words := strings.FieldsFunc(k, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var name strings.Builder
+	for _, w := range words {
+		if goInitialisms[strings.ToUpper(w)] {
+			name.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		name.WriteString(string(rs))
+	}
+	s := name.String()
+	if s == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(s)[0]) {
+		return "F" + s
+	}
+	return s
+}
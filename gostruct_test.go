@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var goStructTests = []struct {
+	testName string
+	vals     []interface{}
+	expect   string
+}{{
+	testName: "scalar",
+	vals:     []interface{}{1.0, 2.0},
+	expect:   "type Value int64\n",
+}, {
+	testName: "mixed",
+	vals:     []interface{}{1.0, "x"},
+	expect:   "type Value interface{}\n",
+}, {
+	testName: "struct",
+	vals: []interface{}{
+		map[string]interface{}{
+			"id":        1.0,
+			"user_name": "x",
+			"score":     1.5,
+			"tags":      []interface{}{"a"},
+			"parent":    nil,
+			"1st":       true,
+		},
+		map[string]interface{}{
+			"id":     2.0,
+			"parent": 3.0,
+			"ID":     "dup",
+			"score":  2.0,
+		},
+	},
+	expect: "" +
+		"type Value struct {\n" +
+		"\tF1st     bool     `json:\"1st,omitempty\"`\n" +
+		"\tID       string   `json:\"ID,omitempty\"`\n" +
+		"\tID2      int64    `json:\"id\"`\n" +
+		"\tParent   *int64   `json:\"parent\"`\n" +
+		"\tScore    float64  `json:\"score\"`\n" +
+		"\tTags     []string `json:\"tags,omitempty\"`\n" +
+		"\tUserName string   `json:\"user_name,omitempty\"`\n" +
+		"}\n",
+}}
+
+func TestGoStruct(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range goStructTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			enc := newGoStructEncoder(&buf).(encoderCloser)
+			for _, v := range test.vals {
+				c.Assert(enc.Encode(v), qt.Equals, nil)
+			}
+			c.Assert(enc.Close(), qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestGoFieldName(t *testing.T) {
+	c := qt.New(t)
+	for key, name := range map[string]string{
+		"name":        "Name",
+		"user_id":     "UserID",
+		"api-url":     "APIURL",
+		"createdAt":   "CreatedAt",
+		"2fa enabled": "F2faEnabled",
+		"--":          "Field",
+	} {
+		c.Assert(goFieldName(key), qt.Equals, name, qt.Commentf("%q", key))
+	}
+}
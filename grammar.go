@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var grammarFormat = flag.String("grammar", "", "print the argument grammar in the given format (ebnf or json) and exit")
+
+// A production is a rule of the argument grammar
+// in EBNF (as used by https://golang.org/ref/spec).
+type production struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+// grammarKeyword describes a keyword in the JSON form of the grammar.
+type grammarKeyword struct {
+	Name string `json:"name"`
+	// Args holds descriptions of the literal arguments
+	// that follow the keyword. Descriptions of optional
+	// arguments are enclosed in square brackets.
+	Args []string `json:"args"`
+	// Value holds whether the keyword is followed by a value.
+	Value bool `json:"value"`
+}
+
+// grammar describes the argument grammar. All terminals
+// represent exactly one argument. It is derived from
+// the parser's keyword table so that it is always
+// in sync with the parser.
+type grammar struct {
+	Start       string            `json:"start"`
+	Productions []production      `json:"productions"`
+	Tokens      map[string]string `json:"tokens"`
+	Keywords    []grammarKeyword  `json:"keywords"`
+}
+
+func argsGrammar() *grammar {
+	g := &grammar{
+		Start: "args",
+		Tokens: map[string]string{
+			"STR":    "any argument",
+			"KEY":    `an argument with a ":" suffix`,
+			"ATFILE": `an argument with a "@" prefix, standing for the contents of the named file`,
+		},
+	}
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	alts := make([]string, len(names))
+	for i, name := range names {
+		kw := keywords[name]
+		g.Keywords = append(g.Keywords, grammarKeyword{
+			Name:  name,
+			Args:  append([]string{}, kw.args...),
+			Value: kw.takesValue,
+		})
+		alts[i] = keywordSyntax(name, kw)
+	}
+	g.Productions = []production{
+		{"args", `{ value } | keyValues`},
+		{"value", `keyword | object | array | "-" | ATFILE | STR`},
+		{"keyword", strings.Join(alts, " | ")},
+		{"arg", `"stdin" | "env" STR | "cmd" STR | "http" STR | "fetch" STR | ATFILE | STR`},
+		{"object", `"[" keyValues "]"`},
+		{"keyValues", `{ [ "comment" STR ] key value }`},
+		{"key", `KEY | "key" STR`},
+		{"array", `".[" { value } "]"`},
+	}
+	return g
+}
+
+// keywordSyntax returns the grammar of the named keyword
+// followed by its arguments and value.
+func keywordSyntax(name string, kw *keyword) string {
+	alt := []string{fmt.Sprintf("%q", name)}
+	for _, arg := range kw.args {
+		switch {
+		case isOptionalArg(arg):
+			alt = append(alt, "[ STR ]")
+		case kw.expandsArg(arg):
+			alt = append(alt, "arg")
+		default:
+			alt = append(alt, "STR")
+		}
+	}
+	if kw.takesValue {
+		alt = append(alt, "value")
+	}
+	return strings.Join(alt, " ")
+}
+
+// isOptionalArg reports whether the keyword argument
+// with the given description is optional.
+func isOptionalArg(desc string) bool {
+	return strings.HasPrefix(desc, "[") && strings.HasSuffix(desc, "]")
+}
+
+// writeGrammar writes the grammar in the given format.
+func writeGrammar(w io.Writer, format string) error {
+	g := argsGrammar()
+	switch format {
+	case "ebnf":
+		for _, p := range g.Productions {
+			if _, err := fmt.Fprintf(w, "%s = %s .\n", p.Name, p.Expr); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		data, err := json.MarshalIndent(g, "", "\t")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+	return fmt.Errorf("unknown grammar format %q", format)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestGrammarEBNF(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	err := writeGrammar(&buf, "ebnf")
+	c.Assert(err, qt.Equals, nil)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	c.Assert(lines[0], qt.Equals, `args = { value } | keyValues .`)
+	var keywordLine, argLine string
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "keyword = "):
+			keywordLine = line
+		case strings.HasPrefix(line, "arg = "):
+			argLine = line
+		}
+	}
+	c.Assert(argLine, qt.Equals, `arg = "stdin" | "env" STR | "cmd" STR | "http" STR | "fetch" STR | ATFILE | STR .`)
+	c.Assert(keywordLine, qt.Contains, `| "jsonstr" value |`)
+	c.Assert(keywordLine, qt.Contains, `| "num" arg |`)
+	c.Assert(keywordLine, qt.Contains, `| "str" STR |`)
+	c.Assert(keywordLine, qt.Contains, `| "http" STR |`)
+	c.Assert(keywordLine, qt.Contains, `| "stdin" |`)
+	c.Assert(keywordLine, qt.Contains, `| "counter" [ STR ] |`)
+	c.Assert(keywordLine, qt.Contains, `| "float" [ STR ] arg |`)
+	c.Assert(keywordLine, qt.Contains, `| "lazy" STR |`)
+}
+
+func TestGrammarJSON(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	err := writeGrammar(&buf, "json")
+	c.Assert(err, qt.Equals, nil)
+	var g grammar
+	err = json.Unmarshal(buf.Bytes(), &g)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(g.Start, qt.Equals, "args")
+	// Every keyword known to the parser is described.
+	c.Assert(g.Keywords, qt.HasLen, len(keywords))
+	for _, kw := range g.Keywords {
+		pkw := keywords[kw.Name]
+		c.Assert(pkw, qt.Not(qt.IsNil), qt.Commentf("%s", kw.Name))
+		c.Assert(kw.Value, qt.Equals, pkw.takesValue)
+		c.Assert(kw.Args, qt.HasLen, len(pkw.args))
+	}
+}
+
+func TestGrammarUnknownFormat(t *testing.T) {
+	c := qt.New(t)
+	err := writeGrammar(&bytes.Buffer{}, "yacc")
+	c.Assert(err, qt.ErrorMatches, `unknown grammar format "yacc"`)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// hclEncoder encodes objects as HCL attributes, as used by
+// Terraform variable files. The members of the top-level object
+// become attributes; nested objects and arrays are written as
+// object and tuple expressions.
+type hclEncoder struct {
+	w io.Writer
+}
+
+func newHCLEncoder(w io.Writer) encoder {
+	return &hclEncoder{w: w}
+}
+
+func (e *hclEncoder) Encode(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("HCL output requires an object")
+	}
+	for k := range obj {
+		if !isHCLIdentifier(k) {
+			return fmt.Errorf("cannot use %q as an HCL attribute name", k)
+		}
+	}
+	var buf bytes.Buffer
+	if err := writeHCLMembers(&buf, obj, ""); err != nil {
+		return err
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// writeHCLMembers writes the members of obj, one per line
+// with the given indentation, aligning their equals signs.
+func writeHCLMembers(buf *bytes.Buffer, obj map[string]interface{}, indent string) error {
+	keys := sortedKeys(obj)
+	names := make([]string, len(keys))
+	width := 0
+	for i, k := range keys {
+		names[i] = k
+		if !isHCLIdentifier(k) {
+			names[i] = hclQuote(k)
+		}
+		if n := len(names[i]); n > width {
+			width = n
+		}
+	}
+	for i, k := range keys {
+		fmt.Fprintf(buf, "%s%-*s = ", indent, width, names[i])
+		if err := writeHCLValue(buf, obj[k], indent); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+func writeHCLValue(buf *bytes.Buffer, v interface{}, indent string) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		buf.WriteString(hclQuote(v))
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			break
+		}
+		buf.WriteString("{\n")
+		if err := writeHCLMembers(buf, v, indent+"  "); err != nil {
+			return err
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		composite := false
+		for _, elem := range v {
+			composite = composite || isComposite(elem)
+		}
+		if !composite {
+			buf.WriteString("[")
+			for i, elem := range v {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				if err := writeHCLValue(buf, elem, indent); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("]")
+			break
+		}
+		buf.WriteString("[\n")
+		for _, elem := range v {
+			buf.WriteString(indent + "  ")
+			if err := writeHCLValue(buf, elem, indent+"  "); err != nil {
+				return err
+			}
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(indent + "]")
+	default:
+		text, err := scalarText(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(text)
+	}
+	return nil
+}
+
+var hclEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	// Template sequences must be escaped to be taken literally.
+	"${", "$${",
+	"%{", "%%{",
+)
+
+func hclQuote(s string) string {
+	return `"` + hclEscaper.Replace(s) + `"`
+}
+
+// isHCLIdentifier reports whether s is a valid HCL identifier.
+func isHCLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case i > 0 && (c == '-' || '0' <= c && c <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
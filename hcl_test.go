@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var hclTests = []struct {
+	testName    string
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "attributes",
+	val: map[string]interface{}{
+		"region":         "eu-west-1",
+		"instance_count": 3.0,
+		"enabled":        true,
+		"zone":           nil,
+	},
+	expect: "" +
+		"enabled        = true\n" +
+		"instance_count = 3\n" +
+		"region         = \"eu-west-1\"\n" +
+		"zone           = null\n",
+}, {
+	testName: "nested",
+	val: map[string]interface{}{
+		"tags": map[string]interface{}{
+			"Name":  "web",
+			"a b":   "${x}\n",
+			"empty": map[string]interface{}{},
+		},
+		"ports": []interface{}{80.0, 443.0},
+		"rules": []interface{}{
+			map[string]interface{}{"port": 80.0},
+		},
+	},
+	expect: "" +
+		"ports = [80, 443]\n" +
+		"rules = [\n" +
+		"  {\n" +
+		"    port = 80\n" +
+		"  },\n" +
+		"]\n" +
+		"tags  = {\n" +
+		"  Name  = \"web\"\n" +
+		"  \"a b\" = \"$${x}\\n\"\n" +
+		"  empty = {}\n" +
+		"}\n",
+}, {
+	testName:    "not-an-object",
+	val:         []interface{}{},
+	expectError: `HCL output requires an object`,
+}, {
+	testName:    "invalid-attribute-name",
+	val:         map[string]interface{}{"a b": 1.0},
+	expectError: `cannot use "a b" as an HCL attribute name`,
+}}
+
+func TestHCLEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range hclTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newHCLEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+)
+
+var hermetic = flag.Bool("hermetic", false, "fail if any keyword performs I/O or is nondeterministic or a flag names a file whose contents would not be attested, and print an attestation of the inputs and output digests to standard error")
+
+// attestation describes a hermetic evaluation.
+type attestation struct {
+	Args    []string          `json:"args"`
+	Format  string            `json:"format"`
+	Digests map[string]string `json:"digests"`
+}
+
+// writeAttestation writes a JSON attestation that the given
+// arguments produced output in the given format with
+// the given digest.
+func writeAttestation(w io.Writer, args []string, format string, output hash.Hash) error {
+	if args == nil {
+		args = []string{}
+	}
+	argsData, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(attestation{
+		Args:   args,
+		Format: format,
+		Digests: map[string]string{
+			"args":   digest(sha256.Sum256(argsData)),
+			"output": digest(output.Sum(nil)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func digest(sum interface{}) string {
+	return fmt.Sprintf("sha256:%x", sum)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestHermeticRejectsImpureKeyword(t *testing.T) {
+	c := qt.New(t)
+	keywords["impure"] = &keyword{
+		eval: func([]string, int, interface{}) (interface{}, error) {
+			return "x", nil
+		},
+		impure: true,
+	}
+	c.Defer(func() {
+		delete(keywords, "impure")
+	})
+	p := newParser([]string{"a:", "impure"})
+	_, err := p.run()
+	c.Assert(err, qt.Equals, nil)
+
+	p = newParser([]string{"a:", "impure"})
+	p.hermetic = true
+	_, err = p.run()
+	c.Assert(err, qt.ErrorMatches, `impure at argument 1 is not allowed because it performs I/O or is nondeterministic`)
+}
+
+func TestWriteAttestation(t *testing.T) {
+	c := qt.New(t)
+	h := sha256.New()
+	h.Write([]byte("{\"a\":1}\n"))
+	var buf bytes.Buffer
+	err := writeAttestation(&buf, []string{"a:", "1"}, "json", h)
+	c.Assert(err, qt.Equals, nil)
+	var got attestation
+	err = json.Unmarshal(buf.Bytes(), &got)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(got, qt.DeepEquals, attestation{
+		Args:   []string{"a:", "1"},
+		Format: "json",
+		Digests: map[string]string{
+			"args":   fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`["a:","1"]`))),
+			"output": fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("{\"a\":1}\n"))),
+		},
+	})
+}
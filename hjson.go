@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// parseHjson parses s as an Hjson document (see https://hjson.github.io)
+// and returns the equivalent JSON value. Numbers are returned
+// as json.Number. As in Hjson, the braces around an object at the
+// top level may be omitted.
+func parseHjson(s string) (interface{}, error) {
+	p := &hjsonParser{json5Parser{s: s}}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("no value found")
+	}
+	if c := p.s[p.pos]; c != '{' && c != '[' {
+		// Try an object without braces first.
+		start := p.pos
+		if v, err := p.object(false); err == nil {
+			return v, nil
+		}
+		p.pos = start
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q after value", p.peekRune())
+	}
+	return v, nil
+}
+
+// hjsonParser parses Hjson. Quoted strings are parsed
+// in the same way as JSON5.
+type hjsonParser struct {
+	json5Parser
+}
+
+// skipSpace skips white space, including newlines, and comments.
+func (p *hjsonParser) skipSpace() error {
+	for p.pos < len(p.s) {
+		rest := p.s[p.pos:]
+		switch {
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			p.pos += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.pos += end + 4
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r':
+			p.pos++
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *hjsonParser) value() (interface{}, error) {
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch p.s[p.pos] {
+	case '{':
+		return p.object(true)
+	case '[':
+		return p.array()
+	case '"':
+		return p.string()
+	case '\'':
+		if strings.HasPrefix(p.s[p.pos:], "'''") {
+			return p.multilineString()
+		}
+		return p.string()
+	case ',', ':', ']', '}':
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return p.quotelessValue(), nil
+}
+
+// object parses the members of an object, which is
+// surrounded by braces if braces is true.
+func (p *hjsonParser) object(braces bool) (interface{}, error) {
+	if braces {
+		p.pos++
+	}
+	obj := make(map[string]interface{})
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.s) {
+			if braces {
+				return nil, p.errorf("unexpected end of input")
+			}
+			return obj, nil
+		}
+		if braces && p.s[p.pos] == '}' {
+			p.pos++
+			return obj, nil
+		}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ':' {
+			return nil, p.errorf("expected ':' after object key")
+		}
+		p.pos++
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+		if err := p.skipSeparator(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *hjsonParser) array() (interface{}, error) {
+	p.pos++
+	elems := []interface{}{}
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unexpected end of input")
+		}
+		if p.s[p.pos] == ']' {
+			p.pos++
+			return elems, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, v)
+		if err := p.skipSeparator(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// skipSeparator skips the optional comma after
+// an object member or array element.
+func (p *hjsonParser) skipSeparator() error {
+	if err := p.skipSpace(); err != nil {
+		return err
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == ',' {
+		p.pos++
+	}
+	return nil
+}
+
+// key parses an object key, which is either quoted
+// or a sequence of non-punctuation characters.
+func (p *hjsonParser) key() (string, error) {
+	if c := p.s[p.pos]; c == '"' || c == '\'' {
+		k, err := p.string()
+		if err != nil {
+			return "", err
+		}
+		return k.(string), nil
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(",:[]{} \t\r\n", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return p.s[start:p.pos], nil
+}
+
+// multilineString parses a string delimited by triple quotes, removing
+// the indentation of the opening delimiter from each line.
+func (p *hjsonParser) multilineString() (interface{}, error) {
+	indent := p.pos - (strings.LastIndexByte(p.s[:p.pos], '\n') + 1)
+	p.pos += 3
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == '\n' {
+		p.pos++
+		p.skipIndent(indent)
+	}
+	var buf strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unterminated multiline string")
+		}
+		if strings.HasPrefix(p.s[p.pos:], "'''") {
+			p.pos += 3
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		}
+		c := p.s[p.pos]
+		p.pos++
+		if c == '\r' && p.pos < len(p.s) && p.s[p.pos] == '\n' {
+			continue
+		}
+		buf.WriteByte(c)
+		if c == '\n' {
+			p.skipIndent(indent)
+		}
+	}
+}
+
+// skipIndent skips up to n white space characters.
+func (p *hjsonParser) skipIndent(n int) {
+	for ; n > 0 && p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t'); n-- {
+		p.pos++
+	}
+}
+
+var hjsonLiteralPattern = regexp.MustCompile(`^(true|false|null|-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?)[ \t]*(,|\]|}|#|//|/\*|\r?\n|$)`)
+
+// quotelessValue parses a literal or, failing that, a
+// quoteless string, which extends to the end of the line.
+func (p *hjsonParser) quotelessValue() interface{} {
+	rest := p.s[p.pos:]
+	if m := hjsonLiteralPattern.FindStringSubmatch(rest); m != nil {
+		p.pos += len(m[1])
+		switch m[1] {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return json.Number(m[1])
+	}
+	end := strings.IndexByte(rest, '\n')
+	if end < 0 {
+		end = len(rest)
+	}
+	p.pos += end
+	return strings.TrimRight(rest[:end], " \t\r")
+}
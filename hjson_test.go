@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var parseHjsonTests = []struct {
+	testName    string
+	data        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "plain-json",
+	data:     `{"a": [1, "x", true, null]}`,
+	expect: map[string]interface{}{
+		"a": []interface{}{json.Number("1"), "x", true, nil},
+	},
+}, {
+	testName: "root-braces-omitted",
+	data: `
+# comment
+name: web server
+port: 80 // comment
+enabled: true
+`,
+	expect: map[string]interface{}{
+		"name":    "web server",
+		"port":    json.Number("80"),
+		"enabled": true,
+	},
+}, {
+	testName: "quoteless-strings",
+	data: `{
+	a: 3 times
+	b: true story
+	c: hello, world
+	d: "quoted" 
+	'e f': 'single'
+}`,
+	expect: map[string]interface{}{
+		"a":   "3 times",
+		"b":   "true story",
+		"c":   "hello, world",
+		"d":   "quoted",
+		"e f": "single",
+	},
+}, {
+	testName: "arrays",
+	data: `[
+	1, 2
+	x
+	/* c */ null,
+]`,
+	expect: []interface{}{json.Number("1"), json.Number("2"), "x", nil},
+}, {
+	testName: "multiline-string",
+	data: `{
+	text:
+		'''
+		first
+		  second
+		'''
+}`,
+	expect: map[string]interface{}{
+		"text": "first\n  second",
+	},
+}, {
+	testName: "scalar-root",
+	data:     `hello world`,
+	expect:   "hello world",
+}, {
+	testName: "number-root",
+	data:     `12.5`,
+	expect:   json.Number("12.5"),
+}, {
+	testName:    "empty",
+	data:        "# nothing\n",
+	expectError: `offset 10: no value found`,
+}, {
+	testName:    "unclosed-object",
+	data:        `{a: 1`,
+	expectError: `offset 5: unexpected end of input`,
+}, {
+	testName:    "missing-colon",
+	data:        `{a 1}`,
+	expectError: `offset 3: expected ':' after object key`,
+}, {
+	testName:    "unterminated-multiline-string",
+	data:        `{a: '''x}`,
+	expectError: `offset 9: unterminated multiline string`,
+}}
+
+func TestParseHjson(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseHjsonTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseHjson(test.data)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package main
+
+import "flag"
+
+var homogeneousArrays = flag.Bool("homogeneous-arrays", false, "fail if the elements of an array specified on the command line do not all have the same type")
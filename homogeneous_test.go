@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var homogeneousTests = []struct {
+	testName    string
+	args        string
+	expectError string
+}{{
+	testName: "numbers",
+	args:     ".[ 1 2 num 3 ]",
+}, {
+	testName: "objects",
+	args:     ".[ [ a: 1 ] [ ] ]",
+}, {
+	testName: "keyword-array-not-checked",
+	args:     `.[ json [1,"a"] .[ ] ]`,
+}, {
+	testName:    "string-among-numbers",
+	args:        ".[ 1 2 x 3 ]",
+	expectError: `array element at argument 3 has type string, but earlier elements have type number`,
+}, {
+	testName:    "nested",
+	args:        "a: .[ .[ true ] .[ ] [ ] ]",
+	expectError: `array element at argument 7 has type object, but earlier elements have type array`,
+}, {
+	testName:    "null",
+	args:        ".[ x null ]",
+	expectError: `array element at argument 2 has type null, but earlier elements have type string`,
+}}
+
+func TestHomogeneousArrays(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range homogeneousTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.homogeneous = true
+			_, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+		})
+	}
+}
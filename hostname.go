@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkDNSLabel checks that s is a valid RFC 1123 label:
+// 1 to 63 letters, digits or hyphens, not starting or
+// ending with a hyphen.
+func checkDNSLabel(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty label")
+	}
+	if len(s) > 63 {
+		return fmt.Errorf("label %q is longer than 63 characters", s)
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+			return fmt.Errorf("label %q contains invalid character %q", s, c)
+		}
+	}
+	if s[0] == '-' || s[len(s)-1] == '-' {
+		return fmt.Errorf("label %q starts or ends with a hyphen", s)
+	}
+	return nil
+}
+
+// checkHostname checks that s is a valid RFC 1123 host name,
+// optionally with a trailing dot.
+func checkHostname(s string) error {
+	name := strings.TrimSuffix(s, ".")
+	if len(name) > 253 {
+		return fmt.Errorf("name is longer than 253 characters")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if err := checkDNSLabel(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evalHostname(name, what string, check func(string) error) func([]string, int, interface{}) (interface{}, error) {
+	return func(_ []string, index int, v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s value at argument %d is %s, not string", name, index, valueKind(v))
+		}
+		if err := check(s); err != nil {
+			return nil, fmt.Errorf("invalid %s %q at argument %d: %v", what, s, index, err)
+		}
+		return s, nil
+	}
+}
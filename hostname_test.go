@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var hostnameTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "hostname",
+	args:     "hostname api-1.Example.com",
+	expect:   "api-1.Example.com",
+}, {
+	testName: "hostname-trailing-dot",
+	args:     "hostname example.com.",
+	expect:   "example.com.",
+}, {
+	testName: "hostname-leading-digit",
+	args:     "hostname 3com.com",
+	expect:   "3com.com",
+}, {
+	testName:    "hostname-underscore",
+	args:        "hostname my_host.example.com",
+	expectError: `invalid host name "my_host.example.com" at argument 1: label "my_host" contains invalid character '_'`,
+}, {
+	testName:    "hostname-empty-label",
+	args:        "hostname a..b",
+	expectError: `invalid host name "a..b" at argument 1: empty label`,
+}, {
+	testName:    "hostname-hyphen",
+	args:        "hostname -a.com",
+	expectError: `invalid host name "-a.com" at argument 1: label "-a" starts or ends with a hyphen`,
+}, {
+	testName:    "hostname-long-label",
+	args:        "hostname " + strings.Repeat("a", 64) + ".com",
+	expectError: `invalid host name "a+\.com" at argument 1: label "a+" is longer than 63 characters`,
+}, {
+	testName:    "hostname-too-long",
+	args:        "hostname " + strings.Repeat("abc.", 64) + "com",
+	expectError: `invalid host name ".*" at argument 1: name is longer than 253 characters`,
+}, {
+	testName: "dnslabel",
+	args:     "dnslabel web-0",
+	expect:   "web-0",
+}, {
+	testName:    "dnslabel-dot",
+	args:        "dnslabel a.b",
+	expectError: `invalid DNS label "a.b" at argument 1: label "a.b" contains invalid character '.'`,
+}, {
+	testName:    "dnslabel-not-string",
+	args:        "dnslabel 1",
+	expectError: `dnslabel value at argument 1 is number, not string`,
+}}
+
+func TestHostname(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range hostnameTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	mathrand "math/rand"
+	"strconv"
+	"time"
+)
+
+var seed = flag.String("seed", "", "seed for the ulid, ksuid, fake, choice and wchoice keywords; when set, the values they produce are the same on every run")
+
+// idSource provides the time and randomness used to
+// generate identifiers.
+type idSource struct {
+	rand io.Reader
+	now  func() time.Time
+}
+
+var ids = &idSource{
+	rand: rand.Reader,
+	now:  time.Now,
+}
+
+// setSeed makes the generation of identifiers and random values
+// deterministic. The randomness comes from pseudo-random generators
+// with the given seed, and the identifier clock starts at a fixed
+// time and advances by a second for each identifier, so identifiers
+// sort in the order they were generated.
+func setSeed(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid seed %q", s)
+	}
+	t := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids = &idSource{
+		rand: mathrand.New(mathrand.NewSource(n)),
+		now: func() time.Time {
+			now := t
+			t = t.Add(time.Second)
+			return now
+		},
+	}
+	valueRand = mathrand.New(mathrand.NewSource(n))
+	return nil
+}
+
+const (
+	crockfordDigits = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	base62Digits    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// encodeDigits returns the big-endian number in b encoded
+// with the given digits, left-padded with the zero digit
+// to the given width.
+func encodeDigits(b []byte, digits string, width int) string {
+	n := new(big.Int).SetBytes(b)
+	base := big.NewInt(int64(len(digits)))
+	buf := make([]byte, width)
+	d := new(big.Int)
+	for i := width - 1; i >= 0; i-- {
+		n.DivMod(n, base, d)
+		buf[i] = digits[d.Int64()]
+	}
+	return string(buf)
+}
+
+// ulid returns a ULID (see https://github.com/ulid/spec)
+// for the given time and 80 bits of randomness.
+func ulid(t time.Time, random []byte) string {
+	var b [16]byte
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	copy(b[6:], random)
+	return encodeDigits(b[:], crockfordDigits, 26)
+}
+
+// ksuidEpoch is the start of the KSUID timestamp range,
+// in Unix seconds.
+const ksuidEpoch = 1400000000
+
+// ksuid returns a KSUID (see https://github.com/segmentio/ksuid)
+// for the given time and 128-bit payload.
+func ksuid(t time.Time, payload []byte) string {
+	var b [20]byte
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()-ksuidEpoch))
+	copy(b[4:], payload)
+	return encodeDigits(b[:], base62Digits, 27)
+}
+
+func evalULID([]string, int, interface{}) (interface{}, error) {
+	random := make([]byte, 10)
+	if _, err := io.ReadFull(ids.rand, random); err != nil {
+		return nil, fmt.Errorf("cannot generate ulid: %v", err)
+	}
+	return ulid(ids.now(), random), nil
+}
+
+func evalKSUID([]string, int, interface{}) (interface{}, error) {
+	payload := make([]byte, 16)
+	if _, err := io.ReadFull(ids.rand, payload); err != nil {
+		return nil, fmt.Errorf("cannot generate ksuid: %v", err)
+	}
+	return ksuid(ids.now(), payload), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestULID(t *testing.T) {
+	c := qt.New(t)
+	// The example from the ULID specification.
+	tm := time.Unix(0, 1469918176385*int64(time.Millisecond))
+	id := ulid(tm, make([]byte, 10))
+	c.Assert(id, qt.Equals, "01ARYZ6S410000000000000000")
+	random := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	c.Assert(ulid(tm, random), qt.Equals, "01ARYZ6S41ZZZZZZZZZZZZZZZZ")
+}
+
+func TestKSUID(t *testing.T) {
+	c := qt.New(t)
+	// The example from the KSUID documentation.
+	payload, err := hex.DecodeString("B5A1CD34B5F99D1154FB6853345C9735")
+	c.Assert(err, qt.Equals, nil)
+	id := ksuid(time.Unix(ksuidEpoch+107608047, 0), payload)
+	c.Assert(id, qt.Equals, "0ujtsYcgvSTl8PAuAdqWYSMnLOv")
+}
+
+func TestSeededIDs(t *testing.T) {
+	c := qt.New(t)
+	defer func(old *idSource) {
+		ids = old
+	}(ids)
+	run := func() []interface{} {
+		err := setSeed("42")
+		c.Assert(err, qt.Equals, nil)
+		vals, err := newParser([]string{"ulid", "ulid", "ksuid"}).run()
+		c.Assert(err, qt.Equals, nil)
+		return vals
+	}
+	vals := run()
+	c.Assert(run(), qt.DeepEquals, vals)
+	c.Assert(vals[0].(string) < vals[1].(string), qt.Equals, true)
+	c.Assert(vals[0], qt.HasLen, 26)
+	c.Assert(vals[2], qt.HasLen, 27)
+
+	err := setSeed("x")
+	c.Assert(err, qt.ErrorMatches, `invalid seed "x"`)
+}
+
+func TestIDsNotHermetic(t *testing.T) {
+	c := qt.New(t)
+	p := newParser([]string{"ksuid"})
+	p.hermetic = true
+	_, err := p.run()
+	c.Assert(err, qt.ErrorMatches, `ksuid at argument 0 is not allowed because it performs I/O or is nondeterministic`)
+}
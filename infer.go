@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"sort"
+)
+
+var typeName = flag.String("type-name", "Value", "name of the type printed by -typescript and -gostruct")
+
+// inferredType describes the union of the types of a set of values.
+type inferredType struct {
+	null, bool, number, string bool
+	// integer holds whether all numbers seen were whole numbers.
+	integer bool
+	// array, if not nil, holds the type of the elements of all arrays seen.
+	array *inferredType
+	// object, if not nil, describes all the objects seen.
+	object *objectType
+}
+
+// objectType describes a set of objects.
+type objectType struct {
+	// count holds the number of objects seen.
+	count int
+	// fields holds the types of all the members seen.
+	fields map[string]*inferredType
+	// present holds how many objects held each member.
+	present map[string]int
+}
+
+func newInferredType() *inferredType {
+	return &inferredType{
+		integer: true,
+	}
+}
+
+// add adds the type of v to t.
+func (t *inferredType) add(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		t.null = true
+	case bool:
+		t.bool = true
+	case string:
+		t.string = true
+	case float64, json.Number:
+		t.number = true
+		if _, ok := intValue(v); !ok {
+			t.integer = false
+		}
+	case []interface{}:
+		if t.array == nil {
+			t.array = newInferredType()
+		}
+		for _, elem := range v {
+			t.array.add(elem)
+		}
+	case map[string]interface{}:
+		if t.object == nil {
+			t.object = &objectType{
+				fields:  make(map[string]*inferredType),
+				present: make(map[string]int),
+			}
+		}
+		o := t.object
+		o.count++
+		for k, elem := range v {
+			ft := o.fields[k]
+			if ft == nil {
+				ft = newInferredType()
+				o.fields[k] = ft
+			}
+			ft.add(elem)
+			o.present[k]++
+		}
+	}
+}
+
+// kinds returns the number of different kinds of non-null value in t.
+func (t *inferredType) kinds() int {
+	n := 0
+	for _, ok := range []bool{t.bool, t.number, t.string, t.array != nil, t.object != nil} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// optional reports whether the member k was absent from some of the objects.
+func (o *objectType) optional(k string) bool {
+	return o.present[k] < o.count
+}
+
+func (o *objectType) keys() []string {
+	keys := make([]string, 0, len(o.fields))
+	for k := range o.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inferEncoder is an encoder that infers the type of all the values
+// it is given and prints a description of that type when closed.
+type inferEncoder struct {
+	t     *inferredType
+	print func(t *inferredType) error
+}
+
+func (e *inferEncoder) Encode(v interface{}) error {
+	e.t.add(v)
+	return nil
+}
+
+func (e *inferEncoder) Close() error {
+	return e.print(e.t)
+}
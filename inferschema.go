@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// newSchemaEncoder returns an encoder that prints a JSON Schema
+// describing all the values it is given when closed.
+func newSchemaEncoder(w io.Writer) encoder {
+	return &inferEncoder{
+		t: newInferredType(),
+		print: func(t *inferredType) error {
+			schema := inferredSchema(t)
+			schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+			data, err := json.MarshalIndent(schema, "", "\t")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n", data)
+			return err
+		},
+	}
+}
+
+// inferredSchema returns a JSON Schema that matches all
+// the values described by t. A value of more than one kind
+// results in a list of types.
+func inferredSchema(t *inferredType) map[string]interface{} {
+	schema := make(map[string]interface{})
+	var types []interface{}
+	add := func(ok bool, name string) {
+		if ok {
+			types = append(types, name)
+		}
+	}
+	add(t.null, "null")
+	add(t.bool, "boolean")
+	add(t.number && t.integer, "integer")
+	add(t.number && !t.integer, "number")
+	add(t.string, "string")
+	if t.array != nil {
+		types = append(types, "array")
+		if t.array.kinds() > 0 || t.array.null {
+			schema["items"] = inferredSchema(t.array)
+		}
+	}
+	if o := t.object; o != nil {
+		types = append(types, "object")
+		props := make(map[string]interface{})
+		var required []interface{}
+		for _, k := range o.keys() {
+			props[k] = inferredSchema(o.fields[k])
+			if !o.optional(k) {
+				required = append(required, k)
+			}
+		}
+		schema["properties"] = props
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	switch len(types) {
+	case 0:
+		// No values seen at all (for example the
+		// elements of an empty array), so anything goes.
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+	return schema
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var schemaEncoderTests = []struct {
+	testName string
+	vals     []interface{}
+	expect   string
+}{{
+	testName: "scalars",
+	vals:     []interface{}{"x", 1.0, json.Number("2"), nil},
+	expect:   `{"type": ["null", "integer", "string"]}`,
+}, {
+	testName: "object",
+	vals: []interface{}{
+		map[string]interface{}{
+			"a": 1.5,
+			"b": []interface{}{"x", true},
+			"c": map[string]interface{}{"e": nil},
+		},
+		map[string]interface{}{
+			"a": 2.0,
+			"f": []interface{}{},
+		},
+	},
+	expect: `{
+		"type": "object",
+		"properties": {
+			"a": {"type": "number"},
+			"b": {"type": "array", "items": {"type": ["boolean", "string"]}},
+			"c": {"type": "object", "properties": {"e": {"type": "null"}}, "required": ["e"]},
+			"f": {"type": "array"}
+		},
+		"required": ["a"]
+	}`,
+}, {
+	testName: "no-values",
+	expect:   `{}`,
+}}
+
+func TestSchemaEncoder(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range schemaEncoderTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			enc := newSchemaEncoder(&buf).(encoderCloser)
+			for _, v := range test.vals {
+				c.Assert(enc.Encode(v), qt.Equals, nil)
+			}
+			c.Assert(buf.Len(), qt.Equals, 0)
+			c.Assert(enc.Close(), qt.Equals, nil)
+			expect := unmarshalJSON(c, test.expect).(map[string]interface{})
+			expect["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+			c.Assert(unmarshalJSON(c, buf.String()), qt.DeepEquals, expect)
+
+			// The values satisfy the schema.
+			schema := unmarshalJSON(c, buf.String())
+			for _, v := range test.vals {
+				c.Assert(validateSchema(schema, schema, v), qt.HasLen, 0)
+			}
+		})
+	}
+}
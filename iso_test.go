@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var isoTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "country",
+	args:     "iso-country gb",
+	expect:   "GB",
+}, {
+	testName:    "country-invalid",
+	args:        "iso-country XY",
+	expectError: `invalid country code "XY" at argument 1`,
+}, {
+	testName:    "country-alpha3",
+	args:        "iso-country GBR",
+	expectError: `invalid country code "GBR" at argument 1`,
+}, {
+	testName: "lang",
+	args:     "iso-lang EN",
+	expect:   "en",
+}, {
+	testName:    "lang-invalid",
+	args:        "iso-lang xx",
+	expectError: `invalid language code "xx" at argument 1`,
+}, {
+	testName: "currency",
+	args:     "iso-currency eur",
+	expect:   "EUR",
+}, {
+	testName:    "currency-invalid",
+	args:        "iso-currency ABC",
+	expectError: `invalid currency code "ABC" at argument 1`,
+}, {
+	testName:    "not-string",
+	args:        "iso-lang 3",
+	expectError: `iso-lang value at argument 1 is number, not string`,
+}}
+
+func TestISOCodes(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range isoTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
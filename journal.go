@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+var journal = flag.Bool("journal", false, "also send each value to the systemd journal as structured fields, or to standard error if the journal is not available")
+
+// journalSocket holds the path of journald's native protocol socket.
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalEncoder is an encoder that sends each value to the systemd
+// journal after passing it on to another encoder.
+type journalEncoder struct {
+	encoder
+	conn net.Conn
+	// fallback receives log entries when the journal
+	// is not available.
+	fallback io.Writer
+}
+
+func newJournalEncoder(enc encoder) encoder {
+	e := &journalEncoder{
+		encoder:  enc,
+		fallback: os.Stderr,
+	}
+	if conn, err := net.Dial("unixgram", journalSocket); err == nil {
+		e.conn = conn
+	}
+	return e
+}
+
+func (e *journalEncoder) Encode(v interface{}) error {
+	if err := e.encoder.Encode(v); err != nil {
+		return err
+	}
+	entry, err := journalEntry(v)
+	if err != nil {
+		return err
+	}
+	if e.conn != nil {
+		if _, err := e.conn.Write(entry); err == nil {
+			return nil
+		}
+	}
+	msg, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.fallback, "%s\n", msg)
+	return err
+}
+
+// journalEntry returns the native journal protocol encoding of v.
+// The MESSAGE field holds v encoded as JSON. If v is an object,
+// each of its members is also sent as a field with a name derived
+// from the member's key; non-string members are encoded as JSON.
+func journalEntry(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	msg, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	writeJournalField(&buf, "MESSAGE", string(msg))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", "json")
+	obj, _ := v.(map[string]interface{})
+	for _, k := range sortedKeys(obj) {
+		name := journalFieldName(k)
+		if name == "" || name == "MESSAGE" || name == "SYSLOG_IDENTIFIER" {
+			continue
+		}
+		val, ok := obj[k].(string)
+		if !ok {
+			data, err := json.Marshal(obj[k])
+			if err != nil {
+				return nil, err
+			}
+			val = string(data)
+		}
+		writeJournalField(&buf, name, val)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeJournalField writes a single field in journal native protocol
+// format. Values containing newlines are written in the binary-safe form.
+func writeJournalField(buf *bytes.Buffer, name, val string) {
+	buf.WriteString(name)
+	if !strings.Contains(val, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(val)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(val)))
+	buf.Write(size[:])
+	buf.WriteString(val)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts an object key to a journal field name,
+// which may contain only upper case letters, digits and underscores
+// and may not start with an underscore or digit.
+// It returns the empty string if there is no such name.
+func journalFieldName(k string) string {
+	name := []byte(strings.ToUpper(k))
+	for i, c := range name {
+		if !('A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			name[i] = '_'
+		}
+	}
+	return strings.TrimLeft(string(name), "_0123456789")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestJournalEntry(t *testing.T) {
+	c := qt.New(t)
+	entry, err := journalEntry(map[string]interface{}{
+		"level":    "info",
+		"count":    2.0,
+		"x-y":      []interface{}{true},
+		"_trusted": "x",
+		"text":     "a\nb",
+	})
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(string(entry), qt.Equals, ""+
+		`MESSAGE={"_trusted":"x","count":2,"level":"info","text":"a\nb","x-y":[true]}`+"\n"+
+		"SYSLOG_IDENTIFIER=json\n"+
+		"TRUSTED=x\n"+
+		"COUNT=2\n"+
+		"LEVEL=info\n"+
+		"TEXT\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n"+
+		"X_Y=[true]\n",
+	)
+}
+
+func TestJournalEncoderFallback(t *testing.T) {
+	c := qt.New(t)
+	var out, fallback bytes.Buffer
+	enc := &journalEncoder{
+		encoder:  newJSONEncoder(&out),
+		fallback: &fallback,
+	}
+	err := enc.Encode(map[string]interface{}{"a": 1.0})
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(out.String(), qt.Equals, `{"a":1}`+"\n")
+	c.Assert(fallback.String(), qt.Equals, `{"a":1}`+"\n")
+}
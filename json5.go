@@ -0,0 +1,501 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// json5Encoder encodes values as JSON5 (see https://spec.json5.org),
+// intended for files that will be edited by hand. Object keys are
+// unquoted where possible, strings use single quotes, and each member
+// of a multi-line object or array is followed by a comma.
+type json5Encoder struct {
+	w io.Writer
+}
+
+func newJSON5Encoder(w io.Writer) encoder {
+	return &json5Encoder{w: w}
+}
+
+func (e *json5Encoder) Encode(v interface{}) error {
+	var buf bytes.Buffer
+	if err := writeJSON5Value(&buf, v, ""); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSON5Value(buf *bytes.Buffer, v interface{}, indent string) error {
+	switch v := v.(type) {
+	case string:
+		buf.WriteString(json5Quote(v))
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			break
+		}
+		buf.WriteString("{\n")
+		for _, k := range sortedKeys(v) {
+			name := k
+			if !isJSON5Identifier(k) {
+				name = json5Quote(k)
+			}
+			buf.WriteString(indent + "\t" + name + ": ")
+			if err := writeJSON5Value(buf, v[k], indent+"\t"); err != nil {
+				return err
+			}
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		composite := false
+		for _, elem := range v {
+			composite = composite || isComposite(elem)
+		}
+		if !composite {
+			buf.WriteString("[")
+			for i, elem := range v {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				if err := writeJSON5Value(buf, elem, indent); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("]")
+			break
+		}
+		buf.WriteString("[\n")
+		for _, elem := range v {
+			buf.WriteString(indent + "\t")
+			if err := writeJSON5Value(buf, elem, indent+"\t"); err != nil {
+				return err
+			}
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(indent + "]")
+	case nil:
+		buf.WriteString("null")
+	default:
+		text, err := scalarText(v)
+		if err != nil {
+			return fmt.Errorf("cannot encode %T as JSON5", v)
+		}
+		buf.WriteString(text)
+	}
+	return nil
+}
+
+var json5Escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\u2028", `\u2028`,
+	"\u2029", `\u2029`,
+)
+
+func json5Quote(s string) string {
+	s = json5Escaper.Replace(s)
+	if strings.IndexFunc(s, isControl) >= 0 {
+		var buf strings.Builder
+		for _, r := range s {
+			if isControl(r) {
+				fmt.Fprintf(&buf, `\x%02x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+		s = buf.String()
+	}
+	return "'" + s + "'"
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// isJSON5Identifier reports whether s can be used
+// as an unquoted object key in JSON5. Only ASCII
+// identifiers are recognized.
+func isJSON5Identifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseJSON5 parses s as a JSON5 value (which includes JSONC)
+// and returns the equivalent JSON value. Numbers are returned
+// as json.Number.
+func parseJSON5(s string) (interface{}, error) {
+	p := &json5Parser{s: s}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q after value", p.peekRune())
+	}
+	return v, nil
+}
+
+type json5Parser struct {
+	s   string
+	pos int
+}
+
+func (p *json5Parser) errorf(f string, a ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(f, a...))
+}
+
+func (p *json5Parser) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(p.s[p.pos:])
+	return r
+}
+
+// skipSpace skips white space and comments.
+func (p *json5Parser) skipSpace() error {
+	for p.pos < len(p.s) {
+		rest := p.s[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexAny(rest, "\n\r\u2028\u2029")
+			if end < 0 {
+				end = len(rest)
+			}
+			p.pos += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.pos += end + 4
+		default:
+			r, size := utf8.DecodeRuneInString(rest)
+			if !unicode.IsSpace(r) && r != '\ufeff' {
+				return nil
+			}
+			p.pos += size
+		}
+	}
+	return nil
+}
+
+func (p *json5Parser) value() (interface{}, error) {
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch c := p.s[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || c == '\'':
+		return p.string()
+	case c == '-' || c == '+' || c == '.' || '0' <= c && c <= '9':
+		return p.number()
+	}
+	start := p.pos
+	id, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	switch id {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "Infinity", "NaN":
+		p.pos = start
+		return nil, p.errorf("%s cannot be represented in JSON", id)
+	}
+	p.pos = start
+	return nil, p.errorf("unexpected %q", id)
+}
+
+func (p *json5Parser) object() (interface{}, error) {
+	p.pos++
+	obj := make(map[string]interface{})
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.s) && p.s[p.pos] == '}' {
+			p.pos++
+			return obj, nil
+		}
+		var key string
+		if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+			k, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			key = k.(string)
+		} else {
+			k, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		}
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ':' {
+			return nil, p.errorf("expected ':' after object key")
+		}
+		p.pos++
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+		if done, err := p.endOfElement('}'); err != nil || done {
+			return obj, err
+		}
+	}
+}
+
+func (p *json5Parser) array() (interface{}, error) {
+	p.pos++
+	elems := []interface{}{}
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.s) && p.s[p.pos] == ']' {
+			p.pos++
+			return elems, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, v)
+		if done, err := p.endOfElement(']'); err != nil || done {
+			return elems, err
+		}
+	}
+}
+
+// endOfElement consumes the comma or closing delimiter after
+// an object member or array element and reports whether
+// it was the closing delimiter.
+func (p *json5Parser) endOfElement(close byte) (bool, error) {
+	if err := p.skipSpace(); err != nil {
+		return false, err
+	}
+	if p.pos >= len(p.s) {
+		return false, p.errorf("unexpected end of input")
+	}
+	switch p.s[p.pos] {
+	case ',':
+		p.pos++
+		return false, nil
+	case close:
+		p.pos++
+		return true, nil
+	}
+	return false, p.errorf("expected ',' or %q", close)
+}
+
+// identifier parses an ECMAScript identifier name
+// as used for unquoted object keys.
+func (p *json5Parser) identifier() (string, error) {
+	var buf strings.Builder
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if r == '\\' {
+			if !strings.HasPrefix(p.s[p.pos:], `\u`) {
+				return "", p.errorf("invalid escape in identifier")
+			}
+			p.pos += 2
+			r2, err := p.hex(4)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteRune(r2)
+			continue
+		}
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || buf.Len() > 0 && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) || unicode.Is(unicode.Pc, r))) {
+			break
+		}
+		buf.WriteRune(r)
+		p.pos += size
+	}
+	if buf.Len() == 0 {
+		if p.pos >= len(p.s) {
+			return "", p.errorf("unexpected end of input")
+		}
+		return "", p.errorf("unexpected %q", p.peekRune())
+	}
+	return buf.String(), nil
+}
+
+// hex parses n hex digits and returns their value.
+func (p *json5Parser) hex(n int) (rune, error) {
+	if p.pos+n > len(p.s) {
+		return 0, p.errorf("invalid hex escape")
+	}
+	x, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid hex escape")
+	}
+	p.pos += n
+	return rune(x), nil
+}
+
+func (p *json5Parser) string() (interface{}, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var buf strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unterminated string")
+		}
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return buf.String(), nil
+		case c == '\n' || c == '\r':
+			return nil, p.errorf("newline in string")
+		case c != '\\':
+			buf.WriteByte(c)
+			p.pos++
+			continue
+		}
+		p.pos++
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unterminated string")
+		}
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		p.pos += size
+		switch r {
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'v':
+			buf.WriteByte('\v')
+		case '0':
+			if p.pos < len(p.s) && '0' <= p.s[p.pos] && p.s[p.pos] <= '9' {
+				return nil, p.errorf("invalid escape")
+			}
+			buf.WriteByte(0)
+		case 'x':
+			x, err := p.hex(2)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteRune(x)
+		case 'u':
+			x, err := p.hex(4)
+			if err != nil {
+				return nil, err
+			}
+			if utf16.IsSurrogate(x) && strings.HasPrefix(p.s[p.pos:], `\u`) {
+				save := p.pos
+				p.pos += 2
+				y, err := p.hex(4)
+				if err == nil && utf16.DecodeRune(x, y) != unicode.ReplacementChar {
+					x = utf16.DecodeRune(x, y)
+				} else {
+					p.pos = save
+				}
+			}
+			buf.WriteRune(x)
+		case '\r':
+			// Line continuation.
+			if p.pos < len(p.s) && p.s[p.pos] == '\n' {
+				p.pos++
+			}
+		case '\n', '\u2028', '\u2029':
+			// Line continuation.
+		default:
+			if '1' <= r && r <= '9' {
+				return nil, p.errorf("invalid escape")
+			}
+			buf.WriteRune(r)
+		}
+	}
+}
+
+var json5NumberPattern = regexp.MustCompile(`^[+-]?(0[xX][0-9a-fA-F]+|Infinity|NaN|([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?)`)
+
+func (p *json5Parser) number() (interface{}, error) {
+	text := json5NumberPattern.FindString(p.s[p.pos:])
+	if text == "" {
+		return nil, p.errorf("invalid number")
+	}
+	p.pos += len(text)
+	neg := strings.HasPrefix(text, "-")
+	digits := strings.TrimLeft(text, "+-")
+	switch {
+	case digits == "Infinity" || digits == "NaN":
+		return nil, p.errorf("%s cannot be represented in JSON", text)
+	case strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X"):
+		n, ok := new(big.Int).SetString(digits[2:], 16)
+		if !ok {
+			return nil, p.errorf("invalid number %q", text)
+		}
+		if neg {
+			n.Neg(n)
+		}
+		return json.Number(n.String()), nil
+	}
+	if neg {
+		digits = "-" + digits
+	}
+	if isJSONNumber(digits) {
+		return json.Number(digits), nil
+	}
+	// Leading or trailing decimal points and leading zeros
+	// are allowed in JSON5 but not in JSON.
+	f, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", text)
+	}
+	return floatNumber(f)
+}
+
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func isJSONNumber(s string) bool {
+	return jsonNumberPattern.MatchString(s)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var json5Tests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "scalars",
+	val:      []interface{}{nil, true, 1.5, json.Number("12"), `it's "q"` + "\n\x01"},
+	expect:   `[null, true, 1.5, 12, 'it\'s "q"\n\x01']` + "\n",
+}, {
+	testName: "nested",
+	val: map[string]interface{}{
+		"name":     "x",
+		"$ref":     "y",
+		"two-word": 1.0,
+		"1a":       2.0,
+		"list": []interface{}{
+			map[string]interface{}{"a": []interface{}{}},
+			map[string]interface{}{},
+		},
+	},
+	expect: `{
+	$ref: 'y',
+	'1a': 2,
+	list: [
+		{
+			a: [],
+		},
+		{},
+	],
+	name: 'x',
+	'two-word': 1,
+}
+`,
+}}
+
+func TestJSON5Encode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range json5Tests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newJSON5Encoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
+
+var parseJSON5Tests = []struct {
+	testName    string
+	data        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "plain-json",
+	data:     `{"a": [1, "x", true, null]}`,
+	expect: map[string]interface{}{
+		"a": []interface{}{json.Number("1"), "x", true, nil},
+	},
+}, {
+	testName: "comments-and-trailing-commas",
+	data: `// leading
+{
+	a: 1, /* inline */
+	b: [2, 3,],
+}`,
+	expect: map[string]interface{}{
+		"a": json.Number("1"),
+		"b": []interface{}{json.Number("2"), json.Number("3")},
+	},
+}, {
+	testName: "unquoted-and-single-quoted-keys",
+	data:     `{$id: 1, _x2: 2, 'a b': 3, ac: 4}`,
+	expect: map[string]interface{}{
+		"$id": json.Number("1"),
+		"_x2": json.Number("2"),
+		"a b": json.Number("3"),
+		"ac":  json.Number("4"),
+	},
+}, {
+	testName: "strings",
+	data:     `['it\'s', "a\x41é\0", 'line \` + "\n" + `cont', '😀']`,
+	expect:   []interface{}{"it's", "aAé\x00", "line cont", "😀"},
+}, {
+	testName: "numbers",
+	data:     `[0x1F, -0xff, .5, 5., +3, 1e3, 007]`,
+	expect: []interface{}{
+		json.Number("31"),
+		json.Number("-255"),
+		json.Number("0.5"),
+		json.Number("5"),
+		json.Number("3"),
+		json.Number("1e3"),
+		json.Number("7"),
+	},
+}, {
+	testName:    "infinity",
+	data:        `[Infinity]`,
+	expectError: `offset 1: Infinity cannot be represented in JSON`,
+}, {
+	testName:    "missing-comma",
+	data:        `[1 2]`,
+	expectError: `offset 3: expected ',' or '\]'`,
+}, {
+	testName:    "trailing-data",
+	data:        `{} x`,
+	expectError: `offset 3: unexpected 'x' after value`,
+}, {
+	testName:    "unterminated-comment",
+	data:        `1 /* x`,
+	expectError: `offset 2: unterminated comment`,
+}, {
+	testName:    "newline-in-string",
+	data:        "'a\nb'",
+	expectError: `offset 2: newline in string`,
+}}
+
+func TestParseJSON5(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseJSON5Tests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseJSON5(test.data)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
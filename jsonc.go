@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsoncEncoder encodes values as indented JSON with comments
+// (JSONC, as used by VS Code configuration files). Each
+// object member that was preceded by a comment directive
+// is preceded by the comment.
+type jsoncEncoder struct {
+	w io.Writer
+	n int
+	// comments holds the comments attached to object members
+	// by comment directives, as recorded by the parser: for each
+	// top level value, a map from the JSON Pointer of a member
+	// to its comment.
+	comments []map[string]string
+}
+
+func newJSONCEncoder(w io.Writer) encoder {
+	return &jsoncEncoder{w: w}
+}
+
+// withComments returns an encoder constructor like newEncoder
+// except that, if it makes a jsonc encoder, the encoder prints
+// the given comments, as held in jsoncEncoder.comments.
+func withComments(newEncoder func(w io.Writer) encoder, comments []map[string]string) func(w io.Writer) encoder {
+	return func(w io.Writer) encoder {
+		enc := newEncoder(w)
+		if enc, ok := enc.(*jsoncEncoder); ok {
+			enc.comments = comments
+		}
+		return enc
+	}
+}
+
+func (e *jsoncEncoder) Encode(v interface{}) error {
+	var comments map[string]string
+	if e.n < len(e.comments) {
+		comments = e.comments[e.n]
+	}
+	e.n++
+	var buf bytes.Buffer
+	if err := writeJSONCValue(&buf, v, "", "", comments); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSONCValue(buf *bytes.Buffer, v interface{}, path, indent string, comments map[string]string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			break
+		}
+		buf.WriteString("{")
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+			elemPath := path + "/" + pointerEscaper.Replace(k)
+			if comment, ok := comments[elemPath]; ok {
+				for _, line := range strings.Split(comment, "\n") {
+					buf.WriteString(strings.TrimRight(indent+"\t// "+line, " ") + "\n")
+				}
+			}
+			key, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			buf.WriteString(indent + "\t")
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := writeJSONCValue(buf, v[k], elemPath, indent+"\t", comments); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			break
+		}
+		if *compactArrays && isScalarArray(v) {
+			return writeCompactArray(buf, v)
+		}
+		buf.WriteString("[")
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "\t")
+			if err := writeJSONCValue(buf, elem, path+"/"+strconv.Itoa(i), indent+"\t", comments); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var jsoncTests = []struct {
+	testName string
+	args     []string
+	expect   string
+}{{
+	testName: "no-comments",
+	args:     []string{".[", "1", "[", "]", ".[", "]", "]"},
+	expect: `[
+	1,
+	{},
+	[]
+]
+`,
+}, {
+	testName: "comments",
+	args: []string{
+		"comment", "port number", "port:", "8080",
+		"tls:", "[",
+		"comment", "line one\nline two", "key", "a/b", "true",
+		"]",
+		"list:", ".[", "[", "comment", "", "x:", "1", "]", "]",
+	},
+	expect: `{
+	"list": [
+		{
+			//
+			"x": 1
+		}
+	],
+	// port number
+	"port": 8080,
+	"tls": {
+		// line one
+		// line two
+		"a/b": true
+	}
+}
+`,
+}, {
+	testName: "multiple-values",
+	args:     []string{"[", "comment", "first", "a:", "1", "]", "[", "a:", "2", "]"},
+	expect: `{
+	// first
+	"a": 1
+}
+{
+	"a": 2
+}
+`,
+}}
+
+func TestJSONCEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range jsoncTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(test.args)
+			vals, err := p.run()
+			c.Assert(err, qt.Equals, nil)
+			var buf bytes.Buffer
+			enc := withComments(newJSONCEncoder, p.comments)(&buf)
+			for _, v := range vals {
+				err := enc.Encode(v)
+				c.Assert(err, qt.Equals, nil)
+			}
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
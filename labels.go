@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseLabels parses a Prometheus-style label set such as
+//
+//	http_requests_total{job="api",code="200"}
+//
+// into an object holding the metric name (if present) and
+// an object holding the labels.
+func parseLabels(s string) (map[string]interface{}, error) {
+	labels := make(map[string]interface{})
+	v := map[string]interface{}{
+		"labels": labels,
+	}
+	name, s := labelName(strings.TrimSpace(s), true)
+	s = strings.TrimSpace(s)
+	if name != "" {
+		v["name"] = name
+	}
+	if s == "" {
+		if name == "" {
+			return nil, fmt.Errorf("empty label set")
+		}
+		return v, nil
+	}
+	if s[0] != '{' {
+		return nil, fmt.Errorf("unexpected %q after metric name", s)
+	}
+	s = strings.TrimSpace(s[1:])
+	for !strings.HasPrefix(s, "}") {
+		var label string
+		label, s = labelName(s, false)
+		if label == "" {
+			return nil, fmt.Errorf("expected label name at %q", s)
+		}
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "=") || strings.HasPrefix(s, "=~") {
+			return nil, fmt.Errorf("expected = after label name %q", label)
+		}
+		s = strings.TrimSpace(s[1:])
+		val, rest, err := labelValue(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for label %q: %v", label, err)
+		}
+		if _, ok := labels[label]; ok {
+			return nil, fmt.Errorf("duplicate label %q", label)
+		}
+		labels[label] = val
+		s = strings.TrimSpace(rest)
+		if strings.HasPrefix(s, ",") {
+			s = strings.TrimSpace(s[1:])
+		} else if !strings.HasPrefix(s, "}") {
+			return nil, fmt.Errorf("expected , or } after value of label %q", label)
+		}
+	}
+	if s = strings.TrimSpace(s[1:]); s != "" {
+		return nil, fmt.Errorf("unexpected %q after label set", s)
+	}
+	return v, nil
+}
+
+// labelName returns the metric or label name at the start of s
+// and the remainder of s. Metric names may also contain colons.
+func labelName(s string, metric bool) (string, string) {
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		ok := c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+			i > 0 && '0' <= c && c <= '9' ||
+			metric && c == ':'
+		if !ok {
+			break
+		}
+	}
+	return s[:i], s[i:]
+}
+
+// labelValue returns the double-quoted label value at the start of s
+// and the remainder of s.
+func labelValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", fmt.Errorf("value must be double-quoted")
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			val, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", err
+			}
+			return val, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted string")
+}
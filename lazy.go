@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	freeze = flag.Bool("freeze", false, "instead of printing the values, print arguments that produce them, suitable for -f, leaving lazy parameters that have no value in place")
+	params paramFlag
+)
+
+func init() {
+	flag.Var(&params, "param", "value of a parameter used by the lazy keyword, specified as NAME=ARGS; may be repeated")
+	// The lazy keyword is registered here rather than in the
+	// keywords table because evaluating a parameter parses its
+	// arguments, which would make the table refer to itself.
+	keywords["lazy"] = &keyword{
+		args:     []string{"parameter name"},
+		parse:    (*parser).lazyParam,
+		noExpand: true,
+	}
+}
+
+// paramFlag implements flag.Value for the -param flag.
+// It maps each parameter name to the arguments,
+// in the syntax of a -f file, that make its value.
+type paramFlag map[string]string
+
+func (f *paramFlag) String() string {
+	var parts []string
+	for _, name := range sortedStrings(*f) {
+		parts = append(parts, name+"="+(*f)[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f *paramFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected NAME=ARGS")
+	}
+	if *f == nil {
+		*f = make(paramFlag)
+	}
+	(*f)[s[:i]] = s[i+1:]
+	return nil
+}
+
+func sortedStrings(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// A lazyValue stands for a value that depends on a parameter
+// with no value. It holds the arguments that produce the value
+// once the parameter has one.
+type lazyValue struct {
+	args []string
+}
+
+// lazyParam parses the name following a lazy argument at the given
+// index and returns the value of the named parameter or, when
+// freezing, a lazyValue if the parameter has no value.
+func (p *parser) lazyParam(start int) (interface{}, error) {
+	name, err := p.mustNext("parameter name")
+	if err != nil {
+		return nil, err
+	}
+	text, ok := p.params[name]
+	if !ok {
+		if !p.freeze {
+			return nil, fmt.Errorf("parameter %q at argument %d has no value", name, start)
+		}
+		return &lazyValue{args: []string{"lazy", name}}, nil
+	}
+	args, err := splitScript(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for parameter %q: line %v", name, err)
+	}
+	q := newParser(args)
+	q.hermetic = p.hermetic
+	vals, err := q.run()
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for parameter %q: %v", name, err)
+	}
+	if len(vals) != 1 {
+		return nil, fmt.Errorf("value for parameter %q holds %d values, not 1", name, len(vals))
+	}
+	if p.paramArgs == nil {
+		p.paramArgs = make(map[int][]string)
+	}
+	p.paramArgs[start] = args
+	return vals[0], nil
+}
+
+// lazyArgError returns the error for a lazy argument at the
+// given index that's used as the argument of a keyword. A
+// parameter's value isn't necessarily a string and it can't
+// be left in place when freezing, so that's not allowed.
+func lazyArgError(index int) error {
+	return fmt.Errorf("lazy at argument %d can only be used as a value, not as the argument of a keyword", index)
+}
+
+// rawArgs returns the arguments from start to end, with the
+// arguments of each parameter that has a value in place of the
+// lazy keyword that refers to it.
+func (p *parser) rawArgs(start, end int) []string {
+	var args []string
+	for i := start; i < end; i++ {
+		if pargs, ok := p.paramArgs[i]; ok {
+			args = append(args, pargs...)
+			i++
+			continue
+		}
+		args = append(args, p.args[i])
+	}
+	return args
+}
+
+func containsLazy(v interface{}) bool {
+	switch v := v.(type) {
+	case *lazyValue:
+		return true
+	case map[string]interface{}:
+		for _, elem := range v {
+			if containsLazy(elem) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, elem := range v {
+			if containsLazy(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeFrozen writes, for each value, a line holding
+// arguments that produce the value.
+func writeFrozen(w io.Writer, vals []interface{}) error {
+	for _, v := range vals {
+		args := frozenArgs(v, nil)
+		for i, a := range args {
+			args[i] = quoteScriptArg(a)
+		}
+		if _, err := io.WriteString(w, strings.Join(args, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// frozenArgs appends arguments that produce v to args.
+func frozenArgs(v interface{}, args []string) []string {
+	switch v := v.(type) {
+	case *lazyValue:
+		return append(args, v.args...)
+	case nil:
+		return append(args, "null")
+	case bool:
+		return append(args, strconv.FormatBool(v))
+	case float64:
+		return append(args, strconv.FormatFloat(v, 'g', -1, 64))
+	case json.Number:
+		return append(args, "num", string(v))
+	case string:
+		if isPlainArg(v) {
+			return append(args, v)
+		}
+		data, _ := json.Marshal(v)
+		return append(args, "json", string(data))
+	case map[string]interface{}:
+		args = append(args, "[")
+		for _, k := range sortedKeys(v) {
+			args = frozenArgs(v[k], append(args, k+":"))
+		}
+		return append(args, "]")
+	case []interface{}:
+		args = append(args, ".[")
+		for _, elem := range v {
+			args = frozenArgs(elem, args)
+		}
+		return append(args, "]")
+	}
+	panic(fmt.Errorf("unexpected value type %T", v))
+}
+
+// isPlainArg reports whether s, used as an argument on
+// its own, produces the string s.
+func isPlainArg(s string) bool {
+	vals, err := newParser([]string{s}).run()
+	return err == nil && len(vals) == 1 && vals[0] == s
+}
+
+// quoteScriptArg quotes a, if necessary, so that
+// splitScript treats it as a single argument.
+func quoteScriptArg(a string) string {
+	if a != "" && !strings.HasPrefix(a, "#") && !strings.ContainsAny(a, " \t\r\n'\"\\") {
+		return a
+	}
+	return "'" + strings.Replace(a, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var lazyTests = []struct {
+	testName    string
+	args        string
+	params      map[string]string
+	freeze      bool
+	expect      string
+	expectError string
+}{{
+	testName: "param",
+	args:     "name: lazy name port: lazy port",
+	params: map[string]string{
+		"name": "'web server'",
+		"port": "num 80",
+	},
+	expect: `{"name":"web server","port":80}`,
+}, {
+	testName: "composite-param",
+	args:     "a: lazy x",
+	params:   map[string]string{"x": "[ b: .[ 1 2 ] ]"},
+	expect:   `{"a":{"b":[1,2]}}`,
+}, {
+	testName:    "unbound",
+	args:        "a: lazy x",
+	expectError: `parameter "x" at argument 1 has no value`,
+}, {
+	testName:    "param-several-values",
+	args:        "a: lazy x",
+	params:      map[string]string{"x": "web server"},
+	expectError: `value for parameter "x" holds 2 values, not 1`,
+}, {
+	testName:    "param-invalid",
+	args:        "a: lazy x",
+	params:      map[string]string{"x": "[ b ]"},
+	expectError: `invalid value for parameter "x": expected object key .*`,
+}, {
+	testName:    "keyword-argument",
+	args:        "a: yaml lazy x",
+	params:      map[string]string{"x": "'b: 1'"},
+	expectError: `lazy at argument 2 can only be used as a value, not as the argument of a keyword`,
+}, {
+	testName: "keyword-value",
+	args:     "a: jsonstr lazy x",
+	params:   map[string]string{"x": "[ b: 1 ]"},
+	expect:   `{"a":"{\"b\":1}"}`,
+}, {
+	testName: "freeze",
+	args:     "a: lazy x b: lazy y c: .[ str 1 true ]",
+	params:   map[string]string{"y": "'two words'"},
+	freeze:   true,
+	expect:   `[ a: lazy x b: 'two words' c: .[ json '"1"' true ] ]`,
+}, {
+	testName: "freeze-keyword",
+	args:     "a: esc lazy x b: jsonstr .[ lazy x lazy y ] c: esc lazy y",
+	params:   map[string]string{"y": "abc"},
+	freeze:   true,
+	expect:   `[ a: esc lazy x b: jsonstr .[ lazy x abc ] c: abc ]`,
+}, {
+	testName: "freeze-values",
+	args:     "num 1.50 null 2 json '\"@x\"' .[ ]",
+	freeze:   true,
+	expect:   "num 1.50\nnull\n2\njson '\"@x\"'\n.[ ]",
+}}
+
+func TestLazy(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range lazyTests {
+		c.Run(test.testName, func(c *qt.C) {
+			args, err := splitScript(test.args)
+			c.Assert(err, qt.Equals, nil)
+			p := newParser(args)
+			p.params = test.params
+			p.freeze = test.freeze
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			var buf bytes.Buffer
+			if test.freeze {
+				err = writeFrozen(&buf, vals)
+			} else {
+				err = newJSONEncoder(&buf).Encode(vals[0])
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(strings.TrimSuffix(buf.String(), "\n"), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestFrozenRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	vals := []interface{}{
+		map[string]interface{}{
+			"":       "",
+			"a b:":   "stdin",
+			"#x":     "it's",
+			"key":    "[",
+			"quotes": `"\`,
+			"n":      json.Number("1e3"),
+			"f":      1.5,
+		},
+		[]interface{}{"true", "12", "a:", "@x", "-", ".[", "]", "lazy", "ulid", "line\nbreak", nil, false},
+	}
+	var buf bytes.Buffer
+	err := writeFrozen(&buf, vals)
+	c.Assert(err, qt.Equals, nil)
+	args, err := splitScript(buf.String())
+	c.Assert(err, qt.Equals, nil)
+	got, err := newParser(args).run()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(got, qt.DeepEquals, vals)
+}
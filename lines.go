@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var linesSkipEmpty = flag.Bool("lines-skip-empty", false, "omit empty lines from the arrays produced by the lines keyword")
+
+// splitLines splits s into lines, which may be terminated
+// by "\n" or "\r\n". A final line terminator does not start
+// another line. If skipEmpty is true, empty lines are omitted.
+func splitLines(s string, skipEmpty bool) []interface{} {
+	lines := []interface{}{}
+	if s == "" {
+		return lines
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" && skipEmpty {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func evalLines(_ []string, index int, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("lines value at argument %d is %s, not string", index, valueKind(v))
+	}
+	return splitLines(s, *linesSkipEmpty), nil
+}
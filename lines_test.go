@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var linesTests = []struct {
+	testName    string
+	args        []string
+	skipEmpty   bool
+	expect      interface{}
+	expectError string
+}{{
+	testName: "lines",
+	args:     []string{"lines", "a\nb c\r\n\nd\n"},
+	expect:   []interface{}{"a", "b c", "", "d"},
+}, {
+	testName:  "skip-empty",
+	args:      []string{"lines", "a\n\n\nb\n\n"},
+	skipEmpty: true,
+	expect:    []interface{}{"a", "b"},
+}, {
+	testName: "no-trailing-newline",
+	args:     []string{"lines", "a\nb"},
+	expect:   []interface{}{"a", "b"},
+}, {
+	testName: "empty",
+	args:     []string{"lines", ""},
+	expect:   []interface{}{},
+}, {
+	testName: "single-newline",
+	args:     []string{"lines", "\n"},
+	expect:   []interface{}{""},
+}, {
+	testName:    "not-string",
+	args:        []string{"lines", "[", "]"},
+	expectError: `lines value at argument 1 is object, not string`,
+}}
+
+func TestLines(t *testing.T) {
+	c := qt.New(t)
+	defer func(skip bool) {
+		*linesSkipEmpty = skip
+	}(*linesSkipEmpty)
+	for _, test := range linesTests {
+		c.Run(test.testName, func(c *qt.C) {
+			*linesSkipEmpty = test.skipEmpty
+			vals, err := newParser(test.args).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
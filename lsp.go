@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// errUnknown is returned by expandArg when checking if the
+// argument can't be expanded without performing I/O.
+var errUnknown = errors.New("value is unknown until the arguments are evaluated")
+
+// checkArg implements expandArg when the parser is checking.
+func (p *parser) checkArg(a string, index int) (string, error) {
+	var expected string
+	switch a {
+	case "lazy":
+		return "", lazyArgError(index)
+	case "stdin":
+		return "", errUnknown
+	case "env":
+		expected = "environment variable name"
+	case "cmd":
+		expected = "command"
+	case "http", "fetch":
+		expected = "URL"
+	default:
+		if len(a) < 2 || a[0] != '@' {
+			return a, nil
+		}
+		if a[1] == '@' {
+			return a[1:], nil
+		}
+		return "", errUnknown
+	}
+	if _, err := p.mustNext(expected); err != nil {
+		return "", err
+	}
+	return "", errUnknown
+}
+
+// unknownValue returns the value of the arguments from start
+// up to the current argument when it can't be determined
+// because the parser is checking.
+func (p *parser) unknownValue(start int) *lazyValue {
+	return &lazyValue{args: p.rawArgs(start, p.index)}
+}
+
+// runLSP implements the lsp subcommand, a language server for
+// -f script files that speaks the Language Server Protocol on
+// r and w. It reports the errors found by the parser, shows the
+// type of the value produced by an argument on hover, and
+// completes keywords. No I/O is performed on behalf of the
+// scripts: values that need it are treated as unknown.
+func runLSP(r io.Reader, w io.Writer) error {
+	s := &lspServer{
+		r:    textproto.NewReader(bufio.NewReader(r)),
+		w:    w,
+		docs: make(map[string]*lspDocument),
+	}
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(msg)
+		rerr, ok := err.(*lspError)
+		if err != nil && !ok {
+			return err
+		}
+		if msg.ID == nil {
+			// Notifications have no response.
+			continue
+		}
+		resp := &lspMessage{
+			ID:     msg.ID,
+			Result: result,
+			Error:  rerr,
+		}
+		if rerr == nil && result == nil {
+			resp.Result = json.RawMessage("null")
+		}
+		if err := s.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// lspMessage holds a JSON-RPC 2.0 request, response or notification.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *lspError) Error() string {
+	return e.Message
+}
+
+// JSON-RPC error codes.
+const (
+	lspInvalidParams  = -32602
+	lspMethodNotFound = -32601
+)
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// Diagnostic severities.
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+)
+
+type lspTextDocument struct {
+	URI     string `json:"uri"`
+	Text    string `json:"text"`
+	Version int    `json:"version"`
+}
+
+type lspDocumentPosition struct {
+	TextDocument lspTextDocument `json:"textDocument"`
+	Position     lspPosition     `json:"position"`
+}
+
+type lspServer struct {
+	r    *textproto.Reader
+	w    io.Writer
+	docs map[string]*lspDocument
+}
+
+// read reads a message, which is preceded by a header
+// giving its length.
+func (s *lspServer) read() (*lspMessage, error) {
+	h, err := s.r.ReadMIMEHeader()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read message header: %v", err)
+	}
+	n, err := strconv.Atoi(h.Get("Content-Length"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", h.Get("Content-Length"))
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(s.r.R, data); err != nil {
+		return nil, fmt.Errorf("cannot read message: %v", err)
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal message: %v", err)
+	}
+	return &msg, nil
+}
+
+func (s *lspServer) write(msg *lspMessage) error {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+func (s *lspServer) handle(msg *lspMessage) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// The full text is sent on each change.
+				"textDocumentSync":   1,
+				"hoverProvider":      true,
+				"completionProvider": map[string]interface{}{},
+			},
+			"serverInfo": map[string]interface{}{
+				"name": "json",
+			},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen", "textDocument/didChange":
+		var params struct {
+			TextDocument   lspTextDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		text := params.TextDocument.Text
+		if n := len(params.ContentChanges); n > 0 {
+			text = params.ContentChanges[n-1].Text
+		}
+		doc := checkScript(text)
+		s.docs[params.TextDocument.URI] = doc
+		return nil, s.publishDiagnostics(params.TextDocument.URI, doc.diagnostics)
+	case "textDocument/didClose":
+		var params lspDocumentPosition
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.publishDiagnostics(params.TextDocument.URI, nil)
+	case "textDocument/hover":
+		var params lspDocumentPosition
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		doc := s.docs[params.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		return doc.hover(params.Position), nil
+	case "textDocument/completion":
+		return keywordCompletions(), nil
+	}
+	if msg.ID == nil {
+		return nil, nil
+	}
+	return nil, &lspError{lspMethodNotFound, fmt.Sprintf("method %q not found", msg.Method)}
+}
+
+func (s *lspServer) publishDiagnostics(uri string, diags []lspDiagnostic) error {
+	if diags == nil {
+		diags = []lspDiagnostic{}
+	}
+	params, err := json.Marshal(map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diags,
+	})
+	if err != nil {
+		return err
+	}
+	return s.write(&lspMessage{
+		Method: "textDocument/publishDiagnostics",
+		Params: params,
+	})
+}
+
+// lspDocument holds the results of checking a script.
+type lspDocument struct {
+	text        string
+	args        []scriptArg
+	classes     []argClass
+	values      map[int]interface{}
+	diagnostics []lspDiagnostic
+}
+
+// argIndexPattern matches the argument index in an error message.
+var argIndexPattern = regexp.MustCompile(`\bargument (\d+)|\bat (\d+)\b`)
+
+// checkScript parses the script held in text, without performing
+// any I/O, and returns the resulting document.
+func checkScript(text string) *lspDocument {
+	doc := &lspDocument{
+		text: text,
+	}
+	args, err := scanScript(text)
+	if err != nil {
+		line := 0
+		if err, ok := err.(*scriptError); ok {
+			line = err.line - 1
+		}
+		pos := lspPosition{Line: line}
+		doc.diagnostics = append(doc.diagnostics, lspDiagnostic{
+			Range:    lspRange{pos, pos},
+			Severity: lspSeverityError,
+			Source:   "json",
+			Message:  err.Error(),
+		})
+		return doc
+	}
+	doc.args = args
+	strs := make([]string, len(args))
+	for i, a := range args {
+		strs[i] = a.text
+	}
+	p := newParser(strs)
+	p.check = true
+	p.freeze = true
+	p.values = make(map[int]interface{})
+	p.warnf = func(format string, arg ...interface{}) {
+		doc.addDiagnostic(lspSeverityWarning, fmt.Sprintf(format, arg...), -1)
+	}
+	if _, err := p.run(); err != nil {
+		doc.addDiagnostic(lspSeverityError, err.Error(), p.index)
+	}
+	doc.classes = p.classes
+	doc.values = p.values
+	return doc
+}
+
+// addDiagnostic adds a diagnostic with the given message. Its
+// position is that of the argument mentioned in the message or,
+// if there is none, the argument with the given index.
+func (doc *lspDocument) addDiagnostic(severity int, msg string, index int) {
+	if m := argIndexPattern.FindStringSubmatch(msg); m != nil {
+		index, _ = strconv.Atoi(m[1] + m[2])
+	}
+	var r lspRange
+	switch {
+	case len(doc.args) == 0:
+	case index < 0 || index >= len(doc.args):
+		// The error is at the end of the arguments.
+		end := doc.position(doc.args[len(doc.args)-1].end)
+		r = lspRange{end, end}
+	default:
+		r = doc.argRange(index)
+	}
+	doc.diagnostics = append(doc.diagnostics, lspDiagnostic{
+		Range:    r,
+		Severity: severity,
+		Source:   "json",
+		Message:  msg,
+	})
+}
+
+// hover returns the hover information for the argument at
+// the given position, or nil if there is none. It shows
+// the TypeScript type of the value that the argument
+// produces or, for an object key, of the member's value.
+func (doc *lspDocument) hover(pos lspPosition) interface{} {
+	offset := doc.offset(pos)
+	index := -1
+	for i, a := range doc.args {
+		if offset >= a.start && offset <= a.end {
+			index = i
+			break
+		}
+	}
+	if index < 0 || index >= len(doc.classes) {
+		return nil
+	}
+	start := index
+	switch {
+	case doc.args[index].text == "key" && doc.classes[index] == classKeyword:
+		start += 2
+	case doc.classes[index] == classKey:
+		start++
+	}
+	v, ok := doc.values[start]
+	if !ok {
+		return nil
+	}
+	t := newInferredType()
+	t.add(v)
+	var buf bytes.Buffer
+	buf.WriteString("```typescript\n")
+	writeTypeScriptType(&buf, t, "")
+	buf.WriteString("\n```")
+	if kw := keywords[doc.args[index].text]; kw != nil && doc.classes[index] == classKeyword {
+		fmt.Fprintf(&buf, "\n\n%s", keywordSyntax(doc.args[index].text, kw))
+	}
+	return map[string]interface{}{
+		"contents": map[string]interface{}{
+			"kind":  "markdown",
+			"value": buf.String(),
+		},
+		"range": doc.argRange(index),
+	}
+}
+
+// keywordCompletions returns the completion items
+// for all the keywords.
+func keywordCompletions() []interface{} {
+	names := []string{"key", "comment"}
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]interface{}, len(names))
+	for i, name := range names {
+		detail := fmt.Sprintf("%q STR", name)
+		if kw := keywords[name]; kw != nil {
+			detail = keywordSyntax(name, kw)
+		}
+		items[i] = map[string]interface{}{
+			"label": name,
+			// The completion item kind for keywords.
+			"kind":   14,
+			"detail": detail,
+		}
+	}
+	return items
+}
+
+func (doc *lspDocument) argRange(index int) lspRange {
+	a := doc.args[index]
+	return lspRange{doc.position(a.start), doc.position(a.end)}
+}
+
+// position returns the position of the given byte offset in the
+// document. As LSP requires, characters are counted in UTF-16
+// code units.
+func (doc *lspDocument) position(offset int) lspPosition {
+	var pos lspPosition
+	for _, r := range doc.text[:offset] {
+		if r == '\n' {
+			pos.Line++
+			pos.Character = 0
+			continue
+		}
+		pos.Character += len(utf16.Encode([]rune{r}))
+	}
+	return pos
+}
+
+// offset returns the byte offset of the given position.
+func (doc *lspDocument) offset(pos lspPosition) int {
+	line := 0
+	i := 0
+	for line < pos.Line {
+		j := strings.IndexByte(doc.text[i:], '\n')
+		if j < 0 {
+			return len(doc.text)
+		}
+		i += j + 1
+		line++
+	}
+	for n := 0; n < pos.Character && i < len(doc.text); {
+		r, size := utf8.DecodeRuneInString(doc.text[i:])
+		if r == '\n' {
+			break
+		}
+		n += len(utf16.Encode([]rune{r}))
+		i += size
+	}
+	return i
+}
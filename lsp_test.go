@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var checkScriptTests = []struct {
+	testName          string
+	script            string
+	expectDiagnostics []lspDiagnostic
+}{{
+	testName: "ok",
+	script:   "a: 1\nb: [ c: true ]\n",
+}, {
+	testName: "no-io",
+	// None of these are evaluated, so there are no
+	// errors even though they would fail.
+	script: "a: cmd 'false'\nb: num @/nonexistent\nc: jsonstr .[ stdin ]\nd: float http nothing\ne: ulid\nf: lazy x\n",
+}, {
+	testName: "syntax-error",
+	script:   "a: 1\nb: ]\n",
+	expectDiagnostics: []lspDiagnostic{{
+		Range:    lspRange{lspPosition{1, 3}, lspPosition{1, 4}},
+		Severity: lspSeverityError,
+		Source:   "json",
+		Message:  "unexpected argument ] at 3, expected value",
+	}},
+}, {
+	testName: "keyword-error",
+	script:   "# comment\na: num 'bad number'\n",
+	expectDiagnostics: []lspDiagnostic{{
+		Range:    lspRange{lspPosition{1, 7}, lspPosition{1, 19}},
+		Severity: lspSeverityError,
+		Source:   "json",
+		Message:  `invalid number "bad number" at argument 2`,
+	}},
+}, {
+	testName: "unexpected-end",
+	script:   "a: [ b: 1\n",
+	expectDiagnostics: []lspDiagnostic{{
+		Range:    lspRange{lspPosition{0, 9}, lspPosition{0, 9}},
+		Severity: lspSeverityError,
+		Source:   "json",
+		Message:  "unexpected end of arguments (expected key or ])",
+	}},
+}, {
+	testName: "unterminated-quote",
+	script:   "a: 1\nb: 'x\n",
+	expectDiagnostics: []lspDiagnostic{{
+		Range:    lspRange{lspPosition{1, 0}, lspPosition{1, 0}},
+		Severity: lspSeverityError,
+		Source:   "json",
+		Message:  "2: unterminated ' quote",
+	}},
+}, {
+	testName: "utf16",
+	script:   "é😀: num x",
+	expectDiagnostics: []lspDiagnostic{{
+		Range:    lspRange{lspPosition{0, 9}, lspPosition{0, 10}},
+		Severity: lspSeverityError,
+		Source:   "json",
+		Message:  `invalid number "x" at argument 2`,
+	}},
+}}
+
+func TestCheckScript(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range checkScriptTests {
+		c.Run(test.testName, func(c *qt.C) {
+			doc := checkScript(test.script)
+			c.Assert(doc.diagnostics, qt.DeepEquals, test.expectDiagnostics)
+		})
+	}
+}
+
+var hoverTests = []struct {
+	testName string
+	pos      lspPosition
+	expect   string
+}{{
+	testName: "number",
+	pos:      lspPosition{0, 3},
+	expect:   "```typescript\nnumber\n```",
+}, {
+	testName: "key",
+	pos:      lspPosition{1, 0},
+	expect:   "```typescript\n{\n\tc: string;\n\td: boolean[];\n}\n```",
+}, {
+	testName: "keyword",
+	pos:      lspPosition{2, 4},
+	expect:   "```typescript\nstring\n```\n\n\"jsonstr\" value",
+}, {
+	testName: "key-keyword",
+	pos:      lspPosition{3, 0},
+	expect:   "```typescript\nunknown\n```",
+}, {
+	testName: "unknown",
+	pos:      lspPosition{3, 8},
+	expect:   "```typescript\nunknown\n```\n\n\"cmd\" STR",
+}}
+
+func TestHover(t *testing.T) {
+	c := qt.New(t)
+	doc := checkScript("a: 1\nb: [ c: x d: .[ true ] ]\ne: jsonstr .[ 1 ]\nkey f cmd date\n")
+	c.Assert(doc.diagnostics, qt.HasLen, 0)
+	for _, test := range hoverTests {
+		c.Run(test.testName, func(c *qt.C) {
+			h := doc.hover(test.pos).(map[string]interface{})
+			c.Assert(h["contents"].(map[string]interface{})["value"], qt.Equals, test.expect)
+		})
+	}
+	c.Run("none", func(c *qt.C) {
+		// There is no argument after the end of the script.
+		c.Assert(doc.hover(lspPosition{10, 0}), qt.IsNil)
+	})
+}
+
+func TestRunLSP(t *testing.T) {
+	c := qt.New(t)
+	var in bytes.Buffer
+	send := func(id int, method string, params interface{}) {
+		msg := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  method,
+			"params":  params,
+		}
+		if id > 0 {
+			msg["id"] = id
+		}
+		data, err := json.Marshal(msg)
+		c.Assert(err, qt.Equals, nil)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	}
+	doc := map[string]interface{}{
+		"uri": "file:///x.args",
+	}
+	send(1, "initialize", map[string]interface{}{})
+	send(0, "initialized", map[string]interface{}{})
+	send(0, "textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{
+			"uri":  "file:///x.args",
+			"text": "a: [ b: 1\n",
+		},
+	})
+	send(0, "textDocument/didChange", map[string]interface{}{
+		"textDocument": doc,
+		"contentChanges": []interface{}{
+			map[string]interface{}{"text": "a: num 2\n"},
+		},
+	})
+	send(2, "textDocument/hover", map[string]interface{}{
+		"textDocument": doc,
+		"position":     lspPosition{0, 4},
+	})
+	send(3, "textDocument/completion", map[string]interface{}{
+		"textDocument": doc,
+		"position":     lspPosition{0, 3},
+	})
+	send(4, "workspace/symbol", map[string]interface{}{})
+	send(5, "shutdown", nil)
+	send(0, "exit", nil)
+	var out bytes.Buffer
+	err := runLSP(&in, &out)
+	c.Assert(err, qt.Equals, nil)
+
+	r := textproto.NewReader(bufio.NewReader(&out))
+	var msgs []map[string]interface{}
+	for {
+		h, err := r.ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, qt.Equals, nil)
+		n, err := strconv.Atoi(h.Get("Content-Length"))
+		c.Assert(err, qt.Equals, nil)
+		data := make([]byte, n)
+		_, err = io.ReadFull(r.R, data)
+		c.Assert(err, qt.Equals, nil)
+		var msg map[string]interface{}
+		c.Assert(json.Unmarshal(data, &msg), qt.Equals, nil)
+		msgs = append(msgs, msg)
+	}
+	c.Assert(msgs, qt.HasLen, 7)
+
+	c.Assert(msgs[0]["id"], qt.Equals, 1.0)
+	caps := msgs[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	c.Assert(caps["hoverProvider"], qt.Equals, true)
+
+	c.Assert(msgs[1]["method"], qt.Equals, "textDocument/publishDiagnostics")
+	diags := msgs[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	c.Assert(diags, qt.HasLen, 1)
+	c.Assert(diags[0].(map[string]interface{})["message"], qt.Matches, "unexpected end of arguments.*")
+	c.Assert(msgs[2]["params"].(map[string]interface{})["diagnostics"], qt.DeepEquals, []interface{}{})
+
+	c.Assert(msgs[3]["id"], qt.Equals, 2.0)
+	hover := msgs[3]["result"].(map[string]interface{})
+	c.Assert(hover["contents"].(map[string]interface{})["value"], qt.Equals, "```typescript\nnumber\n```\n\n\"num\" arg")
+
+	c.Assert(msgs[4]["id"], qt.Equals, 3.0)
+	var labels []string
+	for _, item := range msgs[4]["result"].([]interface{}) {
+		labels = append(labels, item.(map[string]interface{})["label"].(string))
+	}
+	c.Assert(labels, qt.HasLen, len(keywords)+2)
+	c.Assert(strings.Join(labels, " "), qt.Contains, " jsonstr ")
+
+	c.Assert(msgs[5]["id"], qt.Equals, 4.0)
+	c.Assert(msgs[5]["error"], qt.DeepEquals, map[string]interface{}{
+		"code":    -32601.0,
+		"message": `method "workspace/symbol" not found`,
+	})
+
+	c.Assert(msgs[6], qt.DeepEquals, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      5.0,
+		"result":  nil,
+	})
+}
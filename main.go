@@ -2,18 +2,30 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
+	"crypto/sha256"
 	"flag"
 	"fmt"
-	"math"
+	"hash"
+	"io"
+	"net/http"
 	"os"
-	"strconv"
-	"strings"
+	"time"
 )
 
-var indent = flag.Bool("indent", false, "indent JSON output; by default it is printed compactly")
+var (
+	indent = flag.Bool("indent", false, "indent JSON output; by default it is printed compactly")
+	format = flag.String("format", "json", "output format; one of "+formatNames())
+	output = flag.String("o", "", "write output to the named file instead of standard output")
+)
 
 func main() {
+	if jsBuild {
+		// When compiled to WebAssembly, there is no command
+		// line; the parser is made available to JavaScript instead.
+		serveJS()
+		return
+	}
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "json [flags] [arg...]\n")
 		flag.PrintDefaults()
@@ -34,16 +46,20 @@ For example:
 	{"bar":{"x":657},"foo":45,"y":[3,5,6]}
 
 The grammar is as follows (in BNF notation as used by https://golang.org/ref/spec).
-All tokens represent exactly one argument on the command line. STR is any string;
-KEY is a string with a ":" suffix.
+All tokens represent exactly one argument on the command line. STR is any argument;
+KEY is an argument with a ":" suffix; ATFILE is an argument with a "@" prefix,
+which stands for the contents of the named file. Only the most common keywords
+are shown; "json -grammar ebnf" prints the complete grammar.
 
 	args = { value } | keyValues
-	value = "null" | "true" | "false" | typeAssertion | object | array | STR
-	typeAssertion = ( "str" | "num" | "bool" | "jsonstr" ) value
+	value = "null" | "true" | "false" | typeAssertion | labels | object | array | "-" | ATFILE | STR
+	typeAssertion = "str" STR | ( "num" | "bool" ) arg | "jsonstr" value
+	labels = "labels" arg
 	object = "[" keyValues "]"
-	keyValues = { key value }
+	keyValues = { [ "comment" STR ] key value }
 	key = KEY | "key" STR
 	array = ".[" { value } "]"
+	arg = "stdin" | "env" STR | "cmd" STR | "http" STR | "fetch" STR | ATFILE | STR
 
 Note that if the top argument looks like an object key (it ends with a colon (:)),
 the entire command line represents a single object; otherwise, the arguments
@@ -76,7 +92,8 @@ The possible assertions are:
 			{"a\"b": "hello"}
 	str
 		The following argument is treated as a string. It must be present, but can contain
-		any value. For example:
+		any value. It's taken literally, so @PATH, stdin, env, cmd, http and fetch
+		have no special meaning there. For example:
 
 			$ json str [
 			"["
@@ -87,6 +104,28 @@ The possible assertions are:
 			$ json num bad
 			json: invalid number "bad" at argument 1
 
+	int
+		The following argument is treated as a 64-bit integer, which
+		is printed without any exponent or fraction. Unlike num, numbers
+		with a fraction or exponent and numbers that are out of range are
+		rejected, so it is safe to use for identifiers such as database IDs.
+		For example:
+
+			$ json id: int 9007199254740993
+			{"id":9007199254740993}
+			$ json id: int 1e3
+			json: invalid integer "1e3" at argument 2
+
+	float
+		The following argument is treated as a floating point number,
+		which is printed in the shortest form that represents it exactly.
+		The number may be preceded by a format starting with %%, as
+		used by Go's fmt package, with a verb of f, e or g, which
+		controls how the number is printed. For example:
+
+			$ json price: float %%.2f 3.14159 ratio: float 1.50
+			{"price":3.14,"ratio":1.5}
+
 	bool
 		The following argument is is treated as a bool.
 		It must be one of 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False.
@@ -96,6 +135,235 @@ The possible assertions are:
 			$ json bool 0
 			false
 
+	numrange
+		The following two arguments hold lower and upper bounds
+		and the value after them must be a number within those
+		bounds. Bounds are inclusive; a "(" prefix on the lower
+		bound or a ")" suffix on the upper bound makes it exclusive.
+		For example:
+
+			$ json port: numrange 1 65535 70000
+			json: number 70000 at argument 4 is out of range [1, 65535]
+			$ json ratio: numrange 0 '1)' 0.5
+			{"ratio":0.5}
+
+	maxlen, maxbytes
+		The following argument holds a maximum length and the
+		argument after it holds a string no longer than that,
+		which is taken literally even if it looks like a number.
+		For maxlen, the length is in characters; for maxbytes,
+		it is the size in bytes of the UTF-8 encoding and may
+		have a unit suffix such as KB or KiB.
+		For example:
+
+			$ json label: maxlen 5 abcdef
+			json: string at argument 3 has 6 characters, more than the maximum of 5
+			$ json body: maxbytes 1KB hello
+			{"body":"hello"}
+
+	email
+		The following value must be a string holding an email
+		address, which is included with its domain in lower case.
+		For example:
+
+			$ json to: email Jo.Bloggs@Example.COM
+			{"to":"Jo.Bloggs@example.com"}
+
+	phone
+		The following argument holds an ISO 3166-1 region code
+		and the argument after it holds a phone number, which is
+		included in E.164 form. The number is always taken as
+		a string, so a leading zero is kept. Spaces, dashes,
+		dots and parentheses are ignored. A number that does not
+		start with + or 00 is taken to be a national number
+		in the region, and any leading trunk prefix is removed.
+		For example:
+
+			$ json tel: phone GB '07700 900123' fax: phone us '+1 (212) 555-0100'
+			{"fax":"+12125550100","tel":"+447700900123"}
+
+	time
+		The following value must be a string holding a time, or
+		a number of seconds since the Unix epoch. The time can be
+		in RFC 3339, RFC 1123 or a number of other common formats,
+		such as 2006-01-02 or 2006-01-02 15:04:05; times without
+		a time zone are taken to be in UTC. It is included as an
+		RFC 3339 string in UTC. For example:
+
+			$ json created: time 'Mon, 02 Jan 2006 15:04:05 GMT' day: time 2006-01-02
+			{"created":"2006-01-02T15:04:05Z","day":"2006-01-02T00:00:00Z"}
+			$ json t: time 1136214245.5
+			{"t":"2006-01-02T15:04:05.5Z"}
+
+	esc
+		The following value must be a string, in which backslash
+		escapes such as \n, \t, \\ and \u00e9 are interpreted as in JSON.
+		\xHH and \UHHHHHHHH also stand for the code point with
+		the given hexadecimal value. This avoids the need to pass
+		newlines and other special characters through the shell.
+		There are no named escapes such as emoji short codes: use
+		the code point instead, as in \U0001F600 or \ud83d\ude00.
+		For example:
+
+			$ json msg: esc 'line one\nline two \u2713'
+			{"msg":"line one\nline two ✓"}
+
+	base64, b64d
+		The following argument is taken as a string, even if it
+		looks like a number or a keyword. For base64, it is
+		included base64 encoded, using the standard alphabet with
+		padding; for b64d, it is decoded from base64, in either the
+		standard or URL-safe alphabet and with or without padding,
+		and must decode to UTF-8 text. For example:
+
+			$ json auth: base64 user:secret
+			{"auth":"dXNlcjpzZWNyZXQ="}
+			$ json b64d aGVsbG8
+			"hello"
+
+	urlval, urlparts
+		The following value must be a string holding an absolute
+		URL. It is normalized by converting the scheme and host
+		to lower case and resolving any . and .. path segments.
+		For urlval, the normalized URL is included as a string;
+		for urlparts, an object holding its components is
+		included instead, with the query parsed as for qs.
+		For example:
+
+			$ json api: urlval HTTPS://Example.COM/v1/../v2/
+			{"api":"https://example.com/v2/"}
+			$ json urlparts 'http://db:5432/x?a=1#top'
+			{"fragment":"top","host":"db","path":"/x","port":5432,"query":{"a":"1"},"scheme":"http"}
+
+	hostname, dnslabel
+		The following value must be a string holding a host name
+		or, for dnslabel, a single DNS label, that is valid according
+		to RFC 1123. Each label holds 1 to 63 letters, digits and
+		hyphens and does not start or end with a hyphen; a host
+		name is at most 253 characters long.
+		For example:
+
+			$ json name: dnslabel web_1
+			json: invalid DNS label "web_1" at argument 2: label "web_1" contains invalid character '_'
+
+	color
+		The following value must be a string holding a color in
+		#rgb, #rrggbb or rgb(r, g, b) form. It is included in the
+		form chosen by the -color-format flag: #rrggbb in lower
+		case (the default) or rgb(r, g, b).
+		For example:
+
+			$ json fg: color '#F0A' bg: color 'rgb(30, 144, 255)'
+			{"bg":"#1e90ff","fg":"#ff00aa"}
+
+	latlon, geopoint, geobbox
+		The following two arguments hold a latitude and a longitude
+		in degrees, which are checked to be in range. For latlon, an
+		object holding lat and lon members is included; for geopoint,
+		a GeoJSON Point geometry. For geobbox, the following four
+		arguments hold the west, south, east and north edges of a
+		bounding box, in the order of a GeoJSON bbox, and a GeoJSON
+		Polygon geometry is included.
+		For example:
+
+			$ json loc: latlon 51.5 -0.12
+			{"loc":{"lat":51.5,"lon":-0.12}}
+			$ json geometry: geopoint 51.5 -0.12
+			{"geometry":{"coordinates":[-0.12,51.5],"type":"Point"}}
+			$ json geobbox -1 50 1 52
+			{"bbox":[-1,50,1,52],"coordinates":[[[-1,50],[1,50],[1,52],[-1,52],[-1,50]]],"type":"Polygon"}
+
+	lines
+		The following value must be a string, which is split into
+		lines to make an array of strings. A final newline does not
+		start another line. When the -lines-skip-empty flag is given,
+		empty lines are omitted. This is useful with stdin or @ file
+		references.
+		For example:
+
+			$ ls | json files: lines stdin
+			{"files":["README.md","main.go"]}
+
+	iso-country, iso-lang, iso-currency
+		The following value must be a string holding an ISO 3166-1
+		alpha-2 country code, an ISO 639-1 language code or an
+		ISO 4217 currency code respectively. Case is ignored;
+		the code is included in its canonical case (upper case
+		for countries and currencies, lower case for languages).
+		For example:
+
+			$ json country: iso-country gb lang: iso-lang EN price: [ currency: iso-currency usd ]
+			{"country":"GB","lang":"en","price":{"currency":"USD"}}
+			$ json iso-currency ABC
+			json: invalid currency code "ABC" at argument 1
+
+	ulid, ksuid
+		A new ULID or KSUID is generated. Both hold a timestamp
+		followed by random bits, so identifiers generated later
+		sort after earlier ones. When the -seed flag is given,
+		the same identifiers are generated on every run.
+		For example:
+			$ json -seed 1 id: ulid
+			{"id":"01DXF6DT00ABYZR1S1G9JMY5HZ"}
+
+	fake
+		The following argument names a kind of fake data, which
+		is generated randomly: name, firstname, lastname, username,
+		email, company, address, city, country, word, sentence,
+		ipv4, ipv6 or url. Email addresses and URLs use the example
+		domains and IPv6 addresses use the documentation prefix.
+		When the -seed flag is given, the same data is generated
+		on every run.
+		For example:
+			$ json -seed 1 name: fake name email: fake email
+			{"email":"fatima.kowalski@example.net","name":"Amara Evans"}
+
+	choice, wchoice
+		The following value must be an array, and one of its
+		elements is chosen at random. For wchoice, each element
+		holds a weight and a value separated by a colon, and each
+		value is chosen with a probability proportional to its
+		weight; the value is a number if it looks like one and a
+		string otherwise. When the -seed flag is given, the same
+		choices are made on every run.
+		For example:
+			$ json -seed 1 level: wchoice .[ 3:info 1:error ] host: choice .[ a b c ]
+			{"host":"a","level":"info"}
+
+	base64file
+		The following argument names a file whose contents are
+		base64 encoded (with padding, using the standard alphabet)
+		and included as a string. This is useful for binary data
+		such as images. Like file, the argument is never treated
+		as an @ reference. For example:
+
+			$ json name: logo.png data: base64file ./logo.png
+
+	file
+		The following argument names a file whose contents are
+		included exactly as a string. The argument is never
+		treated as an @ reference, so it is safe to use with
+		names that start with @. For example:
+
+			$ json body: file ./payload.txt
+
+	glob
+		The following value must be a string holding a file name
+		pattern, which is expanded to an array holding the names of
+		the matching files in sorted order. The pattern syntax is
+		that of Go's filepath.Match, so it does not depend on the shell.
+		For example:
+
+			$ json configs: glob 'conf.d/*.json'
+			{"configs":["conf.d/a.json","conf.d/b.json"]}
+
+	jsonfile
+		The following argument names a file holding a JSON
+		value, which is included. Like file, the argument is
+		never treated as an @ reference. For example:
+
+			$ json cfg: jsonfile ./config.json
+
 	jsonstr
 		The following value is marshaled as JSON and used as a string value.
 
@@ -110,213 +378,788 @@ The possible assertions are:
 		For example:
 			$  json [ one: 1 two: json '["two", 2]' ]
 			{"one":1,"two":["two",2]}
+
+	yaml
+		The following argument is treated as a YAML document
+		and included as the equivalent JSON. Map keys that are
+		not strings are converted to their textual form.
+		For example:
+			$ json cfg: yaml 'ports: [80, 443]'
+			{"cfg":{"ports":[80,443]}}
+
+	toml
+		The following argument is treated as a TOML document
+		and included as the equivalent JSON object. Date-times
+		are converted to RFC 3339 strings.
+		For example:
+			$ json server: toml 'port = 8080'
+			{"server":{"port":8080}}
+
+	csv
+		The following argument is treated as CSV with a header row
+		and included as an array holding an object for each record,
+		keyed by the column names. All values are strings.
+		For example:
+			$ json rows: csv $'name,age\nbob,42'
+			{"rows":[{"age":"42","name":"bob"}]}
+
+	xml
+		The following argument is treated as an XML document and
+		included as an object with a member named after the root
+		element. Elements with only text become strings; others
+		become objects holding attributes (prefixed with @), child
+		elements (as arrays when repeated) and text (as #text).
+		For example:
+			$ json xml '<a id="1"><b>x</b><b>y</b></a>'
+			{"a":{"@id":"1","b":["x","y"]}}
+
+	json5
+		The following argument is treated as a JSON5 document,
+		which can contain comments, trailing commas, unquoted keys
+		and single-quoted strings, and included as the equivalent JSON.
+		For example:
+			$ json cfg: json5 "{port: 0x50 /* http */, tags: ['a',],}"
+			{"cfg":{"port":80,"tags":["a"]}}
+
+	hjson
+		The following argument is treated as an Hjson document
+		and included as the equivalent JSON. The braces around
+		a top level object may be omitted.
+		For example:
+			$ json cfg: hjson $'# server\nport: 80\nname: web server'
+			{"cfg":{"name":"web server","port":80}}
+
+	qs
+		The following argument is treated as a URL query string
+		and included as an object. Keys that occur more than once
+		hold an array of their values. All values are strings.
+		For example:
+			$ json params: qs 'a=1&b=two&b=three'
+			{"params":{"a":"1","b":["two","three"]}}
+
+	ndjson
+		The following argument is treated as newline-delimited JSON,
+		with one JSON value on each line, and included as an array
+		holding the values. Use "ndjson stdin" to wrap a stream read
+		from standard input in an envelope. For example:
+			$ printf '{"id":1}\n{"id":2}\n' | json kind: batch items: ndjson stdin
+			{"items":[{"id":1},{"id":2}],"kind":"batch"}
+
+	msgpack, cbor
+		The following argument holds hex or base64 encoded MessagePack
+		or CBOR data, which is decoded and included as the equivalent JSON.
+		A "hex:" or "base64:" prefix can be used to resolve ambiguity;
+		otherwise hex is assumed if the argument is valid hex.
+		Binary data is included as base64 strings.
+		For example:
+			$ json msgpack 81a161c3
+			{"a":true}
+
+The labels keyword parses the following argument as a Prometheus-style
+label set (a metric name and/or double-quoted label values in braces)
+and produces an object holding the name and labels. For example:
+
+	$ json labels 'up{job="api",env="prod"}'
+	{"labels":{"env":"prod","job":"api"},"name":"up"}
+
+An argument of the form @PATH in a value position, including the
+argument of a keyword other than str, stands for the contents of the named file,
+with any single trailing newline removed. A file's contents are
+treated as a string unless a keyword says otherwise. Use @@ for a
+literal leading @, or the file keyword to read a file's contents
+without removing the newline. The argument of str is always taken
+literally, so str can be used to include a value that starts with @.
+For example:
+
+	$ json name: @name.txt cfg: yaml @cfg.yaml port: num @port.txt
+
+Files named by @PATH arguments and by the file, base64file and
+jsonfile keywords are read concurrently, several at a time, before
+the arguments are interpreted, so that documents assembled from
+many files are quick to make. The values are still produced in order.
+
+The argument stdin, or - in a value position, stands for the
+contents of standard input, with any single trailing newline
+removed. Like a file's contents, it's treated as a string unless
+a keyword says otherwise, and stdin can also be used as the
+argument of a keyword. Standard input is read only once, so it
+can be used more than once. For example:
+
+	$ curl -s https://example.com/status | json wrapper: [ payload: json stdin ]
+
+The argument env followed by the name of an environment variable
+stands for the value of that variable; it's an error if the
+variable is not set. Like stdin, it can be used as the argument
+of a keyword, and its value is a string unless a keyword says
+otherwise. For example:
+
+	$ json port: num env PORT home: env HOME
+
+The argument cmd followed by a command stands for the standard
+output of that command. The command is split into words at white
+space and run directly, not by a shell. The output is used exactly
+unless the -cmd-trim flag is given, in which case trailing newlines
+are removed. Like env, it can be used as the argument of a keyword,
+except for str, env, cmd, http and fetch, whose arguments are always
+taken literally, so a command never runs unless cmd is used where a
+value is expected. For example:
+
+	$ json -cmd-trim rev: cmd 'git rev-parse HEAD' pods: json cmd 'kubectl get pods -o json'
+
+The argument http or fetch followed by a URL stands for the body of
+the response to a GET request for that URL. In a value position,
+the body must hold JSON, which is included; as the argument of a
+keyword, it's used as a string, so yaml http URL decodes the body
+as YAML. It's an error if the response status is not 2xx or the
+request takes longer than the -http-timeout flag (30s by default).
+For example:
+
+	$ json user: http https://api.example.com/users/1 cfg: yaml fetch https://example.com/cfg.yaml
+
+When the -memo flag names a directory, the output of each command
+run by cmd and the body of each response fetched by http or fetch
+are cached there, keyed by the command and the directory it runs in,
+or by the URL. A cached result is used instead of repeating the work
+until it's older than the -memo-ttl flag (1h by default), so scripts
+that are run repeatedly don't redo expensive lookups. Failures are
+not cached. For example:
+
+	$ json -memo ~/.cache/json -memo-ttl 24h pods: json cmd 'kubectl get pods -o json'
+
+The argument counter, optionally followed by a name, stands for the
+next value of the named counter: 1 the first time it's used, then 2,
+and so on. If counter is followed by a key, a closing ] or nothing,
+the counter named default is used. When the -counter-file flag is given, the most recent value of each
+counter is kept in that file, so that counters continue to increase
+across runs. For example:
+
+	$ json .[ [ id: counter rec ] [ id: counter rec ] [ id: counter ] ]
+	[{"id":1},{"id":2},{"id":1}]
+
+The -f flag names a file holding arguments, which are used before
+any arguments on the command line. Arguments are separated by white
+space, including newlines, and may be quoted as in a POSIX shell:
+single quotes preserve everything between them, and double quotes
+and backslashes can be used as usual. A # at the start of an argument
+starts a comment that extends to the end of the line. For example,
+given a file payload.args holding:
+
+	# The web server deployment.
+	name: 'web server'
+	spec: [
+		replicas: 3  # enough for now
+	]
+
+then:
+
+	$ json -f payload.args
+	{"name":"web server","spec":{"replicas":3}}
+
+If the first argument is "lsp", the json command instead runs a
+language server for such files, speaking the Language Server
+Protocol on standard input and output, so that editors can check
+stored argument files. It reports the errors and warnings found by
+the parser, shows the type of the value produced by an argument
+(or of an object member's value, over its key) on hover, and
+completes keywords. Nothing is run, read or fetched while checking:
+the values of keywords that would do so are unknown.
+
+The -args0 flag causes arguments to be read from standard input,
+each terminated by a NUL byte, as produced by find -print0 or
+printf '%%s\0'. They're used after any arguments read with -f and
+before those on the command line. This avoids quoting problems and
+limits on the length of the command line when another program
+generates the arguments. For example:
+
+	$ printf '%%s\0' name: 'web server' port: 80 | json -args0
+	{"name":"web server","port":80}
+
+The -base flag names a file holding a JSON or YAML document that
+each value is applied to as a JSON merge patch (RFC 7396): object
+members are merged recursively, a null member deletes the member
+from the base document, and any other value replaces it. For example,
+given a file base.yaml holding:
+
+	name: web
+	spec:
+	  replicas: 1
+	  debug: true
+
+then:
+
+	$ json -base base.yaml spec: [ replicas: 3 debug: null ]
+	{"name":"web","spec":{"replicas":3}}
+
+The -merge flag names a file holding a JSON or YAML object to
+be deep-merged into the base document in the same way. It may be
+repeated to layer several files, each overriding the ones before it,
+and values on the command line are applied last. With no arguments,
+the merged files themselves are printed. For example, given files
+defaults.json holding {"port": 80, "tls": {"enabled": false}} and
+prod.json holding {"tls": {"enabled": true}}, then:
+
+	$ json -merge defaults.json -merge prod.json port: 443
+	{"port":443,"tls":{"enabled":true}}
+
+The argument "lazy NAME" stands for the value of the parameter
+NAME, which is set with the -param flag as NAME=ARGS, where ARGS
+holds arguments in the same syntax as a -f file. This allows a
+stored template to be filled in later. With the -freeze flag,
+the values are printed as arguments suitable for -f instead, with
+each value that depends on a parameter with no value left in
+place, so that a template can be partially evaluated. A parameter
+can only be used where a value is expected: unlike env, lazy can't
+be used as the argument of a keyword, so yaml lazy NAME is an error,
+but a keyword that takes a value, such as jsonstr lazy NAME, can
+be applied to it. For example:
+
+	$ json -freeze -param "name='web server'" name: lazy name port: lazy port > tmpl
+	$ cat tmpl
+	[ name: 'web server' port: lazy port ]
+	$ json -f tmpl -param 'port=num 80'
+	{"name":"web server","port":80}
+
+With the -ref-resolve flag, each object holding a $ref member
+(as used by JSON Reference and JSON Schema) is replaced by the value
+it refers to, so that documents can be flattened for consumers that
+do not support $ref. A reference such as "#/definitions/port" refers
+to part of the same value, and one such as "common.json#/port" refers
+to part of a JSON or YAML file. File names are relative to the current
+directory or, for references within a file, to the directory holding
+that file. For example:
+
+	$ json -ref-resolve defs: [ port: [ type: integer ] ] properties: [ port: [ \$ref: '#/defs/port' ] ]
+	{"defs":{"port":{"type":"integer"}},"properties":{"port":{"type":"integer"}}}
+
+The -bundle flag is like -ref-resolve, except that rather than
+being inlined, each value that a reference to a file refers to is
+copied into the $defs member of the value, and the reference
+rewritten to refer to the copy. This preserves reuse while making
+the document self-contained. References within the value itself
+are left alone. For example, given a file common.json holding
+{"port": {"type": "integer"}}, then:
+
+	$ json -bundle a: [ \$ref: common.json#/port ] b: [ \$ref: common.json#/port ]
+	{"$defs":{"port":{"type":"integer"}},"a":{"$ref":"#/$defs/port"},"b":{"$ref":"#/$defs/port"}}
+
+If the first argument is "convert", the json command instead
+converts a document between formats. It reads the document from
+the file named by its argument, or from standard input if there is
+none, and writes it in another format. The -from flag gives the
+input format, one of cbor, csv, hjson, json, json5, msgpack, ndjson,
+query, toml, xml and yaml, and the -to flag gives the output format,
+which can be any format accepted by -format. Both default to json.
+Flags such as -indent and -o given before "convert" also apply.
+For example:
+
+	$ printf 'name: web\nports: [80, 443]\n' | json convert -from yaml
+	{"name":"web","ports":[80,443]}
+
+The -openapi flag names a JSON or YAML OpenAPI document, and the
+-operation flag names one of its operations by operationId. Each value
+must then match the JSON Schema of the operation's request body, so
+that API test payloads are checked against the contract. Most JSON
+Schema validation keywords are supported, as are references within
+the document. With no arguments, a skeleton of the expected request
+body is printed instead. For example, given a file api.yaml in which
+operation createPet requires a name of at least one character and
+allows an integer age of at least 0:
+
+	$ json -openapi api.yaml -operation createPet
+	{"age":0,"name":"x"}
+	$ json -openapi api.yaml -operation createPet nme: rex age: -1
+	json: value does not match request body of operation "createPet": /: missing required member "name"; /age: -1 is less than the minimum of 0; /nme: member is not allowed
+
+If the first argument is "skeleton", the json command instead prints
+a value that matches the JSON Schema in the JSON or YAML file named
+by its argument, using any default or example values in the schema.
+With the -required flag, only required object members are included.
+With -format jsonc, each member is preceded by its description and
+optional members are marked, giving a template to fill in; the
+skeleton can also be used with -base, so that only the members that
+differ need be given on the command line. For example, given a file
+pet.yaml holding a schema for an object with a required name
+and optional age and tags:
+
+	$ json skeleton pet.yaml
+	{"age":0,"name":"","tags":[]}
+	$ json -base <(json skeleton -required pet.yaml) name: rex
+	{"name":"rex"}
+
+The -compact-arrays flag causes json and jsonc output to be
+indented, except that arrays holding no objects or arrays are
+printed on a single line, as in many hand-maintained configuration
+files. For example:
+
+	$ json -compact-arrays name: web ports: .[ 80 443 ]
+	{
+		"name": "web",
+		"ports": [80, 443]
+	}
+
+By default, each value is followed by a newline. The -separator
+flag gives text to print between values instead, and with
+-final-newline=false no newline is printed after the last value.
+These flags cannot be used with binary output formats. For example:
+
+	$ json -separator , 1 2 3
+	1,2,3
+
+A comment directive before an object key attaches a comment to that
+member. Comments are ignored by all output formats except jsonc,
+which prints them before the member. For example:
+
+	$ json -format jsonc comment 'port number' port: 8080 host: localhost
+	{
+		"host": "localhost",
+		// port number
+		"port": 8080
+	}
+
+The -emit flag, which may be repeated, also writes the output in
+another format to a file, specified as FORMAT=FILE, so that several
+artifacts are made from a single evaluation of nondeterministic values.
+Any format accepted by -format can be used, including yaml, and schema,
+which writes a JSON Schema inferred from all the values. For example:
+
+	$ json -emit yaml=out.yaml -emit schema=out.schema.json id: ulid
+	{"id":"01J9ZQ7X3K2V4M8N6P0R5T1W3Y"}
+	$ cat out.yaml
+	id: 01J9ZQ7X3K2V4M8N6P0R5T1W3Y
 `)
 		os.Exit(2)
 	}
 
 	flag.Parse()
-	exprs, err := parse(flag.Args())
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "json: %s\n", err)
-		os.Exit(1)
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "convert":
+			runConvert(flag.Args()[1:])
+			return
+		case "skeleton":
+			runSkeleton(flag.Args()[1:])
+			return
+		case "lsp":
+			if err := runLSP(os.Stdin, os.Stdout); err != nil {
+				fatalf("%v", err)
+			}
+			return
+		}
 	}
-	w := bufio.NewWriter(os.Stdout)
-	defer w.Flush()
-	enc := json.NewEncoder(w)
-	if *indent {
-		enc.SetIndent("", "\t")
+	if *grammarFormat != "" {
+		if err := writeGrammar(os.Stdout, *grammarFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
+		}
+		return
 	}
-	for _, expr := range exprs {
-		if err := enc.Encode(expr); err != nil {
-			fmt.Fprintf(os.Stderr, "cannot encode value %#v: %v\n", expr, err)
-			os.Exit(1)
+	newEncoder, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "json: unknown output format %q\n", *format)
+		os.Exit(2)
+	}
+	if (*separator != "\n" || !*finalNewline) && binaryFormats[*format] {
+		fmt.Fprintf(os.Stderr, "json: -separator and -final-newline cannot be used with binary output format %q\n", *format)
+		os.Exit(2)
+	}
+	if *typescript {
+		newEncoder = newTypeScriptEncoder
+	}
+	if *goStruct {
+		newEncoder = newGoStructEncoder
+	}
+	if *hermetic {
+		// The contents of these files affect the output
+		// but aren't part of the attestation.
+		for _, f := range []struct {
+			name, value string
+		}{
+			{"allowed-keys", *allowedKeys},
+			{"avro-schema", *avroSchemaFile},
+			{"openapi", *openAPIFile},
+			{"proto-desc", *protoDesc},
+		} {
+			if f.value != "" {
+				fmt.Fprintf(os.Stderr, "json: -%s cannot be used with -hermetic\n", f.name)
+				os.Exit(2)
+			}
 		}
 	}
-}
-
-type parser struct {
-	index int
-	args  []string
-}
-
-type syntaxError struct {
-	e string
-}
-
-func (e *syntaxError) Error() string {
-	return e.e
-}
-
-func parse(args []string) (_ []interface{}, err error) {
-	defer func() {
-		e := recover()
-		if e == nil {
-			return
+	var interval time.Duration
+	if *rate != "" {
+		var err error
+		interval, err = parseRate(*rate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		if e, ok := e.(*syntaxError); ok {
-			err = e
-			return
+	}
+	maxChunkBytes := 0
+	if *chunkBytes != "" {
+		var err error
+		maxChunkBytes, err = parseSize(*chunkBytes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: invalid -chunk-bytes value: %v\n", err)
+			os.Exit(2)
 		}
-		panic(e)
-	}()
-	return parse1(&parser{args: args}), nil
-}
-
-func parse1(p *parser) []interface{} {
-	a, ok := p.peek()
-	if !ok {
-		// No arguments -> null.
-		return nil
 	}
-	// It's an object key; parse the whole command line as an object.
-	if strings.HasSuffix(a, ":") || a == "key" {
-		obj := parseKeyValues(p)
-		if a, ok := p.peek(); ok {
-			syntaxErrorf("unexpected argument %q at %d", a, p.index)
+	var requiredPaths []keyPath
+	if *requireKeys != "" {
+		var err error
+		requiredPaths, err = parseKeyPaths(*requireKeys)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		return []interface{}{obj}
 	}
-	var exprs []interface{}
-	for {
-		a, ok := p.peek()
-		if !ok {
-			return exprs
+	var keyTmpl *keyTemplate
+	if *allowedKeys != "" {
+		var err error
+		keyTmpl, err = loadKeyTemplate(*allowedKeys)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		if a == "]" {
-			syntaxErrorf("unexpected argument ] at %d, expected value", p.index)
+	}
+	var reqSchema *requestSchema
+	if *openAPIFile != "" || *operationID != "" {
+		if *openAPIFile == "" || *operationID == "" {
+			fmt.Fprintf(os.Stderr, "json: -openapi and -operation must be used together\n")
+			os.Exit(2)
+		}
+		var err error
+		reqSchema, err = loadRequestSchema(*openAPIFile, *operationID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		exprs = append(exprs, parseValue(p))
 	}
-	return exprs
-}
-
-func parseKeyValues(p *parser) interface{} {
-	v := make(map[string]interface{})
-	for {
-		key, ok := p.peek()
-		if !ok || key == "]" {
-			return v
-		}
-		if key == "key" {
-			p.next()
-			key = p.mustPeek("key argument")
-		} else if !strings.HasSuffix(key, ":") {
-			syntaxErrorf("expected object key (ending in :) or 'key' keyword at argument %d, but got %q", p.index, key)
-		} else {
-			key = key[0 : len(key)-1]
-		}
-		p.next()
-		v[key] = parseValue(p)
-	}
-	return v
-}
-
-func parseValue(p *parser) interface{} {
-	switch a := p.mustNext("value"); a {
-	case "[":
-		v := parseKeyValues(p)
-		a := p.mustNext("]")
-		if a != "]" {
-			syntaxErrorf("argument %d; expected ] got %q", p.index-1, a)
-		}
-		return v
-	case ".[":
-		var v []interface{}
-		for {
-			if a := p.mustPeek("]"); a == "]" {
-				p.next()
-				break
-			}
-			v = append(v, parseValue(p))
+	if *seed != "" {
+		if err := setSeed(*seed); err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		return v
-	case "null":
-		return nil
-	case "true":
-		return true
-	case "false":
-		return false
-	case "str":
-		return p.mustNext("str argument")
-	case "json":
-		a := p.mustNext("json argument")
-		dec := json.NewDecoder(strings.NewReader(a))
-		dec.UseNumber()
-		var x interface{}
-		if err := dec.Decode(&x); err != nil {
-			syntaxErrorf("cannot unmarshal json %q at argument %d", a, p.index-1)
-		}
-		return x
-	case "jsonstr":
-		v := parseValue(p)
-		data, err := json.Marshal(v)
+	}
+	switch *colorFormat {
+	case "hex", "rgb":
+	default:
+		fmt.Fprintf(os.Stderr, "json: invalid -color-format value %q\n", *colorFormat)
+		os.Exit(2)
+	}
+	switch *partial {
+	case "keep", "delete":
+	default:
+		fmt.Fprintf(os.Stderr, "json: invalid -partial value %q\n", *partial)
+		os.Exit(2)
+	}
+	var args []string
+	if *scriptFile != "" {
+		scriptArgs, err := readScript(*scriptFile)
 		if err != nil {
-			panic(err)
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
+		}
+		args = append(args, scriptArgs...)
+	}
+	if *args0 {
+		if *serveStdio || *pasteKeys != "" {
+			fmt.Fprintf(os.Stderr, "json: -args0 cannot be used with -serve-stdio or -paste\n")
+			os.Exit(2)
 		}
-		return string(data)
-	case "num":
-		a := p.mustNext("numeric value")
-		n, err := strconv.ParseFloat(a, 64)
+		stdinArgs, err := readArgs0(os.Stdin)
 		if err != nil {
-			syntaxErrorf("invalid number %q at argument %d", a, p.index-1)
+			fmt.Fprintf(os.Stderr, "json: cannot read arguments: %v\n", err)
+			os.Exit(2)
+		}
+		args = append(args, stdinArgs...)
+	}
+	args = append(args, flag.Args()...)
+	if *serveStdio {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "json: arguments cannot be used with -serve-stdio\n")
+			os.Exit(2)
+		}
+		if err := serveArgs(os.Stdin, os.Stdout, newEncoder); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+	if *serveAddr != "" {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "json: arguments cannot be used with -serve\n")
+			os.Exit(2)
+		}
+		if err := http.ListenAndServe(*serveAddr, argsHandler(newEncoder, contentTypeOf(*format))); err != nil {
+			fatalf("%v", err)
 		}
-		if math.IsInf(n, 0) || math.IsNaN(n) {
-			syntaxErrorf("%q is not a regular floating point number and cannot be encoded to JSON", a)
+		return
+	}
+	var base interface{}
+	if *baseFile != "" {
+		if *hermetic {
+			fmt.Fprintf(os.Stderr, "json: -base cannot be used with -hermetic\n")
+			os.Exit(2)
 		}
-		// Preserve the original form of the number to avoid losing precision.
-		return json.Number(a)
-	case "bool":
-		a := p.mustNext("boolean value")
-		v, err := strconv.ParseBool(a)
+		var err error
+		base, err = loadBase(*baseFile)
 		if err != nil {
-			syntaxErrorf("invalid boolean at argument %d: %v", p.index-1, err)
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		return v
-	default:
-		if strings.HasSuffix(a, ":") || a == "key" {
-			syntaxErrorf("argument %d; expected value, got key", p.index-1)
+	}
+	if len(mergeFiles) > 0 {
+		if *hermetic {
+			fmt.Fprintf(os.Stderr, "json: -merge cannot be used with -hermetic\n")
+			os.Exit(2)
 		}
-		// If it looks like a float, treat it as a float.
-		n, err := strconv.ParseFloat(a, 64)
-		if err == nil {
-			return n
+		var err error
+		base, err = mergeObjects(base, mergeFiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json: %v\n", err)
+			os.Exit(2)
 		}
-		return a
+	}
+	if *bundle && *refResolve {
+		fmt.Fprintf(os.Stderr, "json: -bundle cannot be used with -ref-resolve\n")
+		os.Exit(2)
+	}
+	if *counterFile != "" && *hermetic {
+		fmt.Fprintf(os.Stderr, "json: -counter-file cannot be used with -hermetic\n")
+		os.Exit(2)
+	}
+	// Install the signal handler before parsing so that
+	// an interrupt abandons any request made by a keyword.
+	ctx := interruptContext()
+	var exprs []interface{}
+	var comments []map[string]string
+	if *pasteKeys != "" {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "json: arguments cannot be used with -paste\n")
+			os.Exit(2)
+		}
+		if *hermetic {
+			fmt.Fprintf(os.Stderr, "json: -paste cannot be used with -hermetic\n")
+			os.Exit(2)
+		}
+		if *freeze {
+			fmt.Fprintf(os.Stderr, "json: -paste cannot be used with -freeze\n")
+			os.Exit(2)
+		}
+	} else {
+		p := newParser(args)
+		p.ctx = ctx
+		p.trackOrigins = *provenance
+		p.hermetic = *hermetic
+		if !*args0 {
+			p.stdin = os.Stdin
+		}
+		p.params = params
+		p.freeze = *freeze
+		p.homogeneous = *homogeneousArrays
+		if *warnKeywords {
+			p.warnf = warnf
+		}
+		if *counterFile != "" {
+			counters, err := loadCounters(*counterFile)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			p.counters = counters
+		}
+		var err error
+		exprs, err = p.run()
+		if *showArgs {
+			writeArgs(os.Stderr, p.args, p.classes, useColor(os.Stderr))
+		}
+		if err != nil {
+			fatalf("%s", err)
+		}
+		if *freeze {
+			if err := writeFrozen(os.Stdout, exprs); err != nil {
+				fatalf("%v", err)
+			}
+			return
+		}
+		if reqSchema != nil && len(exprs) == 0 {
+			// With no arguments, the result is a skeleton
+			// of the request body.
+			v, _, err := schemaSkeleton(reqSchema.schema, reqSchema.root, false)
+			if err != nil {
+				fatalf("cannot make skeleton of request body: %v", err)
+			}
+			exprs = p.synthesize(exprs, v)
+		} else if len(mergeFiles) > 0 && len(exprs) == 0 {
+			// With no arguments, the result is the merged files.
+			exprs = p.synthesize(exprs, base)
+		} else if *baseFile != "" || len(mergeFiles) > 0 {
+			for i, v := range exprs {
+				exprs[i] = mergePatch(base, v)
+			}
+		}
+		if *refResolve {
+			for i, v := range exprs {
+				v, err := resolveRefs(v, *hermetic)
+				if err != nil {
+					fatalf("%v", err)
+				}
+				exprs[i] = v
+			}
+		}
+		if *bundle {
+			for i, v := range exprs {
+				v, err := bundleRefs(v, *hermetic)
+				if err != nil {
+					fatalf("%v", err)
+				}
+				exprs[i] = v
+			}
+		}
+		if err := checkRequiredKeys(exprs, requiredPaths); err != nil {
+			fatalf("%v", err)
+		}
+		if err := checkAllowedKeys(exprs, keyTmpl); err != nil {
+			fatalf("%v", err)
+		}
+		if reqSchema != nil {
+			if err := reqSchema.check(exprs); err != nil {
+				fatalf("%v", err)
+			}
+		}
+		if *counterFile != "" {
+			if err := saveCounters(*counterFile, p.counters); err != nil {
+				fatalf("cannot save counters: %v", err)
+			}
+		}
+		comments = p.comments
+		if *provenance {
+			exprs = withProvenance(exprs, p.origins)
+		}
+	}
+	if err := writeOutput(ctx, args, exprs, comments, newEncoder, interval, maxChunkBytes); err != nil {
+		removePartialOutput()
+		fatalf("%v", err)
 	}
 }
 
-func (p *parser) mustNext(expected string) string {
-	a := p.mustPeek(expected)
-	p.next()
-	return a
-}
-
-func (p *parser) mustPeek(expected string) string {
-	a, ok := p.peek()
-	if !ok {
-		syntaxErrorf("unexpected end of arguments (expected %s)", expected)
+// writeOutput writes the values, which were specified by args, to
+// the output using the given encoder constructor, or, with -paste,
+// the values read from standard input. The comments, if any, are
+// those recorded by the parser for the values.
+func writeOutput(ctx context.Context, args []string, exprs []interface{}, comments []map[string]string, newEncoder func(io.Writer) encoder, interval time.Duration, maxChunkBytes int) error {
+	newEncoder = withComments(newEncoder, comments)
+	chunked := *chunkElems > 0 || maxChunkBytes > 0
+	out := os.Stdout
+	if *output != "" && !chunked {
+		f, err := createOutput(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	var outputHash hash.Hash
+	var hw io.Writer = w
+	if *hermetic {
+		outputHash = sha256.New()
+		hw = io.MultiWriter(w, outputHash)
+	}
+	counter := &countingWriter{w: hw}
+	var enc encoder
+	if *separator != "\n" || !*finalNewline {
+		enc = newSeparatorEncoder(counter, newEncoder, *separator, *finalNewline)
+	} else {
+		enc = newEncoder(counter)
+	}
+	if len(emits) > 0 {
+		emitEnc, err := newEmitEncoder(enc, emits, comments)
+		if err != nil {
+			return err
+		}
+		enc = emitEnc
 	}
-	return a
+	closer, _ := enc.(encoderCloser)
+	if chunked {
+		base := enc
+		chunkEnc := &chunkEncoder{
+			maxElems: *chunkElems,
+			maxBytes: maxChunkBytes,
+			emit: func(chunk []interface{}) error {
+				return base.Encode(chunk)
+			},
+		}
+		if *output != "" {
+			chunkEnc.emit = chunkFileEmitter(*output, newEncoder)
+		}
+		enc = chunkEnc
+	}
+	if *journal {
+		enc = newJournalEncoder(enc)
+	}
+	if interval > 0 {
+		enc = newRateEncoder(ctx, enc, interval, w.Flush)
+	}
+	var in io.Reader = os.Stdin
+	var progressEnc *progressReporter
+	if *progress {
+		progressEnc = newProgressReporter(enc, counter)
+		if *pasteKeys != "" {
+			in = progressEnc.countInput(os.Stdin)
+		}
+		enc = progressEnc
+		progressEnc.run()
+		defer progressEnc.stop()
+	}
+	if *pasteKeys != "" {
+		if err := paste(ctx, in, enc); err != nil {
+			return interruptedError(ctx, err)
+		}
+	}
+	for _, expr := range exprs {
+		if ctx.Err() != nil {
+			return errInterrupted
+		}
+		if err := enc.Encode(expr); err != nil {
+			return interruptedError(ctx, fmt.Errorf("cannot encode value %#v: %v", expr, err))
+		}
+	}
+	if closer != nil {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if progressEnc != nil {
+		progressEnc.stop()
+		progressEnc.report()
+	}
+	if outputHash != nil {
+		if err := writeAttestation(os.Stderr, args, *format, outputHash); err != nil {
+			return err
+		}
+	}
+	if out == os.Stdout {
+		return nil
+	}
+	return closeOutput(out)
 }
 
-func (p *parser) next() (string, bool) {
-	a, ok := p.peek()
-	if !ok {
-		return "", false
+// interruptedError returns errInterrupted if err
+// was caused by the context being canceled.
+func interruptedError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return errInterrupted
 	}
-	p.index++
-	return a, true
+	return err
 }
 
-func (p *parser) peek() (string, bool) {
-	if p.index >= len(p.args) {
-		return "", false
-	}
-	return p.args[p.index], true
+func warnf(format string, arg ...interface{}) {
+	fmt.Fprintf(os.Stderr, "json: warning: %s\n", fmt.Sprintf(format, arg...))
 }
 
-func syntaxErrorf(format string, arg ...interface{}) {
-	panic(&syntaxError{
-		e: fmt.Sprintf(format, arg...),
-	})
+func fatalf(format string, arg ...interface{}) {
+	fmt.Fprintf(os.Stderr, "json: %s\n", fmt.Sprintf(format, arg...))
+	os.Exit(1)
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -53,6 +54,42 @@ var parseTests = []struct {
 	testName: "json-string",
 	args:     []string{"json", `{"a": "b"}`},
 	expect:   []interface{}{map[string]interface{}{"a": "b"}},
+}, {
+	testName: "yaml",
+	args:     []string{"a:", "yaml", "b: [1, x]"},
+	expect:   []interface{}{map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{json.Number("1"), "x"}}}},
+}, {
+	testName: "toml",
+	args:     []string{"a:", "toml", "b = 1"},
+	expect:   []interface{}{map[string]interface{}{"a": map[string]interface{}{"b": json.Number("1")}}},
+}, {
+	testName:    "yaml-invalid",
+	args:        []string{"yaml", "a: ["},
+	expectError: `cannot unmarshal yaml "a: \[" at argument 1: .*`,
+}, {
+	testName: "json5",
+	args:     []string{"a:", "json5", "{b: 'c',}"},
+	expect:   []interface{}{map[string]interface{}{"a": map[string]interface{}{"b": "c"}}},
+}, {
+	testName:    "json5-invalid",
+	args:        []string{"json5", "[1 2]"},
+	expectError: `cannot unmarshal json5 "\[1 2\]" at argument 1: .*`,
+}, {
+	testName: "int",
+	args:     []string{"int", "-9007199254740993", "int", "+007"},
+	expect:   []interface{}{json.Number("-9007199254740993"), json.Number("7")},
+}, {
+	testName:    "int-with-fraction",
+	args:        []string{"int", "1.0"},
+	expectError: `invalid integer "1.0" at argument 1`,
+}, {
+	testName:    "int-with-exponent",
+	args:        []string{"int", "1e3"},
+	expectError: `invalid integer "1e3" at argument 1`,
+}, {
+	testName:    "int-out-of-range",
+	args:        []string{"int", "9223372036854775808"},
+	expectError: `integer "9223372036854775808" at argument 1 is out of range for 64 bits`,
 }, {
 	testName:    "forced-number-with-invalid-number",
 	args:        []string{"num", "a"},
@@ -65,6 +102,18 @@ var parseTests = []struct {
 	testName:    "forced-number-with-NaN",
 	args:        []string{"num", "NaN"},
 	expectError: `"NaN" is not a regular floating point number and cannot be encoded to JSON`,
+}, {
+	testName: "comment",
+	args:     []string{"comment", "the a", "a:", "1", "b:", "[", "comment", "c", "key", "c", "2", "]"},
+	expect:   []interface{}{map[string]interface{}{"a": 1.0, "b": map[string]interface{}{"c": 2.0}}},
+}, {
+	testName:    "comment-without-key",
+	args:        []string{"a:", "1", "comment", "x"},
+	expectError: `comment at argument 2 must be followed by a key`,
+}, {
+	testName: "comment-before-value",
+	args:     []string{".[", "comment", "x", "y", "]"},
+	expect:   []interface{}{[]interface{}{"comment", "x", "y"}},
 }, {
 	testName: "top-level-object",
 	args:     []string{"xy:", "zw", "abc:", "de"},
@@ -88,19 +137,82 @@ var parseTests = []struct {
 	}},
 }, {
 	testName: "literal-object-key",
-	args: []string{"key", "foo\"bar", "123", "x:", "y"},
+	args:     []string{"key", "foo\"bar", "123", "x:", "y"},
 	expect: []interface{}{map[string]interface{}{
 		"foo\"bar": 123.0,
-		"x": "y",
+		"x":        "y",
 	}},
 }, {
-	testName: "key-in-value-position",
-	args: []string{"a:", "b:"},
+	testName:    "key-in-value-position",
+	args:        []string{"a:", "b:"},
 	expectError: `argument 1; expected value, got key`,
 }, {
-	testName: "key-keyword--in-value-position",
-	args: []string{"a:", "key", "k"},
+	testName:    "key-keyword--in-value-position",
+	args:        []string{"a:", "key", "k"},
 	expectError: `argument 1; expected value, got key`,
+}, {
+	testName: "labels",
+	args:     []string{"labels", `up{job="api", path="/a\"b\""}`},
+	expect: []interface{}{map[string]interface{}{
+		"name": "up",
+		"labels": map[string]interface{}{
+			"job":  "api",
+			"path": `/a"b"`,
+		},
+	}},
+}, {
+	testName: "labels-without-name",
+	args:     []string{"labels", `{a="1",}`},
+	expect: []interface{}{map[string]interface{}{
+		"labels": map[string]interface{}{"a": "1"},
+	}},
+}, {
+	testName: "labels-name-only",
+	args:     []string{"labels", "up"},
+	expect: []interface{}{map[string]interface{}{
+		"name":   "up",
+		"labels": map[string]interface{}{},
+	}},
+}, {
+	testName:    "labels-unquoted-value",
+	args:        []string{"labels", `up{a=b}`},
+	expectError: `invalid label set "up\{a=b\}" at argument 1: invalid value for label "a": value must be double-quoted`,
+}, {
+	testName:    "labels-regexp-matcher",
+	args:        []string{"labels", `up{a=~"b"}`},
+	expectError: `invalid label set .* at argument 1: expected = after label name "a"`,
+}, {
+	testName: "empty-array",
+	args:     []string{".[", "]"},
+	expect:   []interface{}{[]interface{}{}},
+}, {
+	testName: "jsonstr",
+	args:     []string{"jsonstr", "[", "a:", ".[", "x", "]", "]"},
+	expect:   []interface{}{`{"a":["x"]}`},
+}, {
+	testName:    "unterminated-object",
+	args:        []string{"[", "a:", "1"},
+	expectError: `unexpected end of arguments \(expected key or \]\)`,
+}, {
+	testName:    "unterminated-array",
+	args:        []string{".[", "1"},
+	expectError: `unexpected end of arguments \(expected value or \]\)`,
+}, {
+	testName:    "missing-value",
+	args:        []string{"a:", "1", "b:"},
+	expectError: `unexpected end of arguments \(expected value\)`,
+}, {
+	testName:    "missing-keyword-argument",
+	args:        []string{"num"},
+	expectError: `unexpected end of arguments \(expected numeric value\)`,
+}, {
+	testName:    "close-in-value-position",
+	args:        []string{"[", "a:", "]", "]"},
+	expectError: `unexpected argument \] at 2, expected value`,
+}, {
+	testName:    "close-after-top-level-object",
+	args:        []string{"a:", "1", "]"},
+	expectError: `unexpected argument "\]" at 2`,
 }}
 
 func TestParse(t *testing.T) {
@@ -120,3 +232,54 @@ func TestParse(t *testing.T) {
 }
 
 var deepEquals = qt.CmpEquals(cmpopts.EquateApprox(1e-9, 0))
+
+func TestParseDeeplyNested(t *testing.T) {
+	c := qt.New(t)
+	const depth = 100000
+	args := make([]string, 0, depth*2)
+	for i := 0; i < depth; i++ {
+		args = append(args, ".[")
+	}
+	for i := 0; i < depth; i++ {
+		args = append(args, "]")
+	}
+	v, err := parse(args)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.HasLen, 1)
+}
+
+var expectedTests = []struct {
+	args   []string
+	expect []string
+}{{
+	args:   []string{},
+	expect: []string{"value", ""},
+}, {
+	args:   []string{"a:", "1"},
+	expect: []string{"key", ""},
+}, {
+	args:   []string{"a:"},
+	expect: []string{"value"},
+}, {
+	args:   []string{"x", "[", "a:", "1"},
+	expect: []string{"key", "]"},
+}, {
+	args:   []string{".[", "1"},
+	expect: []string{"value", "]"},
+}, {
+	args:   []string{".[", "jsonstr"},
+	expect: []string{"value"},
+}}
+
+func TestExpected(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range expectedTests {
+		c.Run(strings.Join(test.args, " "), func(c *qt.C) {
+			p := newParser(test.args)
+			for p.index < len(p.args) {
+				c.Assert(p.step(), qt.Equals, nil)
+			}
+			c.Assert(p.expected(), qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	memoDir = flag.String("memo", "", "directory in which to cache the output of the cmd, http and fetch keywords between runs, keyed by their inputs")
+	memoTTL = flag.Duration("memo-ttl", time.Hour, "how long results cached in the -memo directory are used for")
+)
+
+// memoize returns the result of get, which makes a request of the
+// given kind with the given input. If a -memo directory is set,
+// a result cached there that's younger than -memo-ttl is
+// used instead, and a new result is stored there. Failed
+// requests are not cached.
+func memoize(kind, input string, get func() (string, error)) (string, error) {
+	if *memoDir == "" {
+		return get()
+	}
+	path := filepath.Join(*memoDir, fmt.Sprintf("%x", sha256.Sum256([]byte(kind+"\x00"+input))))
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < *memoTTL {
+		if data, err := ioutil.ReadFile(path); err == nil {
+			return string(data), nil
+		}
+	}
+	s, err := get()
+	if err != nil {
+		return "", err
+	}
+	if err := saveMemo(path, s); err != nil {
+		return "", fmt.Errorf("cannot save %s result: %v", kind, err)
+	}
+	return s, nil
+}
+
+// saveMemo writes a cached result to the named file. The file
+// is replaced atomically so that a concurrent run never sees
+// it partially written.
+func saveMemo(file, s string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), ".memo")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(s)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMemoize(t *testing.T) {
+	c := qt.New(t)
+	c.Run("cached", func(c *qt.C) {
+		c.Patch(memoDir, filepath.Join(c.Mkdir(), "memo"))
+		n := 0
+		get := func() (string, error) {
+			n++
+			return fmt.Sprint("result ", n), nil
+		}
+		for i := 0; i < 2; i++ {
+			s, err := memoize("cmd", "x", get)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(s, qt.Equals, "result 1")
+		}
+		// The kind and input together make the key.
+		s, err := memoize("http", "x", get)
+		c.Assert(err, qt.Equals, nil)
+		c.Assert(s, qt.Equals, "result 2")
+		s, err = memoize("cmd", "y", get)
+		c.Assert(err, qt.Equals, nil)
+		c.Assert(s, qt.Equals, "result 3")
+	})
+	c.Run("expired", func(c *qt.C) {
+		c.Patch(memoDir, c.Mkdir())
+		c.Patch(memoTTL, time.Duration(0))
+		n := 0
+		get := func() (string, error) {
+			n++
+			return fmt.Sprint("result ", n), nil
+		}
+		for i := 1; i <= 2; i++ {
+			s, err := memoize("cmd", "x", get)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(s, qt.Equals, fmt.Sprint("result ", i))
+		}
+	})
+	c.Run("error-not-cached", func(c *qt.C) {
+		dir := c.Mkdir()
+		c.Patch(memoDir, dir)
+		_, err := memoize("cmd", "x", func() (string, error) {
+			return "", errors.New("failed")
+		})
+		c.Assert(err, qt.ErrorMatches, "failed")
+		infos, err := ioutil.ReadDir(dir)
+		c.Assert(err, qt.Equals, nil)
+		c.Assert(infos, qt.HasLen, 0)
+	})
+	c.Run("disabled", func(c *qt.C) {
+		c.Patch(memoDir, "")
+		n := 0
+		for i := 1; i <= 2; i++ {
+			s, err := memoize("cmd", "x", func() (string, error) {
+				n++
+				return fmt.Sprint("result ", n), nil
+			})
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(s, qt.Equals, fmt.Sprint("result ", i))
+		}
+	})
+}
+
+func TestMemoizeKeywords(t *testing.T) {
+	c := qt.New(t)
+	c.Run("fetch", func(c *qt.C) {
+		c.Patch(memoDir, c.Mkdir())
+		n := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			n++
+			fmt.Fprint(w, n)
+		}))
+		defer srv.Close()
+		vals, err := newParser([]string{"a:", "fetch", srv.URL, "b:", "str", "fetch", "c:", "num", "http", srv.URL}).run()
+		c.Assert(err, qt.Equals, nil)
+		c.Assert(vals, qt.DeepEquals, []interface{}{map[string]interface{}{
+			"a": json.Number("1"),
+			"b": "fetch",
+			"c": json.Number("1"),
+		}})
+		c.Assert(n, qt.Equals, 1)
+	})
+	c.Run("cmd", func(c *qt.C) {
+		c.Patch(memoDir, c.Mkdir())
+		vals, err := newParser([]string{".[", "cmd", "date +%N", "cmd", "date +%N", "]"}).run()
+		c.Assert(err, qt.Equals, nil)
+		elems := vals[0].([]interface{})
+		c.Assert(elems[1], qt.Equals, elems[0])
+	})
+}
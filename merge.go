@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var mergeFiles fileListFlag
+
+func init() {
+	flag.Var(&mergeFiles, "merge", "file holding a JSON or YAML object to deep-merge into the base document, as with -base; may be repeated, in which case later files override earlier ones")
+}
+
+// fileListFlag implements flag.Value for a repeatable flag
+// that names a file.
+type fileListFlag []string
+
+func (f *fileListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileListFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// mergeObjects reads the objects in the named files and merges
+// each one in turn into base, which may be nil, as a JSON merge
+// patch. It returns the result.
+func mergeObjects(base interface{}, files []string) (interface{}, error) {
+	for _, file := range files {
+		v, err := loadBase(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := v.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("cannot merge %s: holds %s, not object", file, valueKind(v))
+		}
+		base = mergePatch(base, v)
+	}
+	return base, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var mergeObjectsTests = []struct {
+	testName    string
+	base        string
+	files       []string
+	expect      string
+	expectError string
+}{{
+	testName: "layered",
+	files:    []string{"defaults.json", "prod.yaml"},
+	expect:   `{"port": "http", "tls": {"enabled": true, "cert": "x.pem"}}`,
+}, {
+	testName: "order",
+	files:    []string{"prod.yaml", "defaults.json"},
+	expect:   `{"port": "http", "tls": {"enabled": false, "cert": "x.pem"}}`,
+}, {
+	testName: "with-base",
+	base:     `{"name": "web", "port": "ssh"}`,
+	files:    []string{"defaults.json"},
+	expect:   `{"name": "web", "port": "http", "tls": {"enabled": false}}`,
+}, {
+	testName: "null-deletes",
+	files:    []string{"defaults.json", "notls.json"},
+	expect:   `{"port": "http"}`,
+}, {
+	testName:    "not-object",
+	files:       []string{"defaults.json", "array.json"},
+	expectError: `cannot merge .*array.json: holds array, not object`,
+}, {
+	testName:    "missing",
+	files:       []string{"missing.json"},
+	expectError: `open .*missing.json: .*`,
+}}
+
+func TestMergeObjects(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	files := map[string]string{
+		"defaults.json": `{"port": "http", "tls": {"enabled": false}}`,
+		"prod.yaml":     "tls:\n  enabled: true\n  cert: x.pem\n",
+		"notls.json":    `{"tls": null}`,
+		"array.json":    `[1]`,
+	}
+	for name, data := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666)
+		c.Assert(err, qt.Equals, nil)
+	}
+	for _, test := range mergeObjectsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var base interface{}
+			if test.base != "" {
+				base = unmarshalJSON(c, test.base)
+			}
+			var paths []string
+			for _, file := range test.files {
+				paths = append(paths, filepath.Join(dir, file))
+			}
+			got, err := mergeObjects(base, paths)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(got, qt.DeepEquals, unmarshalJSON(c, test.expect))
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// msgpackEncoder encodes values in MessagePack format
+// (see https://github.com/msgpack/msgpack/blob/master/spec.md).
+type msgpackEncoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+func newMsgpackEncoder(w io.Writer) encoder {
+	return &msgpackEncoder{w: w}
+}
+
+func (e *msgpackEncoder) Encode(v interface{}) error {
+	e.buf = e.buf[:0]
+	if err := e.encode(v); err != nil {
+		return err
+	}
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *msgpackEncoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xc0)
+	case bool:
+		if v {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case float64, json.Number:
+		if i, ok := intValue(v); ok {
+			e.encodeInt(i)
+			break
+		}
+		e.buf = append(e.buf, 0xcb)
+		e.buf = appendUint64(e.buf, math.Float64bits(floatValue(v)))
+	case string:
+		e.encodeHeader(len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		e.buf = append(e.buf, v...)
+	case []interface{}:
+		e.encodeHeader(len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, elem := range v {
+			if err := e.encode(elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		e.encodeHeader(len(v), 0x80, 15, 0, 0xde, 0xdf)
+		for _, k := range sortedKeys(v) {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as msgpack", v)
+	}
+	return nil
+}
+
+// encodeHeader appends the header for a string, array or map of length n.
+// The fix code is used when n <= fixMax; otherwise the smallest of the
+// 8, 16 and 32 bit codes is used. A zero code8 means that
+// there is no 8-bit form.
+func (e *msgpackEncoder) encodeHeader(n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n <= fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		e.buf = append(e.buf, code8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, code16)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, code32)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeInt(i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f, i < 0 && i >= -32:
+		// Positive or negative fixint.
+		e.buf = append(e.buf, byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = appendUint16(e.buf, uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = appendUint32(e.buf, uint32(i))
+	case i >= 0:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = appendUint64(e.buf, uint64(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = appendUint16(e.buf, uint16(i))
+	case i >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = appendUint32(e.buf, uint32(i))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = appendUint64(e.buf, uint64(i))
+	}
+}
+
+func appendUint16(buf []byte, x uint16) []byte {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], x)
+	return append(buf, b[:]...)
+}
+
+func appendUint32(buf []byte, x uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], x)
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, x uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], x)
+	return append(buf, b[:]...)
+}
+
+// decodeMsgpack decodes a single MessagePack value from data and
+// returns the equivalent JSON value. Binary data is converted to a
+// base64 string, numbers to json.Number and map keys that aren't
+// strings to their textual representation.
+func decodeMsgpack(data []byte) (interface{}, error) {
+	d := &msgpackDecoder{data: data}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) > 0 {
+		return nil, fmt.Errorf("%d unexpected bytes after value", len(d.data))
+	}
+	return v, nil
+}
+
+type msgpackDecoder struct {
+	data []byte
+}
+
+func (d *msgpackDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of the given size in bytes.
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.take(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var x uint64
+	for _, c := range b {
+		x = x<<8 | uint64(c)
+	}
+	return x, nil
+}
+
+func (d *msgpackDecoder) decode() (interface{}, error) {
+	b, err := d.take(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return json.Number(strconv.Itoa(int(c))), nil
+	case c >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(c)))), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(uint64(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(uint64(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.decodeString(uint64(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case 0xca:
+		x, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(float64(math.Float32frombits(uint32(x))))
+	case 0xcb:
+		x, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(math.Float64frombits(x))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		x, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(x, 10)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		x, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend the value.
+		shift := uint(64 - 8*size)
+		return json.Number(strconv.FormatInt(int64(x<<shift)>>shift, 10)), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n)
+	}
+	return nil, fmt.Errorf("unsupported msgpack type 0x%02x", c)
+}
+
+func (d *msgpackDecoder) decodeString(n uint64) (interface{}, error) {
+	b, err := d.take(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n uint64) (interface{}, error) {
+	if n > uint64(len(d.data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	elems := make([]interface{}, n)
+	for i := range elems {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = v
+	}
+	return elems, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n uint64) (interface{}, error) {
+	if n > uint64(len(d.data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	obj := make(map[string]interface{})
+	for i := uint64(0); i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		key, err := scalarText(k)
+		if err != nil {
+			return nil, fmt.Errorf("cannot use %s as an object key", valueKind(k))
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+	}
+	return obj, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var msgpackTests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "null",
+	val:      nil,
+	expect:   "c0",
+}, {
+	testName: "bool",
+	val:      []interface{}{true, false},
+	expect:   "92c3c2",
+}, {
+	testName: "small-ints",
+	val:      []interface{}{0.0, 127.0, -1.0, -32.0},
+	expect:   "94007fffe0",
+}, {
+	testName: "larger-ints",
+	val:      []interface{}{200.0, 65535.0, -33.0, -40000.0, json.Number("4294967296")},
+	expect:   "95ccc8cdffffd0dfd2ffff63c0cf0000000100000000",
+}, {
+	testName: "float",
+	val:      1.5,
+	expect:   "cb3ff8000000000000",
+}, {
+	testName: "json-number-float",
+	val:      json.Number("0.25"),
+	expect:   "cb3fd0000000000000",
+}, {
+	testName: "string",
+	val:      "hello",
+	expect:   "a568656c6c6f",
+}, {
+	testName: "map-with-sorted-keys",
+	val: map[string]interface{}{
+		"b": 1.0,
+		"a": "x",
+	},
+	expect: "82a161a178a16201",
+}}
+
+func TestMsgpackEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range msgpackTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newMsgpackEncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestMsgpackLongString(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	err := newMsgpackEncoder(&buf).Encode(string(make([]byte, 300)))
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(buf.Bytes()[:3], qt.DeepEquals, []byte{0xda, 0x01, 0x2c})
+	c.Assert(buf.Len(), qt.Equals, 303)
+}
+
+var msgpackDecodeTests = []struct {
+	testName    string
+	data        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "null",
+	data:     "c0",
+	expect:   nil,
+}, {
+	testName: "ints",
+	data:     "dc000b007fffe0ccc8cdffffd0dfd2ffff63c0cf0000000100000000cfffffffffffffffffd3ffffffffffffffff",
+	expect: []interface{}{
+		json.Number("0"),
+		json.Number("127"),
+		json.Number("-1"),
+		json.Number("-32"),
+		json.Number("200"),
+		json.Number("65535"),
+		json.Number("-33"),
+		json.Number("-40000"),
+		json.Number("4294967296"),
+		json.Number("18446744073709551615"),
+		json.Number("-1"),
+	},
+}, {
+	testName: "floats",
+	data:     "92cb3ff8000000000000ca3fc00000",
+	expect:   []interface{}{json.Number("1.5"), json.Number("1.5")},
+}, {
+	testName: "strings-and-binary",
+	data:     "93a568656c6c6fd90161c403010203",
+	expect:   []interface{}{"hello", "a", "AQID"},
+}, {
+	testName: "map",
+	data:     "83a161a178a16201" + "01c3",
+	expect: map[string]interface{}{
+		"a": "x",
+		"b": json.Number("1"),
+		"1": true,
+	},
+}, {
+	testName:    "unsupported",
+	data:        "c1",
+	expectError: `unsupported msgpack type 0xc1`,
+}, {
+	testName:    "ext",
+	data:        "d40102",
+	expectError: `unsupported msgpack type 0xd4`,
+}, {
+	testName:    "truncated",
+	data:        "a261",
+	expectError: `unexpected EOF`,
+}, {
+	testName:    "trailing-data",
+	data:        "c0c0",
+	expectError: `1 unexpected bytes after value`,
+}, {
+	testName:    "array-key",
+	data:        "819001",
+	expectError: `cannot use array as an object key`,
+}}
+
+func TestMsgpackDecode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range msgpackDecodeTests {
+		c.Run(test.testName, func(c *qt.C) {
+			data, err := hex.DecodeString(test.data)
+			c.Assert(err, qt.Equals, nil)
+			v, err := decodeMsgpack(data)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
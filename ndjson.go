@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseNDJSON parses s as newline-delimited JSON, with one
+// value on each line, and returns an array holding the values.
+// Blank lines are ignored.
+func parseNDJSON(s string) (interface{}, error) {
+	vals := []interface{}{}
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			return nil, fmt.Errorf("line %d: unexpected data after value", i+1)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var parseNDJSONTests = []struct {
+	testName    string
+	data        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "values",
+	data:     "{\"a\":1}\n[true]\n\"x\"\nnull\n",
+	expect: []interface{}{
+		map[string]interface{}{"a": json.Number("1")},
+		[]interface{}{true},
+		"x",
+		nil,
+	},
+}, {
+	testName: "blank-lines-and-crlf",
+	data:     "\n1\r\n\r\n 2 \n",
+	expect:   []interface{}{json.Number("1"), json.Number("2")},
+}, {
+	testName: "empty",
+	data:     "",
+	expect:   []interface{}{},
+}, {
+	testName:    "invalid",
+	data:        "1\n{\"a\"\n",
+	expectError: `line 2: unexpected EOF`,
+}, {
+	testName:    "two-values-on-line",
+	data:        "1 2\n",
+	expectError: `line 1: unexpected data after value`,
+}}
+
+func TestParseNDJSON(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseNDJSONTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseNDJSON(test.data)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A numBound holds one bound of a numrange assertion.
+type numBound struct {
+	n         float64
+	exclusive bool
+}
+
+// parseNumRange parses the bounds of a numrange assertion.
+// The lower bound may be prefixed with "(" and the upper bound
+// suffixed with ")" to make them exclusive, as in interval
+// notation; "[" and "]" explicitly mark inclusive bounds,
+// which is the default.
+func parseNumRange(lo, hi string) (numBound, numBound, error) {
+	var loBound, hiBound numBound
+	switch {
+	case strings.HasPrefix(lo, "("):
+		loBound.exclusive = true
+		lo = lo[1:]
+	case strings.HasPrefix(lo, "["):
+		lo = lo[1:]
+	}
+	switch {
+	case strings.HasSuffix(hi, ")"):
+		hiBound.exclusive = true
+		hi = hi[:len(hi)-1]
+	case strings.HasSuffix(hi, "]"):
+		hi = hi[:len(hi)-1]
+	}
+	var err error
+	if loBound.n, err = parseBound(lo); err != nil {
+		return numBound{}, numBound{}, err
+	}
+	if hiBound.n, err = parseBound(hi); err != nil {
+		return numBound{}, numBound{}, err
+	}
+	if loBound.n > hiBound.n {
+		return numBound{}, numBound{}, fmt.Errorf("lower bound %s is greater than upper bound %s", lo, hi)
+	}
+	return loBound, hiBound, nil
+}
+
+func parseBound(s string) (float64, error) {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid bound %q", s)
+	}
+	return n, nil
+}
+
+// inRange reports whether n is within the given bounds.
+func inRange(n float64, lo, hi numBound) bool {
+	if n < lo.n || lo.exclusive && n == lo.n {
+		return false
+	}
+	if n > hi.n || hi.exclusive && n == hi.n {
+		return false
+	}
+	return true
+}
+
+// rangeText returns the bounds in interval notation.
+func rangeText(lo, hi numBound) string {
+	open, close := "[", "]"
+	if lo.exclusive {
+		open = "("
+	}
+	if hi.exclusive {
+		close = ")"
+	}
+	return open + strconv.FormatFloat(lo.n, 'g', -1, 64) + ", " + strconv.FormatFloat(hi.n, 'g', -1, 64) + close
+}
+
+func evalNumRange(args []string, index int, v interface{}) (interface{}, error) {
+	lo, hi, err := parseNumRange(args[0], args[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid range at argument %d: %v", index, err)
+	}
+	if kind := valueKind(v); kind != "number" {
+		return nil, fmt.Errorf("numrange value at argument %d is %s, not number", index+2, kind)
+	}
+	if !inRange(floatValue(v), lo, hi) {
+		text, _ := scalarText(v)
+		return nil, fmt.Errorf("number %s at argument %d is out of range %s", text, index+2, rangeText(lo, hi))
+	}
+	return v, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var numRangeTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "inclusive",
+	args:     "numrange 1 65535 65535",
+	expect:   65535.0,
+}, {
+	testName: "explicit-inclusive",
+	args:     "numrange [0 100] 0",
+	expect:   0.0,
+}, {
+	testName: "asserted-number",
+	args:     "numrange 0 10 num 5",
+	expect:   json.Number("5"),
+}, {
+	testName:    "above",
+	args:        "numrange 1 65535 70000",
+	expectError: `number 70000 at argument 3 is out of range \[1, 65535\]`,
+}, {
+	testName:    "below",
+	args:        "numrange -1.5 2 -2",
+	expectError: `number -2 at argument 3 is out of range \[-1.5, 2\]`,
+}, {
+	testName:    "exclusive-lower",
+	args:        "numrange (0 1 0",
+	expectError: `number 0 at argument 3 is out of range \(0, 1\]`,
+}, {
+	testName:    "exclusive-upper",
+	args:        "numrange 0 100) 100",
+	expectError: `number 100 at argument 3 is out of range \[0, 100\)`,
+}, {
+	testName:    "not-a-number",
+	args:        "numrange 0 1 x",
+	expectError: `numrange value at argument 3 is string, not number`,
+}, {
+	testName:    "invalid-bound",
+	args:        "numrange 0 y 1",
+	expectError: `invalid range at argument 1: invalid bound "y"`,
+}, {
+	testName:    "inverted-bounds",
+	args:        "numrange 2 1 1",
+	expectError: `invalid range at argument 1: lower bound 2 is greater than upper bound 1`,
+}}
+
+func TestNumRange(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range numRangeTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
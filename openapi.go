@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	openAPIFile = flag.String("openapi", "", "file holding a JSON or YAML OpenAPI document; each value must match the request body schema of the operation named by -operation; with no arguments, a skeleton of the request body is printed")
+	operationID = flag.String("operation", "", "operationId of the operation in the -openapi document")
+)
+
+// openAPIMethods holds the fields of an OpenAPI path item
+// that hold operations.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// A requestSchema holds the schema of the request body
+// of an OpenAPI operation.
+type requestSchema struct {
+	operationID string
+	schema      interface{}
+	// root holds the OpenAPI document, which is used
+	// to resolve references in the schema.
+	root interface{}
+}
+
+// loadRequestSchema reads the OpenAPI document in the named
+// file and returns the JSON request body schema of the
+// operation with the given operationId.
+func loadRequestSchema(file, opID string) (*requestSchema, error) {
+	doc, err := loadBase(file)
+	if err != nil {
+		return nil, err
+	}
+	op, err := findOperation(doc, opID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	sv := &schemaValidator{root: doc}
+	body, _, err := sv.resolveSchema(op["requestBody"], 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: operation %q: %v", file, opID, err)
+	}
+	content, _ := body["content"].(map[string]interface{})
+	mediaType, ok := content["application/json"].(map[string]interface{})
+	if !ok {
+		// Fall back to another JSON media type, such as application/merge-patch+json.
+		for _, name := range sortedKeys(content) {
+			if strings.HasSuffix(name, "json") {
+				mediaType, ok = content[name].(map[string]interface{})
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s: operation %q has no JSON request body", file, opID)
+	}
+	return &requestSchema{
+		operationID: opID,
+		schema:      mediaType["schema"],
+		root:        doc,
+	}, nil
+}
+
+// findOperation returns the operation with the given operationId.
+func findOperation(doc interface{}, opID string) (map[string]interface{}, error) {
+	m, _ := doc.(map[string]interface{})
+	paths, ok := m["paths"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no paths found")
+	}
+	var ids []string
+	for _, path := range sortedKeys(paths) {
+		item, _ := paths[path].(map[string]interface{})
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id, _ := op["operationId"].(string)
+			if id == opID {
+				return op, nil
+			}
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return nil, fmt.Errorf("operation %q not found; operations are %s", opID, strings.Join(ids, ", "))
+}
+
+// check returns an error describing how any of the
+// values fail to match the request body schema.
+func (rs *requestSchema) check(vals []interface{}) error {
+	for i, v := range vals {
+		errs := validateSchema(rs.schema, rs.root, v)
+		if len(errs) == 0 {
+			continue
+		}
+		what := "value"
+		if len(vals) > 1 {
+			what = fmt.Sprintf("value %d", i)
+		}
+		return fmt.Errorf("%s does not match request body of operation %q: %s", what, rs.operationID, strings.Join(errs, "; "))
+	}
+	return nil
+}
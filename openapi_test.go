@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const testOpenAPI = `
+openapi: 3.0.0
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+  /pets/{id}:
+    get:
+      operationId: getPet
+    patch:
+      operationId: updatePet
+      requestBody:
+        content:
+          application/merge-patch+json:
+            schema:
+              type: object
+              properties:
+                age: {type: integer, nullable: true}
+components:
+  requestBodies:
+    Pet:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      additionalProperties: false
+      properties:
+        name: {type: string, minLength: 1}
+        age: {type: integer, minimum: 0}
+`
+
+var requestSchemaTests = []struct {
+	testName    string
+	operation   string
+	args        string
+	expectError string
+}{{
+	testName:  "ok",
+	operation: "createPet",
+	args:      "name: rex age: 3",
+}, {
+	testName:    "mismatch",
+	operation:   "createPet",
+	args:        "nme: rex age: -1",
+	expectError: `value does not match request body of operation "createPet": /: missing required member "name"; /age: -1 is less than the minimum of 0; /nme: member is not allowed`,
+}, {
+	testName:    "multiple-values",
+	operation:   "createPet",
+	args:        "[ name: a ] [ name: 1 ]",
+	expectError: `value 1 does not match request body of operation "createPet": /name: got number, want string`,
+}, {
+	testName:  "other-json-media-type",
+	operation: "updatePet",
+	args:      "age: null",
+}}
+
+func TestRequestSchema(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "api.yaml")
+	err := ioutil.WriteFile(file, []byte(testOpenAPI), 0666)
+	c.Assert(err, qt.Equals, nil)
+	for _, test := range requestSchemaTests {
+		c.Run(test.testName, func(c *qt.C) {
+			rs, err := loadRequestSchema(file, test.operation)
+			c.Assert(err, qt.Equals, nil)
+			vals, err := newParser(strings.Fields(test.args)).run()
+			c.Assert(err, qt.Equals, nil)
+			err = rs.check(vals)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+		})
+	}
+}
+
+func TestLoadRequestSchemaError(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "api.yaml")
+	err := ioutil.WriteFile(file, []byte(testOpenAPI), 0666)
+	c.Assert(err, qt.Equals, nil)
+	_, err = loadRequestSchema(file, "deletePet")
+	c.Assert(err, qt.ErrorMatches, `.*api.yaml: operation "deletePet" not found; operations are createPet, getPet, updatePet`)
+	_, err = loadRequestSchema(file, "getPet")
+	c.Assert(err, qt.ErrorMatches, `.*api.yaml: operation "getPet" has no JSON request body`)
+}
+
+func TestRequestSchemaSkeletonProvenance(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "api.yaml")
+	err := ioutil.WriteFile(file, []byte(testOpenAPI), 0666)
+	c.Assert(err, qt.Equals, nil)
+	rs, err := loadRequestSchema(file, "createPet")
+	c.Assert(err, qt.Equals, nil)
+	// With no arguments, the skeleton takes the place of
+	// the parsed values and has no provenance.
+	p := newParser(nil)
+	p.trackOrigins = true
+	vals, err := p.run()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(vals, qt.HasLen, 0)
+	v, _, err := schemaSkeleton(rs.schema, rs.root, false)
+	c.Assert(err, qt.Equals, nil)
+	vals = p.synthesize(vals, v)
+	got := withProvenance(vals, p.origins)
+	c.Assert(got, qt.HasLen, 1)
+	c.Assert(got[0].(map[string]interface{})["provenance"], qt.DeepEquals, map[string]interface{}{})
+}
@@ -0,0 +1,979 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A keyword describes a keyword argument that introduces a value.
+type keyword struct {
+	// args holds descriptions of the arguments that follow
+	// the keyword. The description
+	// of an optional argument, which only a keyword with a
+	// parse function can have, is enclosed in square brackets.
+	args []string
+	// takesValue holds whether the keyword is followed
+	// by a value after its arguments.
+	takesValue bool
+	// eval returns the value of the keyword given its arguments,
+	// the index of the first of those arguments, and the value that
+	// follows the arguments if takesValue is true.
+	eval func(args []string, index int, v interface{}) (interface{}, error)
+	// impure holds whether the keyword performs I/O or
+	// produces a nondeterministic value.
+	impure bool
+	// parse, if non-nil, is used instead of eval for keywords
+	// that need the parser's state. It consumes the keyword's
+	// arguments itself and returns its value, given the index
+	// of the keyword.
+	parse func(p *parser, start int) (interface{}, error)
+	// noExpand holds whether the arguments are taken
+	// literally, so that @file references and stdin, env,
+	// cmd, http and fetch arguments are left unexpanded.
+	noExpand bool
+	// hasOptionalArg reports whether the given argument fills
+	// the keyword's optional argument rather than the one after.
+	// It's needed only by keywords that have an optional argument.
+	hasOptionalArg func(arg string) bool
+}
+
+// expandsArg reports whether the keyword argument with the
+// given description is expanded by expandArg. Optional
+// arguments are always taken literally.
+func (kw *keyword) expandsArg(desc string) bool {
+	return !kw.noExpand && !isOptionalArg(desc)
+}
+
+var keywords = map[string]*keyword{
+	"null": {
+		eval: func([]string, int, interface{}) (interface{}, error) {
+			return nil, nil
+		},
+	},
+	"true": {
+		eval: func([]string, int, interface{}) (interface{}, error) {
+			return true, nil
+		},
+	},
+	"false": {
+		eval: func([]string, int, interface{}) (interface{}, error) {
+			return false, nil
+		},
+	},
+	"str": {
+		args:     []string{"str argument"},
+		noExpand: true,
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			return args[0], nil
+		},
+	},
+	"json": {
+		args: []string{"json argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			dec := json.NewDecoder(strings.NewReader(args[0]))
+			dec.UseNumber()
+			var x interface{}
+			if err := dec.Decode(&x); err != nil {
+				return nil, fmt.Errorf("cannot unmarshal json %q at argument %d", args[0], index)
+			}
+			return x, nil
+		},
+	},
+	"jsonstr": {
+		takesValue: true,
+		eval: func(_ []string, index int, v interface{}) (interface{}, error) {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot marshal value at argument %d: %v", index, err)
+			}
+			return string(data), nil
+		},
+	},
+	"num": {
+		args: []string{"numeric value"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			a := args[0]
+			n, err := strconv.ParseFloat(a, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at argument %d", a, index)
+			}
+			if math.IsInf(n, 0) || math.IsNaN(n) {
+				return nil, fmt.Errorf("%q is not a regular floating point number and cannot be encoded to JSON", a)
+			}
+			// Preserve the original form of the number to avoid losing precision.
+			return json.Number(a), nil
+		},
+	},
+	"int": {
+		args: []string{"integer value"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			n, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				if err.(*strconv.NumError).Err == strconv.ErrRange {
+					return nil, fmt.Errorf("integer %q at argument %d is out of range for 64 bits", args[0], index)
+				}
+				return nil, fmt.Errorf("invalid integer %q at argument %d", args[0], index)
+			}
+			return json.Number(strconv.FormatInt(n, 10)), nil
+		},
+	},
+	"bool": {
+		args: []string{"boolean value"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean at argument %d: %v", index, err)
+			}
+			return v, nil
+		},
+	},
+	"numrange": {
+		args:       []string{"lower bound", "upper bound"},
+		takesValue: true,
+		eval:       evalNumRange,
+	},
+	"maxlen": {
+		args: []string{"maximum length", "string"},
+		eval: evalMaxLen,
+	},
+	"maxbytes": {
+		args: []string{"maximum size", "string"},
+		eval: evalMaxBytes,
+	},
+	"email": {
+		takesValue: true,
+		eval:       evalEmail,
+	},
+	"phone": {
+		args: []string{"region", "phone number"},
+		eval: evalPhone,
+	},
+	"time": {
+		takesValue: true,
+		eval:       evalTime,
+	},
+	"esc": {
+		takesValue: true,
+		eval:       evalEsc,
+	},
+	"base64": {
+		args: []string{"string to encode"},
+		eval: evalBase64,
+	},
+	"b64d": {
+		args: []string{"base64 string"},
+		eval: evalB64d,
+	},
+	"urlval": {
+		takesValue: true,
+		eval:       evalURL("urlval", false),
+	},
+	"urlparts": {
+		takesValue: true,
+		eval:       evalURL("urlparts", true),
+	},
+	"hostname": {
+		takesValue: true,
+		eval:       evalHostname("hostname", "host name", checkHostname),
+	},
+	"dnslabel": {
+		takesValue: true,
+		eval:       evalHostname("dnslabel", "DNS label", checkDNSLabel),
+	},
+	"color": {
+		takesValue: true,
+		eval:       evalColor,
+	},
+	"latlon": {
+		args: []string{"latitude", "longitude"},
+		eval: evalLatLon,
+	},
+	"geopoint": {
+		args: []string{"latitude", "longitude"},
+		eval: evalGeoPoint,
+	},
+	"geobbox": {
+		args: []string{"west longitude", "south latitude", "east longitude", "north latitude"},
+		eval: evalGeoBBox,
+	},
+	"lines": {
+		takesValue: true,
+		eval:       evalLines,
+	},
+	"iso-country":  isoCodeKeyword("iso-country", "country", isoCountries, true),
+	"iso-lang":     isoCodeKeyword("iso-lang", "language", isoLanguages, false),
+	"iso-currency": isoCodeKeyword("iso-currency", "currency", isoCurrencies, true),
+	"float": {
+		args:           []string{"[float format]", "numeric value"},
+		parse:          (*parser).floatArg,
+		hasOptionalArg: isFloatFormat,
+	},
+	"counter": {
+		args:           []string{"[counter name]"},
+		parse:          (*parser).counterValue,
+		hasOptionalArg: isCounterName,
+	},
+	"stdin": {
+		impure: true,
+		parse: func(p *parser, start int) (interface{}, error) {
+			return p.readStdin(start)
+		},
+	},
+	"env": {
+		args:     []string{"environment variable name"},
+		impure:   true,
+		noExpand: true,
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			return lookupEnv(args[0], index)
+		},
+	},
+	"http": {
+		args:     []string{"URL"},
+		impure:   true,
+		noExpand: true,
+		parse:    (*parser).fetchValue,
+	},
+	"fetch": {
+		args:     []string{"URL"},
+		impure:   true,
+		noExpand: true,
+		parse:    (*parser).fetchValue,
+	},
+	"ulid": {
+		impure: true,
+		eval:   evalULID,
+	},
+	"ksuid": {
+		impure: true,
+		eval:   evalKSUID,
+	},
+	"fake": {
+		args:   []string{"fake data kind"},
+		impure: true,
+		eval:   evalFake,
+	},
+	"choice": {
+		takesValue: true,
+		impure:     true,
+		eval:       evalChoice,
+	},
+	"wchoice": {
+		takesValue: true,
+		impure:     true,
+		eval:       evalWChoice,
+	},
+	"cmd": {
+		args:     []string{"command"},
+		impure:   true,
+		noExpand: true,
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			return runCmd(args[0], index)
+		},
+	},
+	"glob": {
+		takesValue: true,
+		impure:     true,
+		eval:       evalGlob,
+	},
+	"file": {
+		args:     []string{"file name"},
+		impure:   true,
+		noExpand: true,
+		eval:     evalFile,
+	},
+	"base64file": {
+		args:     []string{"file name"},
+		impure:   true,
+		noExpand: true,
+		eval:     evalBase64File,
+	},
+	"jsonfile": {
+		args:     []string{"file name"},
+		impure:   true,
+		noExpand: true,
+		eval:     evalJSONFile,
+	},
+	"yaml": {
+		args: []string{"yaml argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseYAML(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot unmarshal yaml %q at argument %d: %v", args[0], index, err)
+			}
+			return v, nil
+		},
+	},
+	"toml": {
+		args: []string{"toml argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseTOML(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot unmarshal toml %q at argument %d: %v", args[0], index, err)
+			}
+			return v, nil
+		},
+	},
+	"csv": {
+		args: []string{"csv argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseCSV(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse csv at argument %d: %v", index, err)
+			}
+			return v, nil
+		},
+	},
+	"xml": {
+		args: []string{"xml argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseXML(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse xml at argument %d: %v", index, err)
+			}
+			return v, nil
+		},
+	},
+	"msgpack": {
+		args: []string{"hex or base64 msgpack argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			return decodeBinaryKeyword("msgpack", decodeMsgpack, args[0], index)
+		},
+	},
+	"cbor": {
+		args: []string{"hex or base64 cbor argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			return decodeBinaryKeyword("cbor", decodeCBOR, args[0], index)
+		},
+	},
+	"json5": {
+		args: []string{"json5 argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseJSON5(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot unmarshal json5 %q at argument %d: %v", args[0], index, err)
+			}
+			return v, nil
+		},
+	},
+	"hjson": {
+		args: []string{"hjson argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseHjson(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot unmarshal hjson %q at argument %d: %v", args[0], index, err)
+			}
+			return v, nil
+		},
+	},
+	"ndjson": {
+		args: []string{"ndjson argument"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseNDJSON(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cannot unmarshal ndjson at argument %d: %v", index, err)
+			}
+			return v, nil
+		},
+	},
+	"qs": {
+		args: []string{"query string"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseQuery(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid query string %q at argument %d: %v", args[0], index, err)
+			}
+			return v, nil
+		},
+	},
+	"labels": {
+		args: []string{"label set"},
+		eval: func(args []string, index int, _ interface{}) (interface{}, error) {
+			v, err := parseLabels(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid label set %q at argument %d: %v", args[0], index, err)
+			}
+			return v, nil
+		},
+	},
+}
+
+// frameKind represents the kind of a partially parsed construct.
+type frameKind int
+
+const (
+	// valuesFrame holds the top level sequence of values.
+	valuesFrame frameKind = iota
+	// topObjectFrame holds an object that makes up the
+	// whole command line, which has no closing ].
+	topObjectFrame
+	// objectFrame holds an object delimited by [ and ].
+	objectFrame
+	// arrayFrame holds an array delimited by .[ and ].
+	arrayFrame
+	// keywordFrame holds a keyword that is waiting for its value.
+	keywordFrame
+)
+
+// A frame holds the state of a partially parsed construct.
+type frame struct {
+	kind frameKind
+	// start holds the index of the argument that started the frame.
+	start int
+
+	// obj holds the members of an object parsed so far.
+	obj map[string]interface{}
+	// key holds the key of the member being parsed when haveKey is true.
+	key     string
+	haveKey bool
+
+	// elems holds the values parsed so far in an array
+	// or the top level sequence of values.
+	elems []interface{}
+
+	// kw holds the keyword of a keyword frame,
+	// and args its arguments.
+	kw   *keyword
+	args []string
+	// unknown holds whether the keyword's value can't
+	// be determined because the parser is checking.
+	unknown bool
+}
+
+// argClass classifies the syntactic role of an argument.
+type argClass int
+
+const (
+	classValue argClass = iota
+	classKey
+	classDelim
+	classKeyword
+)
+
+// parser implements an iterative parser for the argument
+// grammar. Rather than recursing, it maintains an explicit stack
+// of partially parsed constructs, which means that the set of
+// acceptable arguments at any point can be determined
+// from the frame on the top of the stack (see expected).
+type parser struct {
+	index int
+	args  []string
+	stack []*frame
+	// vals holds the result of the parse once it has completed.
+	vals []interface{}
+	// classes holds the class of each argument.
+	// Arguments that have not been parsed are
+	// classified as values.
+	classes []argClass
+
+	// When trackOrigins is true, origins holds, for each
+	// parsed top level value, a map from the JSON Pointer
+	// (RFC 6901) of each leaf value within it to the index
+	// of the argument that produced it. Keyword values
+	// and empty objects and arrays count as leaves.
+	trackOrigins bool
+	origins      []map[string]int
+	curOrigins   map[string]int
+
+	// comments holds, for each parsed top level value, a map
+	// from the JSON Pointer of each object member that was
+	// preceded by a comment directive to the comment text.
+	comments    []map[string]string
+	curComments map[string]string
+	// comment holds the text of a comment directive that
+	// applies to the next key.
+	comment     string
+	haveComment bool
+
+	// homogeneous holds whether all the elements of an
+	// array must have the same type.
+	homogeneous bool
+
+	// hermetic holds whether impure keywords are disallowed.
+	hermetic bool
+
+	// check holds whether the arguments are only being checked,
+	// as by the language server, so that no I/O is performed.
+	// It's used together with freeze: impure keywords and
+	// expansions that need I/O produce a *lazyValue, as do
+	// keywords that use their values.
+	check bool
+	// values, if non-nil, records the value that starts
+	// at each argument index.
+	values map[int]interface{}
+
+	// prefetched holds the files that have been read
+	// ahead of time by prefetchFiles.
+	prefetched map[prefetchKey]*prefetch
+
+	// ctx, if non-nil, is canceled to abandon I/O performed
+	// by keywords, such as fetching a URL.
+	ctx context.Context
+
+	// stdin holds the reader used for the stdin value. If it's nil,
+	// standard input is not available. stdinData holds its contents
+	// once read, so that it can be used more than once.
+	stdin     io.Reader
+	stdinData *string
+
+	// counters holds the most recent value of each
+	// counter used by the counter keyword.
+	counters map[string]int64
+
+	// params holds the values of the parameters used by the
+	// lazy keyword, and paramArgs holds, for each lazy argument
+	// whose parameter has a value, the arguments of that value.
+	// When freeze is true, parameters without a value
+	// produce a *lazyValue rather than an error.
+	params    map[string]string
+	paramArgs map[int][]string
+	freeze    bool
+
+	// warnf, if non-nil, is called to report arguments
+	// that look like mistyped keywords.
+	warnf func(format string, arg ...interface{})
+}
+
+type syntaxError struct {
+	e string
+}
+
+func (e *syntaxError) Error() string {
+	return e.e
+}
+
+func syntaxErrorf(format string, arg ...interface{}) error {
+	return &syntaxError{
+		e: fmt.Sprintf(format, arg...),
+	}
+}
+
+// parse parses the given arguments and returns the values they represent.
+func parse(args []string) ([]interface{}, error) {
+	return newParser(args).run()
+}
+
+func newParser(args []string) *parser {
+	p := &parser{
+		args:    args,
+		classes: make([]argClass, len(args)),
+	}
+	top := &frame{
+		kind: valuesFrame,
+	}
+	if a, ok := p.peek(); ok && (isKey(a) || a == "comment" && len(args) > 2 && isKey(args[2])) {
+		// It's an object key (possibly preceded by a comment);
+		// parse the whole command line as an object.
+		top.kind = topObjectFrame
+		top.obj = make(map[string]interface{})
+	}
+	p.stack = []*frame{top}
+	return p
+}
+
+// run runs the parser to completion and returns the parsed values.
+func (p *parser) run() ([]interface{}, error) {
+	if !p.hermetic && !p.check {
+		p.prefetchFiles()
+	}
+	for p.stack != nil {
+		if err := p.step(); err != nil {
+			return nil, err
+		}
+	}
+	return p.vals, nil
+}
+
+// step consumes one or more arguments. When the parse
+// has completed, it sets p.stack to nil and p.vals to the result.
+func (p *parser) step() error {
+	f := p.stack[len(p.stack)-1]
+	a, ok := p.peek()
+	switch f.kind {
+	case valuesFrame:
+		if !ok {
+			p.stack, p.vals = nil, f.elems
+			return nil
+		}
+		if a == "]" {
+			return syntaxErrorf("unexpected argument ] at %d, expected value", p.index)
+		}
+	case topObjectFrame, objectFrame:
+		if f.haveKey {
+			break
+		}
+		switch {
+		case !ok && f.kind == topObjectFrame:
+			p.stack, p.vals = nil, []interface{}{f.obj}
+			p.completeValue()
+			return nil
+		case !ok:
+			return p.unexpectedEnd()
+		case a == "]" && f.kind == topObjectFrame:
+			return syntaxErrorf("unexpected argument %q at %d", a, p.index)
+		case a == "]":
+			p.classes[p.index] = classDelim
+			p.next()
+			return p.pop()
+		case a == "comment":
+			p.classes[p.index] = classKeyword
+			p.next()
+			text, err := p.mustNext("comment text")
+			if err != nil {
+				return err
+			}
+			if next, ok := p.peek(); !ok || !isKey(next) {
+				return syntaxErrorf("comment at argument %d must be followed by a key", p.index-2)
+			}
+			p.comment, p.haveComment = text, true
+			return nil
+		case a == "key":
+			p.classes[p.index] = classKeyword
+			p.next()
+			key, err := p.mustNext("key argument")
+			if err != nil {
+				return err
+			}
+			p.classes[p.index-1] = classKey
+			f.key = key
+		case strings.HasSuffix(a, ":"):
+			p.classes[p.index] = classKey
+			p.next()
+			f.key = a[:len(a)-1]
+		default:
+			return syntaxErrorf("expected object key (ending in :) or 'key' keyword at argument %d, but got %q", p.index, a)
+		}
+		f.haveKey = true
+		if p.haveComment {
+			p.recordComment(p.comment)
+			p.comment, p.haveComment = "", false
+		}
+		return nil
+	case arrayFrame:
+		if ok && a == "]" {
+			p.classes[p.index] = classDelim
+			p.next()
+			return p.pop()
+		}
+	}
+	return p.startValue()
+}
+
+// startValue consumes the argument at the start of a value.
+func (p *parser) startValue() error {
+	if _, ok := p.peek(); !ok {
+		return p.unexpectedEnd()
+	}
+	start := p.index
+	a, _ := p.next()
+	switch a {
+	case "[", ".[":
+		p.classes[start] = classDelim
+	}
+	switch a {
+	case "[":
+		p.push(&frame{
+			kind:  objectFrame,
+			start: start,
+			obj:   make(map[string]interface{}),
+		})
+		return nil
+	case ".[":
+		p.push(&frame{
+			kind:  arrayFrame,
+			start: start,
+			elems: []interface{}{},
+		})
+		return nil
+	case "]":
+		return syntaxErrorf("unexpected argument ] at %d, expected value", start)
+	}
+	if a == "-" {
+		// A lone - is shorthand for stdin.
+		a = "stdin"
+	}
+	if kw := keywords[a]; kw != nil {
+		if kw.impure && p.hermetic {
+			return fmt.Errorf("%s at argument %d is not allowed because it performs I/O or is nondeterministic", a, start)
+		}
+		if len(kw.args) > 0 || kw.takesValue {
+			p.classes[start] = classKeyword
+		}
+		unknown := kw.impure && p.check
+		if kw.parse != nil && !unknown {
+			v, err := kw.parse(p, start)
+			if err == errUnknown {
+				return p.deliver(p.unknownValue(start), start, true)
+			}
+			if err != nil {
+				return err
+			}
+			return p.deliver(v, start, true)
+		}
+		args := make([]string, len(kw.args))
+		for i, what := range kw.args {
+			arg, err := p.mustNext(what)
+			if err != nil {
+				return err
+			}
+			if kw.noExpand || unknown {
+				args[i] = arg
+				continue
+			}
+			args[i], err = p.expandArg(arg, p.index-1)
+			if err == errUnknown {
+				unknown = true
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if kw.takesValue {
+			p.push(&frame{
+				kind:    keywordFrame,
+				start:   start,
+				kw:      kw,
+				args:    args,
+				unknown: unknown,
+			})
+			return nil
+		}
+		if unknown {
+			return p.deliver(p.unknownValue(start), start, true)
+		}
+		v, err := p.readAhead(a, start+1, func() (interface{}, error) {
+			return kw.eval(args, start+1, nil)
+		})
+		if err != nil {
+			return err
+		}
+		return p.deliver(v, start, true)
+	}
+	if isKey(a) {
+		return syntaxErrorf("argument %d; expected value, got key", start)
+	}
+	if p.warnf != nil {
+		p.checkKeyword(a, start)
+	}
+	if strings.HasPrefix(a, "@") {
+		// The contents of a file are always a string
+		// unless asserted otherwise.
+		s, err := p.expandArg(a, start)
+		if err == errUnknown {
+			return p.deliver(p.unknownValue(start), start, true)
+		}
+		if err != nil {
+			return err
+		}
+		return p.deliver(s, start, true)
+	}
+	// If it looks like a float, treat it as a float.
+	if n, err := strconv.ParseFloat(a, 64); err == nil {
+		return p.deliver(n, start, true)
+	}
+	return p.deliver(a, start, true)
+}
+
+func (p *parser) push(f *frame) {
+	p.stack = append(p.stack, f)
+}
+
+// pop removes the frame for a completed object or array
+// and delivers its value.
+func (p *parser) pop() error {
+	f := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	if f.kind == objectFrame {
+		return p.deliver(f.obj, f.start, len(f.obj) == 0)
+	}
+	return p.deliver(f.elems, f.start, len(f.elems) == 0)
+}
+
+// deliver delivers a completed value that started at the given
+// argument index to the frame on the top of the stack.
+// The leaf parameter reports whether the value is a leaf for
+// the purposes of origin tracking.
+func (p *parser) deliver(v interface{}, start int, leaf bool) error {
+	for {
+		f := p.stack[len(p.stack)-1]
+		if p.values != nil {
+			p.values[start] = v
+		}
+		if leaf && f.kind != keywordFrame {
+			p.recordOrigin(start)
+		}
+		switch f.kind {
+		case valuesFrame:
+			f.elems = append(f.elems, v)
+			p.completeValue()
+			return nil
+		case arrayFrame:
+			if p.homogeneous && len(f.elems) > 0 {
+				if want, got := valueKind(f.elems[0]), valueKind(v); got != want {
+					return fmt.Errorf("array element at argument %d has type %s, but earlier elements have type %s", start, got, want)
+				}
+			}
+			f.elems = append(f.elems, v)
+			return nil
+		case topObjectFrame, objectFrame:
+			f.obj[f.key] = v
+			f.key, f.haveKey = "", false
+			return nil
+		case keywordFrame:
+			p.stack = p.stack[:len(p.stack)-1]
+			if p.freeze && (f.unknown || containsLazy(v)) {
+				// The keyword can't be evaluated until
+				// the value's parameters are known.
+				v = &lazyValue{args: p.rawArgs(f.start, p.index)}
+			} else {
+				var err error
+				v, err = f.kw.eval(f.args, f.start+1, v)
+				if err != nil {
+					return err
+				}
+			}
+			start, leaf = f.start, true
+		}
+	}
+}
+
+// recordOrigin records that the value about to be delivered
+// to the top of the stack was produced by the argument at
+// the given index. Values within keyword arguments are
+// not recorded because they do not appear in the result.
+func (p *parser) recordOrigin(index int) {
+	if !p.trackOrigins {
+		return
+	}
+	path, ok := p.path()
+	if !ok {
+		return
+	}
+	if p.curOrigins == nil {
+		p.curOrigins = make(map[string]int)
+	}
+	p.curOrigins[path] = index
+}
+
+// recordComment records a comment for the object
+// member whose key has just been parsed.
+func (p *parser) recordComment(text string) {
+	path, ok := p.path()
+	if !ok {
+		return
+	}
+	if p.curComments == nil {
+		p.curComments = make(map[string]string)
+	}
+	p.curComments[path] = text
+}
+
+// path returns the JSON Pointer of the value about to be
+// delivered to the top of the stack within its top level
+// value. It reports false if the value is within a keyword's
+// argument and hence does not appear in the result.
+func (p *parser) path() (string, bool) {
+	var path strings.Builder
+	for _, f := range p.stack {
+		switch f.kind {
+		case keywordFrame:
+			return "", false
+		case topObjectFrame, objectFrame:
+			path.WriteString("/")
+			path.WriteString(pointerEscaper.Replace(f.key))
+		case arrayFrame:
+			path.WriteString("/")
+			path.WriteString(strconv.Itoa(len(f.elems)))
+		}
+	}
+	return path.String(), true
+}
+
+// completeValue is called when a top level value is complete.
+func (p *parser) completeValue() {
+	p.comments = append(p.comments, p.curComments)
+	p.curComments = nil
+	if !p.trackOrigins {
+		return
+	}
+	if p.curOrigins == nil {
+		p.curOrigins = make(map[string]int)
+	}
+	p.origins = append(p.origins, p.curOrigins)
+	p.curOrigins = nil
+}
+
+// pointerEscaper escapes a reference token in a JSON Pointer.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// expected returns descriptions of the arguments that
+// are acceptable at the current position. The empty string
+// means that the arguments may end here.
+func (p *parser) expected() []string {
+	f := p.stack[len(p.stack)-1]
+	switch f.kind {
+	case valuesFrame:
+		return []string{"value", ""}
+	case topObjectFrame:
+		if f.haveKey {
+			return []string{"value"}
+		}
+		return []string{"key", ""}
+	case objectFrame:
+		if f.haveKey {
+			return []string{"value"}
+		}
+		return []string{"key", "]"}
+	case arrayFrame:
+		return []string{"value", "]"}
+	}
+	return []string{"value"}
+}
+
+func (p *parser) unexpectedEnd() error {
+	var expected []string
+	for _, e := range p.expected() {
+		if e != "" {
+			expected = append(expected, e)
+		}
+	}
+	return p.endError(strings.Join(expected, " or "))
+}
+
+func (p *parser) endError(expected string) error {
+	return syntaxErrorf("unexpected end of arguments (expected %s)", expected)
+}
+
+func (p *parser) mustNext(expected string) (string, error) {
+	a, ok := p.next()
+	if !ok {
+		return "", p.endError(expected)
+	}
+	return a, nil
+}
+
+func (p *parser) next() (string, bool) {
+	a, ok := p.peek()
+	if !ok {
+		return "", false
+	}
+	p.index++
+	return a, true
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.index >= len(p.args) {
+		return "", false
+	}
+	return p.args[p.index], true
+}
+
+// isKey reports whether the argument a introduces an object key.
+func isKey(a string) bool {
+	return strings.HasSuffix(a, ":") || a == "key"
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	pasteKeys  = flag.String("paste", "", "read lines of whitespace-separated fields from standard input and print each line as an object with the given comma-separated keys")
+	pasteTypes = flag.String("paste-types", "", "comma-separated types (str, num, bool or json) of the -paste fields; an empty type infers the type as for an argument")
+)
+
+// paste reads lines of whitespace-separated fields from r and
+// encodes each non-empty line as an object keyed by the -paste keys.
+func paste(ctx context.Context, r io.Reader, enc encoder) error {
+	keys := strings.Split(*pasteKeys, ",")
+	types := make([]string, len(keys))
+	if *pasteTypes != "" {
+		types = strings.Split(*pasteTypes, ",")
+		if len(types) != len(keys) {
+			return fmt.Errorf("-paste-types has %d types but -paste has %d keys", len(types), len(keys))
+		}
+		for _, t := range types {
+			switch t {
+			case "", "str", "num", "bool", "json":
+			default:
+				return fmt.Errorf("unknown -paste-types type %q", t)
+			}
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != len(keys) {
+			return fmt.Errorf("line %d: found %d fields, expected %d", line, len(fields), len(keys))
+		}
+		obj := make(map[string]interface{})
+		for i, field := range fields {
+			v, err := pasteValue(types[i], field)
+			if err != nil {
+				return fmt.Errorf("line %d: field %d: %v", line, i+1, err)
+			}
+			obj[keys[i]] = v
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// pasteValue returns the value of a -paste field with the given type.
+func pasteValue(typ, field string) (interface{}, error) {
+	switch typ {
+	case "str":
+		return field, nil
+	case "num":
+		n, err := strconv.ParseFloat(field, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, fmt.Errorf("invalid number %q", field)
+		}
+		return json.Number(field), nil
+	case "json":
+		dec := json.NewDecoder(strings.NewReader(field))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal json %q", field)
+		}
+		return v, nil
+	case "bool":
+		return strconv.ParseBool(field)
+	}
+	switch field {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseFloat(field, 64); err == nil {
+		return n, nil
+	}
+	return field, nil
+}
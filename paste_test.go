@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// valuesEncoder is an encoder that records the values encoded.
+type valuesEncoder struct {
+	vals []interface{}
+}
+
+func (e *valuesEncoder) Encode(v interface{}) error {
+	e.vals = append(e.vals, v)
+	return nil
+}
+
+var pasteTests = []struct {
+	testName    string
+	keys        string
+	types       string
+	input       string
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "inferred-types",
+	keys:     "name,age,admin",
+	input:    "alice 30 true\nbob\tnone  null\n",
+	expect: []interface{}{
+		map[string]interface{}{"name": "alice", "age": 30.0, "admin": true},
+		map[string]interface{}{"name": "bob", "age": "none", "admin": nil},
+	},
+}, {
+	testName: "explicit-types",
+	keys:     "id,n,ok,extra",
+	types:    "str,num,bool,json",
+	input:    "0123 1e3 1 [1,2]\n\n",
+	expect: []interface{}{
+		map[string]interface{}{
+			"id":    "0123",
+			"n":     json.Number("1e3"),
+			"ok":    true,
+			"extra": []interface{}{json.Number("1"), json.Number("2")},
+		},
+	},
+}, {
+	testName:    "wrong-field-count",
+	keys:        "a,b",
+	input:       "1 2\n3\n",
+	expectError: `line 2: found 1 fields, expected 2`,
+}, {
+	testName:    "bad-number",
+	keys:        "a",
+	types:       "num",
+	input:       "x\n",
+	expectError: `line 1: field 1: invalid number "x"`,
+}, {
+	testName:    "type-count-mismatch",
+	keys:        "a,b",
+	types:       "num",
+	expectError: `-paste-types has 1 types but -paste has 2 keys`,
+}, {
+	testName:    "unknown-type",
+	keys:        "a",
+	types:       "int32",
+	expectError: `unknown -paste-types type "int32"`,
+}}
+
+func TestPaste(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range pasteTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(pasteKeys, test.keys)
+			c.Patch(pasteTypes, test.types)
+			var enc valuesEncoder
+			err := paste(context.Background(), strings.NewReader(test.input), &enc)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(enc.vals, deepEquals, test.expect)
+		})
+	}
+}
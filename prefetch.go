@@ -0,0 +1,133 @@
+package main
+
+import "os"
+
+// maxConcurrentReads holds the maximum number of files
+// that are read at the same time by prefetchFiles.
+const maxConcurrentReads = 8
+
+// fileKeywords holds the evaluation functions of the keywords
+// whose argument names a file to be read.
+var fileKeywords = map[string]func(args []string, index int, v interface{}) (interface{}, error){
+	"file":       evalFile,
+	"base64file": evalBase64File,
+	"jsonfile":   evalJSONFile,
+}
+
+// prefetchKey identifies a file read ahead of time: the index
+// of the argument naming the file and the keyword that it's
+// an argument of, or "@" for an @file reference.
+type prefetchKey struct {
+	index   int
+	keyword string
+}
+
+// A prefetch holds the result of reading a file ahead of time.
+// The result is available when done is closed.
+type prefetch struct {
+	done chan struct{}
+	v    interface{}
+	err  error
+}
+
+// prefetchFiles starts reading the files named by file keywords
+// and @file references, with at most maxConcurrentReads being read
+// at once, so that documents assembled from many files are quick
+// to make. The values are still evaluated in order by the parser,
+// which waits for each read as it needs it.
+//
+// The arguments are scanned ahead of the parser using the
+// keyword table, so only arguments that the parser will treat
+// as naming a file are read: literal arguments such as those
+// of str, key and comment are left alone.
+func (p *parser) prefetchFiles() {
+	sem := make(chan struct{}, maxConcurrentReads)
+	start := func(key prefetchKey, read func() (interface{}, error)) {
+		f := &prefetch{
+			done: make(chan struct{}),
+		}
+		if p.prefetched == nil {
+			p.prefetched = make(map[prefetchKey]*prefetch)
+		}
+		p.prefetched[key] = f
+		go func() {
+			sem <- struct{}{}
+			f.v, f.err = read()
+			<-sem
+			close(f.done)
+		}()
+	}
+	startAtFile := func(index int) {
+		a := p.args[index]
+		if len(a) < 2 || a[0] != '@' || a[1] == '@' || !isRegularFile(a[1:]) {
+			return
+		}
+		start(prefetchKey{index, "@"}, func() (interface{}, error) {
+			return readAtFile(a[1:], index)
+		})
+	}
+	args := p.args
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "key" || a == "comment" {
+			// The following argument is taken literally.
+			i++
+			continue
+		}
+		kw := keywords[a]
+		if kw == nil {
+			if !isKey(a) {
+				startAtFile(i)
+			}
+			continue
+		}
+		if eval := fileKeywords[a]; eval != nil {
+			if i+1 < len(args) && isRegularFile(args[i+1]) {
+				path, index := args[i+1], i+1
+				start(prefetchKey{index, a}, func() (interface{}, error) {
+					return eval([]string{path}, index, nil)
+				})
+			}
+			i++
+			continue
+		}
+		for _, desc := range kw.args {
+			if i+1 >= len(args) {
+				break
+			}
+			if isOptionalArg(desc) && !kw.hasOptionalArg(args[i+1]) {
+				continue
+			}
+			i++
+			if !kw.expandsArg(desc) {
+				continue
+			}
+			switch args[i] {
+			case "env", "cmd", "http", "fetch":
+				// The following argument is taken literally.
+				i++
+			default:
+				startAtFile(i)
+			}
+		}
+	}
+}
+
+// readAhead returns the result of reading the file named by the
+// argument at the given index for the given keyword (or "@"). If
+// prefetchFiles started reading it, it waits for that to complete;
+// otherwise it calls read.
+func (p *parser) readAhead(keyword string, index int, read func() (interface{}, error)) (interface{}, error) {
+	f := p.prefetched[prefetchKey{index, keyword}]
+	if f == nil {
+		return read()
+	}
+	<-f.done
+	return f.v, f.err
+}
+
+// isRegularFile reports whether path names a regular file.
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
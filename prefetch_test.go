@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPrefetchFiles(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	const n = 50
+	var args []string
+	expect := make(map[string]interface{})
+	for i := 0; i < n; i++ {
+		file := filepath.Join(dir, fmt.Sprint(i))
+		err := ioutil.WriteFile(file, []byte(fmt.Sprintf("%d\n", i)), 0666)
+		c.Assert(err, qt.Equals, nil)
+		key := fmt.Sprintf("k%d", i)
+		switch i % 3 {
+		case 0:
+			args = append(args, key+":", "file", file)
+			expect[key] = fmt.Sprintf("%d\n", i)
+		case 1:
+			args = append(args, key+":", "jsonfile", file)
+			expect[key] = json.Number(strconv.Itoa(i))
+		case 2:
+			args = append(args, key+":", "@"+file)
+			expect[key] = strconv.Itoa(i)
+		}
+	}
+	p := newParser(args)
+	vals, err := p.run()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(vals, qt.DeepEquals, []interface{}{expect})
+	c.Assert(p.prefetched, qt.HasLen, n)
+}
+
+func TestPrefetchFilesNotNeeded(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	err := ioutil.WriteFile(filepath.Join(dir, "a"), []byte("x\n"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	err = ioutil.WriteFile(filepath.Join(dir, "@a"), []byte("y"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	err = ioutil.WriteFile(filepath.Join(dir, "x:"), []byte("z"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	err = ioutil.WriteFile(filepath.Join(dir, "n"), []byte("3"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	wd, err := os.Getwd()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(os.Chdir(dir), qt.Equals, nil)
+	defer os.Chdir(wd)
+
+	// Arguments in literal positions aren't read even
+	// when they name a file.
+	p := newParser([]string{
+		"comment", "@a",
+		"key", "@a", "str", "@a",
+		"@x:", "file", "@a",
+		"b:", "num", "@n",
+		"c:", "counter", "@a",
+		"d:", "str", "file", "e:", "str", "a",
+	})
+	vals, err := p.run()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(vals, qt.DeepEquals, []interface{}{map[string]interface{}{
+		"@a": "@a",
+		"@x": "y",
+		"b":  json.Number("3"),
+		"c":  1.0,
+		"d":  "file",
+		"e":  "a",
+	}})
+	keys := make(map[prefetchKey]bool)
+	for key := range p.prefetched {
+		keys[key] = true
+	}
+	c.Assert(keys, qt.DeepEquals, map[prefetchKey]bool{
+		{8, "file"}: true,
+		{11, "@"}:   true,
+	})
+}
+
+func TestPrefetchFilesHermetic(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "a")
+	err := ioutil.WriteFile(file, []byte("x"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	p := newParser([]string{"@" + file})
+	p.hermetic = true
+	_, err = p.run()
+	c.Assert(err, qt.ErrorMatches, `file reference .* at argument 0 is not allowed because it performs I/O`)
+	c.Assert(p.prefetched, qt.HasLen, 0)
+}
+
+func TestKeywordsWithOptionalArgs(t *testing.T) {
+	c := qt.New(t)
+	// prefetchFiles relies on hasOptionalArg to find
+	// out whether an optional argument is present.
+	for name, kw := range keywords {
+		for _, desc := range kw.args {
+			if isOptionalArg(desc) {
+				c.Assert(kw.hasOptionalArg, qt.Not(qt.IsNil), qt.Commentf("%s", name))
+			}
+		}
+	}
+}
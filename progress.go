@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var progress = flag.Bool("progress", false, "periodically print the number of values and bytes processed, with an estimated time remaining when reading a file from standard input, to standard error")
+
+// progressInterval holds the time between progress reports.
+const progressInterval = time.Second
+
+// progressReporter is an encoder that counts the values passed to
+// another encoder. While it's running, it reports progress
+// periodically regardless of how long each value takes to encode,
+// reading the counters from another goroutine, so they are
+// accessed atomically.
+type progressReporter struct {
+	// values counts the values encoded. It's first in the
+	// struct so that it's aligned for atomic access.
+	values int64
+
+	encoder
+	w   io.Writer
+	now func() time.Time
+
+	start time.Time
+
+	// out counts the bytes written by the encoder.
+	out *countingWriter
+	// in counts the bytes read from the input, if any.
+	in *countingReader
+	// inSize holds the total size of the input,
+	// or zero if it isn't known.
+	inSize int64
+
+	stopTicker func()
+	done       chan struct{}
+	finished   chan struct{}
+}
+
+func newProgressReporter(enc encoder, out *countingWriter) *progressReporter {
+	return &progressReporter{
+		encoder: enc,
+		w:       os.Stderr,
+		now:     time.Now,
+		start:   time.Now(),
+		out:     out,
+	}
+}
+
+// countInput arranges for progress reports to include the number
+// of bytes read from f, and returns the reader to use in place of f.
+func (p *progressReporter) countInput(f *os.File) io.Reader {
+	p.in = &countingReader{r: f}
+	if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+		p.inSize = info.Size()
+	}
+	return p.in
+}
+
+func (p *progressReporter) Encode(v interface{}) error {
+	if err := p.encoder.Encode(v); err != nil {
+		return err
+	}
+	atomic.AddInt64(&p.values, 1)
+	return nil
+}
+
+// run starts printing a progress report every progressInterval
+// until stop is called.
+func (p *progressReporter) run() {
+	ticker := time.NewTicker(progressInterval)
+	p.stopTicker = ticker.Stop
+	p.watch(ticker.C)
+}
+
+// watch starts printing a progress report whenever a time
+// is received on tick, until stop is called.
+func (p *progressReporter) watch(tick <-chan time.Time) {
+	p.done = make(chan struct{})
+	p.finished = make(chan struct{})
+	go func() {
+		defer close(p.finished)
+		for {
+			select {
+			case <-tick:
+				p.report()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop stops the periodic progress reports started by run,
+// waiting for any report in progress to be printed.
+func (p *progressReporter) stop() {
+	if p.done == nil {
+		return
+	}
+	if p.stopTicker != nil {
+		p.stopTicker()
+	}
+	close(p.done)
+	<-p.finished
+	p.done = nil
+}
+
+// report prints a single progress report.
+func (p *progressReporter) report() {
+	msg := fmt.Sprintf("json: %d values, %d bytes written", atomic.LoadInt64(&p.values), atomic.LoadInt64(&p.out.n))
+	if p.in != nil {
+		in := atomic.LoadInt64(&p.in.n)
+		msg += fmt.Sprintf(", %d bytes read", in)
+		if p.inSize > 0 && in > 0 {
+			elapsed := p.now().Sub(p.start)
+			remaining := time.Duration(float64(elapsed) * float64(p.inSize-in) / float64(in))
+			msg += fmt.Sprintf(" (%d%%, eta %v)", in*100/p.inSize, remaining.Round(time.Second))
+		}
+	}
+	fmt.Fprintln(p.w, msg)
+}
+
+// countingWriter counts the bytes written to w. The count
+// is first in the struct so that it's aligned for atomic access.
+type countingWriter struct {
+	n int64
+	w io.Writer
+}
+
+func (w *countingWriter) Write(buf []byte) (int, error) {
+	n, err := w.w.Write(buf)
+	atomic.AddInt64(&w.n, int64(n))
+	return n, err
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	n int64
+	r io.Reader
+}
+
+func (r *countingReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestProgressReporter(t *testing.T) {
+	c := qt.New(t)
+	var out bytes.Buffer
+	report := &syncBuffer{}
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &progressReporter{
+		w: report,
+		now: func() time.Time {
+			return now
+		},
+		start: now,
+		out:   &countingWriter{w: &out},
+	}
+	p.encoder = newJSONEncoder(p.out)
+	p.in = &countingReader{r: strings.NewReader("0123456789")}
+	p.inSize = 10
+	tick := make(chan time.Time)
+	p.watch(tick)
+
+	// Encoding values doesn't print a report by itself.
+	_, err := p.in.Read(make([]byte, 4))
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(p.Encode("a"), qt.Equals, nil)
+	c.Assert(p.Encode(1.0), qt.Equals, nil)
+	c.Assert(report.String(), qt.Equals, "")
+
+	// A report is printed on each tick, with the time remaining
+	// estimated from the proportion of the input read so far.
+	now = now.Add(progressInterval * 2)
+	tick <- now
+	tick <- now
+	p.stop()
+	c.Assert(report.String(), qt.Equals, strings.Repeat("json: 2 values, 6 bytes written, 4 bytes read (40%, eta 3s)\n", 2))
+
+	// Reports are printed even when no values are being encoded,
+	// for example while waiting for input.
+	report.Reset()
+	tick = make(chan time.Time)
+	p.watch(tick)
+	_, err = p.in.Read(make([]byte, 3))
+	c.Assert(err, qt.Equals, nil)
+	tick <- now
+	p.stop()
+	c.Assert(report.String(), qt.Equals, "json: 2 values, 6 bytes written, 7 bytes read (70%, eta 1s)\n")
+
+	report.Reset()
+	_, err = ioutil.ReadAll(p.in)
+	c.Assert(err, qt.Equals, nil)
+	p.report()
+	c.Assert(report.String(), qt.Equals, "json: 2 values, 6 bytes written, 10 bytes read (100%, eta 0s)\n")
+}
+
+func TestProgressReporterRun(t *testing.T) {
+	c := qt.New(t)
+	report := &syncBuffer{}
+	p := newProgressReporter(newJSONEncoder(ioutil.Discard), &countingWriter{w: ioutil.Discard})
+	p.w = report
+	p.run()
+	p.stop()
+	// Stopping again has no effect.
+	p.stop()
+	c.Assert(p.Encode(1.0), qt.Equals, nil)
+	p.report()
+	c.Assert(report.String(), qt.Equals, "json: 1 values, 0 bytes written\n")
+}
+
+// syncBuffer is a bytes.Buffer that's safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(data)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var (
+	protoDesc    = flag.String("proto-desc", "", "in protobuf output, the file holding the FileDescriptorSet (as produced by protoc --descriptor_set_out) that defines the message type")
+	protoMessage = flag.String("proto-message", "", "in protobuf output, the fully qualified name of the message type to encode")
+)
+
+// protobufEncoder encodes a value in protobuf wire format
+// as the message type named by -proto-message. The value
+// is interpreted according to the protobuf JSON mapping.
+type protobufEncoder struct {
+	w       io.Writer
+	msg     protoreflect.MessageDescriptor
+	err     error
+	encoded bool
+}
+
+func newProtobufEncoder(w io.Writer) encoder {
+	msg, err := loadMessageDescriptor(*protoDesc, *protoMessage)
+	return &protobufEncoder{
+		w:   w,
+		msg: msg,
+		err: err,
+	}
+}
+
+func (e *protobufEncoder) Encode(v interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.encoded {
+		// Concatenated protobuf messages are indistinguishable
+		// from a single merged message.
+		return errors.New("protobuf output can hold only one value")
+	}
+	e.encoded = true
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	m := dynamicpb.NewMessage(e.msg)
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return err
+	}
+	data, err = proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// loadMessageDescriptor reads the FileDescriptorSet in the
+// given file and returns the descriptor for the named message.
+func loadMessageDescriptor(file, name string) (protoreflect.MessageDescriptor, error) {
+	if file == "" || name == "" {
+		return nil, errors.New("protobuf output requires -proto-desc and -proto-message")
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("cannot read descriptor set %s: %v", file, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s: %v", file, err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find message %q in %s", name, file)
+	}
+	msg, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message type", name)
+	}
+	return msg, nil
+}
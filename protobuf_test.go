@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var protobufTests = []struct {
+	testName    string
+	message     string
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "scalars-and-repeated",
+	message:  "test.Msg",
+	val: map[string]interface{}{
+		"name":  "x",
+		"count": 3.0,
+		"tags":  []interface{}{"a", "b"},
+	},
+	expect: "0a017810031a01611a0162",
+}, {
+	testName: "int64-as-string",
+	message:  "test.Msg",
+	val: map[string]interface{}{
+		"big": "300",
+	},
+	expect: "20ac02",
+}, {
+	testName:    "unknown-field",
+	message:     "test.Msg",
+	val:         map[string]interface{}{"other": 1.0},
+	expectError: `proto:.*unknown field "other"`,
+}, {
+	testName:    "unknown-message",
+	message:     "test.Other",
+	val:         map[string]interface{}{},
+	expectError: `cannot find message "test.Other" in .*`,
+}}
+
+func TestProtobufEncode(t *testing.T) {
+	c := qt.New(t)
+	descFile := writeTestDescriptorSet(c)
+	for _, test := range protobufTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(protoDesc, descFile)
+			c.Patch(protoMessage, test.message)
+			var buf bytes.Buffer
+			err := newProtobufEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestProtobufEncodeOneValue(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(protoDesc, writeTestDescriptorSet(c))
+	c.Patch(protoMessage, "test.Msg")
+	enc := newProtobufEncoder(ioutil.Discard)
+	c.Assert(enc.Encode(map[string]interface{}{}), qt.Equals, nil)
+	c.Assert(enc.Encode(map[string]interface{}{}), qt.ErrorMatches, `protobuf output can hold only one value`)
+}
+
+func TestProtobufEncodeNoDescriptor(t *testing.T) {
+	c := qt.New(t)
+	c.Patch(protoDesc, "")
+	err := newProtobufEncoder(ioutil.Discard).Encode(nil)
+	c.Assert(err, qt.ErrorMatches, `protobuf output requires -proto-desc and -proto-message`)
+}
+
+// writeTestDescriptorSet writes a descriptor set defining
+// the message test.Msg and returns the name of the file.
+func writeTestDescriptorSet(c *qt.C) string {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("test.proto"),
+			Package: proto.String("test"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Msg"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+					field("count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional),
+					field("tags", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+					field("big", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+				},
+			}},
+		}},
+	}
+	data, err := proto.Marshal(set)
+	c.Assert(err, qt.Equals, nil)
+	file := filepath.Join(c.Mkdir(), "test.desc")
+	err = ioutil.WriteFile(file, data, 0666)
+	c.Assert(err, qt.Equals, nil)
+	return file
+}
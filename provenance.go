@@ -0,0 +1,37 @@
+package main
+
+import "flag"
+
+var provenance = flag.Bool("provenance", false, `print each value as an object holding the value and a "provenance" object that maps the JSON Pointer of each leaf within it to the argument that produced it`)
+
+// withProvenance returns the values with each one wrapped
+// alongside its provenance as recorded by the parser.
+func withProvenance(vals []interface{}, origins []map[string]int) []interface{} {
+	wrapped := make([]interface{}, len(vals))
+	for i, v := range vals {
+		prov := make(map[string]interface{})
+		for path, index := range origins[i] {
+			prov[path] = map[string]interface{}{
+				"arg": float64(index),
+			}
+		}
+		wrapped[i] = map[string]interface{}{
+			"value":      v,
+			"provenance": prov,
+		}
+	}
+	return wrapped
+}
+
+// synthesize returns vals with v appended, for a value that was
+// not produced by any argument, such as the merged base document
+// printed when there are no arguments. It records no comments
+// and, when origins are being tracked, an empty provenance for v,
+// so that they stay in step with the values.
+func (p *parser) synthesize(vals []interface{}, v interface{}) []interface{} {
+	p.comments = append(p.comments, nil)
+	if p.trackOrigins {
+		p.origins = append(p.origins, make(map[string]int))
+	}
+	return append(vals, v)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var provenanceTests = []struct {
+	testName string
+	args     string
+	expect   []map[string]int
+}{{
+	testName: "values",
+	args:     "1 a true",
+	expect: []map[string]int{
+		{"": 0},
+		{"": 1},
+		{"": 2},
+	},
+}, {
+	testName: "top-object",
+	args:     "a: 1 b: [ c: x ] d: .[ y z ]",
+	expect: []map[string]int{{
+		"/a":   1,
+		"/b/c": 5,
+		"/d/0": 9,
+		"/d/1": 10,
+	}},
+}, {
+	testName: "keywords",
+	args:     "a: num 1 b: jsonstr [ x: 1 ] c: json {\"y\":2}",
+	expect: []map[string]int{{
+		"/a": 1,
+		"/b": 4,
+		"/c": 10,
+	}},
+}, {
+	testName: "empty-composites",
+	args:     "[ ] .[ [ ] ]",
+	expect: []map[string]int{
+		{"": 0},
+		{"/0": 3},
+	},
+}, {
+	testName: "escaped-keys",
+	args:     "a/b: 1 c~d: 2",
+	expect: []map[string]int{{
+		"/a~1b": 1,
+		"/c~0d": 3,
+	}},
+}, {
+	testName: "empty-top-object",
+	args:     "",
+	expect:   nil,
+}}
+
+func TestProvenance(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range provenanceTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			p.trackOrigins = true
+			_, err := p.run()
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(p.origins, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestWithProvenance(t *testing.T) {
+	c := qt.New(t)
+	got := withProvenance([]interface{}{"x"}, []map[string]int{{"": 3}})
+	c.Assert(got, qt.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"value": "x",
+			"provenance": map[string]interface{}{
+				"": map[string]interface{}{"arg": 3.0},
+			},
+		},
+	})
+}
+
+func TestSynthesizedProvenance(t *testing.T) {
+	c := qt.New(t)
+	p := newParser(nil)
+	p.trackOrigins = true
+	vals, err := p.run()
+	c.Assert(err, qt.Equals, nil)
+	vals = p.synthesize(vals, map[string]interface{}{"a": "b"})
+	c.Assert(p.comments, qt.HasLen, 1)
+	c.Assert(withProvenance(vals, p.origins), qt.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"value":      map[string]interface{}{"a": "b"},
+			"provenance": map[string]interface{}{},
+		},
+	})
+}
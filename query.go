@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// queryEncoder encodes flat objects as URL query strings
+// (application/x-www-form-urlencoded). Array members are encoded as
+// repeated keys, one for each element.
+type queryEncoder struct {
+	w io.Writer
+}
+
+func newQueryEncoder(w io.Writer) encoder {
+	return &queryEncoder{w: w}
+}
+
+func (e *queryEncoder) Encode(v interface{}) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("query output requires an object")
+	}
+	vals := make(url.Values)
+	for k, v := range obj {
+		elems, ok := v.([]interface{})
+		if !ok {
+			elems = []interface{}{v}
+		}
+		for _, elem := range elems {
+			if isComposite(elem) {
+				return fmt.Errorf("cannot encode non-flat value of %q as a query string", k)
+			}
+			text, err := scalarText(elem)
+			if err != nil {
+				return err
+			}
+			vals.Add(k, text)
+		}
+	}
+	_, err := io.WriteString(e.w, vals.Encode()+"\n")
+	return err
+}
+
+// parseQuery parses a URL query string, with an optional
+// leading "?", into an object. Keys that occur more than
+// once hold an array of all their values.
+func parseQuery(s string) (interface{}, error) {
+	vals, err := url.ParseQuery(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return nil, err
+	}
+	obj := make(map[string]interface{})
+	for k, v := range vals {
+		if len(v) == 1 {
+			obj[k] = v[0]
+			continue
+		}
+		elems := make([]interface{}, len(v))
+		for i, s := range v {
+			elems[i] = s
+		}
+		obj[k] = elems
+	}
+	return obj, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var queryTests = []struct {
+	testName    string
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "flat",
+	val: map[string]interface{}{
+		"b": "two words",
+		"a": 1.0,
+		"c": nil,
+		"d": "x&y=z",
+	},
+	expect: "a=1&b=two+words&c=&d=x%26y%3Dz\n",
+}, {
+	testName: "repeated-keys",
+	val: map[string]interface{}{
+		"tag":  []interface{}{"x", "y", true},
+		"none": []interface{}{},
+	},
+	expect: "tag=x&tag=y&tag=true\n",
+}, {
+	testName:    "not-an-object",
+	val:         "a=b",
+	expectError: `query output requires an object`,
+}, {
+	testName: "nested",
+	val: map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{}},
+	},
+	expectError: `cannot encode non-flat value of "a" as a query string`,
+}}
+
+func TestQueryEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range queryTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newQueryEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
+
+var parseQueryTests = []struct {
+	testName    string
+	query       string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "simple",
+	query:    "a=1&b=two&b=three",
+	expect: map[string]interface{}{
+		"a": "1",
+		"b": []interface{}{"two", "three"},
+	},
+}, {
+	testName: "escaped-with-question-mark",
+	query:    "?q=a+b%26c&empty",
+	expect: map[string]interface{}{
+		"q":     "a b&c",
+		"empty": "",
+	},
+}, {
+	testName: "empty",
+	query:    "",
+	expect:   map[string]interface{}{},
+}, {
+	testName:    "bad-escape",
+	query:       "a=%zz",
+	expectError: `invalid URL escape "%zz"`,
+}}
+
+func TestParseQuery(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseQueryTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseQuery(test.query)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
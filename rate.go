@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rate = flag.String("rate", "", "limit output to the given rate of values, for example 10/s, 30/m or 100/h")
+
+// parseRate parses a rate as accepted by the -rate flag and
+// returns the interval between successive values.
+func parseRate(s string) (time.Duration, error) {
+	unit := time.Second
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		switch s[i+1:] {
+		case "s":
+		case "m":
+			unit = time.Minute
+		case "h":
+			unit = time.Hour
+		default:
+			return 0, fmt.Errorf("invalid rate %q: unknown unit %q", s, s[i+1:])
+		}
+		s = s[:i]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(n > 0) {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	// A zero interval would disable rate limiting altogether,
+	// and one too long to represent would overflow.
+	d := float64(unit) / n
+	if d < 1 || d >= math.MaxInt64 {
+		return 0, fmt.Errorf("rate %q is out of range", s)
+	}
+	return time.Duration(d), nil
+}
+
+// rateEncoder is an encoder that paces the values passed to
+// another encoder so that they are emitted at a fixed rate.
+// Output is flushed after each value so that readers
+// see values as they are produced.
+type rateEncoder struct {
+	encoder
+	ctx      context.Context
+	flush    func() error
+	interval time.Duration
+	next     time.Time
+	sleep    func(context.Context, time.Duration) error
+}
+
+func newRateEncoder(ctx context.Context, enc encoder, interval time.Duration, flush func() error) encoder {
+	return &rateEncoder{
+		encoder:  enc,
+		ctx:      ctx,
+		flush:    flush,
+		interval: interval,
+		sleep:    sleepContext,
+	}
+}
+
+func (e *rateEncoder) Encode(v interface{}) error {
+	now := time.Now()
+	if e.next.After(now) {
+		if err := e.sleep(e.ctx, e.next.Sub(now)); err != nil {
+			return err
+		}
+	} else {
+		e.next = now
+	}
+	e.next = e.next.Add(e.interval)
+	if err := e.encoder.Encode(v); err != nil {
+		return err
+	}
+	return e.flush()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var parseRateTests = []struct {
+	rate        string
+	expect      time.Duration
+	expectError string
+}{{
+	rate:   "10/s",
+	expect: 100 * time.Millisecond,
+}, {
+	rate:   "4",
+	expect: 250 * time.Millisecond,
+}, {
+	rate:   "30/m",
+	expect: 2 * time.Second,
+}, {
+	rate:   "0.5/h",
+	expect: 2 * time.Hour,
+}, {
+	rate:        "10/d",
+	expectError: `invalid rate "10/d": unknown unit "d"`,
+}, {
+	rate:        "0/s",
+	expectError: `invalid rate "0"`,
+}, {
+	rate:        "NaN/s",
+	expectError: `invalid rate "NaN"`,
+}, {
+	rate:        "2e9/s",
+	expectError: `rate "2e9" is out of range`,
+}, {
+	rate:        "inf",
+	expectError: `rate "inf" is out of range`,
+}, {
+	rate:        "1e-9/h",
+	expectError: `rate "1e-9" is out of range`,
+}, {
+	rate:   "1e9/s",
+	expect: time.Nanosecond,
+}}
+
+func TestParseRate(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseRateTests {
+		c.Run(test.rate, func(c *qt.C) {
+			d, err := parseRate(test.rate)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(d, qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestRateEncoder(t *testing.T) {
+	c := qt.New(t)
+	var enc valuesEncoder
+	flushed := 0
+	e := newRateEncoder(context.Background(), &enc, time.Hour, func() error {
+		flushed++
+		return nil
+	}).(*rateEncoder)
+	var slept []time.Duration
+	e.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		err := e.Encode(float64(i))
+		c.Assert(err, qt.Equals, nil)
+	}
+	c.Assert(enc.vals, qt.DeepEquals, []interface{}{0.0, 1.0, 2.0})
+	c.Assert(flushed, qt.Equals, 3)
+	// The first value is not delayed; the others each wait
+	// for (almost) the whole interval.
+	c.Assert(slept, qt.HasLen, 2)
+	for _, d := range slept {
+		c.Assert(d > 59*time.Minute, qt.Equals, true)
+	}
+}
+
+func TestRateEncoderCanceled(t *testing.T) {
+	c := qt.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	var enc valuesEncoder
+	e := newRateEncoder(ctx, &enc, time.Hour, func() error {
+		return nil
+	})
+	c.Assert(e.Encode(1.0), qt.Equals, nil)
+	cancel()
+	c.Assert(e.Encode(2.0), qt.Equals, context.Canceled)
+	c.Assert(enc.vals, qt.DeepEquals, []interface{}{1.0})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var refResolve = flag.Bool("ref-resolve", false, "replace each object holding a $ref member with the value it refers to, which can be in the same value or in a JSON or YAML file")
+
+// refResolver resolves $ref members as described in the
+// JSON Reference specification.
+type refResolver struct {
+	hermetic bool
+	// docs holds the documents read from files, by file name.
+	docs map[string]interface{}
+	// active holds the references currently being resolved,
+	// so that cycles can be detected.
+	active map[string]bool
+}
+
+// resolveRefs returns v with every object that has a string $ref
+// member replaced by the value it refers to. A reference without a
+// file name refers to v itself; file names in references are
+// relative to the current directory or, within a file, to the
+// directory holding that file. If hermetic is true, references
+// to files are not allowed. The value v is not modified.
+func resolveRefs(v interface{}, hermetic bool) (interface{}, error) {
+	r := &refResolver{
+		hermetic: hermetic,
+		docs:     make(map[string]interface{}),
+		active:   make(map[string]bool),
+	}
+	return r.resolve(v, v, "")
+}
+
+// resolve resolves the references in v, which is
+// within the given root value read from the given file.
+func (r *refResolver) resolve(v, root interface{}, file string) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			return r.follow(ref, root, file)
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			elem, err := r.resolve(elem, root, file)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = elem
+		}
+		return m, nil
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, elem := range v {
+			elem, err := r.resolve(elem, root, file)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = elem
+		}
+		return a, nil
+	}
+	return v, nil
+}
+
+// follow returns the resolved value referred to by ref.
+func (r *refResolver) follow(ref string, root interface{}, file string) (interface{}, error) {
+	u, root, file, err := r.load(ref, root, file)
+	if err != nil {
+		return nil, err
+	}
+	key := file + "#" + u.Fragment
+	if r.active[key] {
+		return nil, fmt.Errorf("cannot resolve $ref %q: circular reference", ref)
+	}
+	target, err := lookupPointer(root, u.Fragment)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve $ref %q: %v", ref, err)
+	}
+	r.active[key] = true
+	defer delete(r.active, key)
+	return r.resolve(target, root, file)
+}
+
+// load parses ref, which is found within the given root value read
+// from the given file, and returns it along with the root value and
+// file name of the document it refers to.
+func (r *refResolver) load(ref string, root interface{}, file string) (*url.URL, interface{}, string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid $ref %q: %v", ref, err)
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return nil, nil, "", fmt.Errorf("cannot resolve $ref %q: only references to files are supported", ref)
+	}
+	if u.Path == "" {
+		return u, root, file, nil
+	}
+	if r.hermetic {
+		return nil, nil, "", fmt.Errorf("$ref %q is not allowed because it performs I/O", ref)
+	}
+	name := filepath.FromSlash(u.Path)
+	if file != "" && !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(file), name)
+	}
+	file = filepath.Clean(name)
+	doc, ok := r.docs[file]
+	if !ok {
+		doc, err = loadBase(file)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("cannot resolve $ref %q: %v", ref, err)
+		}
+		r.docs[file] = doc
+	}
+	return u, doc, file, nil
+}
+
+// lookupPointer returns the value within v at the given JSON Pointer.
+func lookupPointer(v interface{}, ptr string) (interface{}, error) {
+	if ptr == "" {
+		return v, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("JSON Pointer %q does not start with /", ptr)
+	}
+	for _, tok := range strings.Split(ptr[1:], "/") {
+		tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+		switch x := v.(type) {
+		case map[string]interface{}:
+			elem, ok := x[tok]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", tok)
+			}
+			v = elem
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(x) || strconv.Itoa(i) != tok {
+				return nil, fmt.Errorf("no array element %q", tok)
+			}
+			v = x[i]
+		default:
+			return nil, fmt.Errorf("cannot look up %q in %s", tok, valueKind(v))
+		}
+	}
+	return v, nil
+}
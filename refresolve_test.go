@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var resolveRefsTests = []struct {
+	testName    string
+	value       string
+	hermetic    bool
+	expect      string
+	expectError string
+}{{
+	testName: "local",
+	value:    `{"defs": {"a": {"b": [1, 2]}}, "x": {"$ref": "#/defs/a/b/1"}}`,
+	expect:   `{"defs": {"a": {"b": [1, 2]}}, "x": 2}`,
+}, {
+	testName:    "whole-value",
+	value:       `{"a": 1, "b": [{"$ref": "#"}]}`,
+	expectError: `cannot resolve \$ref "#": circular reference`,
+}, {
+	testName: "escaped",
+	value:    `{"defs": {"a/b": {"~c": 1}}, "x": {"$ref": "#/defs/a~1b/~0c"}}`,
+	expect:   `{"defs": {"a/b": {"~c": 1}}, "x": 1}`,
+}, {
+	testName: "percent-encoded",
+	value:    `{"defs": {"a b": 1}, "x": {"$ref": "#/defs/a%20b"}}`,
+	expect:   `{"defs": {"a b": 1}, "x": 1}`,
+}, {
+	testName: "chained",
+	value:    `{"a": {"$ref": "#/b"}, "b": {"$ref": "#/c"}, "c": 3}`,
+	expect:   `{"a": 3, "b": 3, "c": 3}`,
+}, {
+	testName:    "circular",
+	value:       `{"a": {"$ref": "#/b"}, "b": {"x": {"$ref": "#/a"}}}`,
+	expectError: `cannot resolve \$ref "#/(a|b)": circular reference`,
+}, {
+	testName:    "missing",
+	value:       `{"a": {"$ref": "#/b/c"}, "b": {}}`,
+	expectError: `cannot resolve \$ref "#/b/c": no member "c"`,
+}, {
+	testName:    "bad-index",
+	value:       `{"a": {"$ref": "#/b/01"}, "b": [1, 2]}`,
+	expectError: `cannot resolve \$ref "#/b/01": no array element "01"`,
+}, {
+	testName: "non-string-ref",
+	value:    `{"$ref": 1}`,
+	expect:   `{"$ref": 1}`,
+}, {
+	testName: "file",
+	value:    `{"a": {"$ref": "defs/common.json#/port"}, "b": {"$ref": "defs/common.json"}}`,
+	expect:   `{"a": {"type": "integer"}, "b": {"port": {"type": "integer"}, "name": {"type": "string", "format": "text"}}}`,
+}, {
+	testName: "yaml-file",
+	value:    `{"$ref": "defs/limits.yaml#/name"}`,
+	expect:   `{"type": "string", "format": "text"}`,
+}, {
+	testName:    "missing-file",
+	value:       `{"$ref": "nothere.json"}`,
+	expectError: `cannot resolve \$ref "nothere.json": open nothere.json: .*`,
+}, {
+	testName:    "hermetic",
+	value:       `{"$ref": "defs/common.json"}`,
+	hermetic:    true,
+	expectError: `\$ref "defs/common.json" is not allowed because it performs I/O`,
+}, {
+	testName: "hermetic-local",
+	value:    `{"a": 1, "b": {"$ref": "#/a"}}`,
+	hermetic: true,
+	expect:   `{"a": 1, "b": 1}`,
+}, {
+	testName:    "url",
+	value:       `{"$ref": "http://example.com/schema.json"}`,
+	expectError: `cannot resolve \$ref "http://example.com/schema.json": only references to files are supported`,
+}}
+
+func TestResolveRefs(t *testing.T) {
+	c := qt.New(t)
+	dir := c.Mkdir()
+	err := os.Mkdir(filepath.Join(dir, "defs"), 0777)
+	c.Assert(err, qt.Equals, nil)
+	files := map[string]string{
+		// The reference within common.json is relative to its directory.
+		"defs/common.json": `{"port": {"type": "integer"}, "name": {"$ref": "limits.yaml#/name"}}`,
+		"defs/limits.yaml": "name:\n  type: string\n  format: text\n",
+	}
+	for name, data := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666)
+		c.Assert(err, qt.Equals, nil)
+	}
+	wd, err := os.Getwd()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(os.Chdir(dir), qt.Equals, nil)
+	defer os.Chdir(wd)
+	for _, test := range resolveRefsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v := unmarshalJSON(c, test.value)
+			orig := unmarshalJSON(c, test.value)
+			got, err := resolveRefs(v, test.hermetic)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(got, qt.DeepEquals, unmarshalJSON(c, test.expect))
+			c.Assert(v, qt.DeepEquals, orig)
+		})
+	}
+}
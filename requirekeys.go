@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var requireKeys = flag.String("require-keys", "", "comma-separated list of paths, such as a,b.c,d[0].e, that must exist in each value")
+
+// A keyPath holds a path to a value within a JSON value.
+// Each element is either a string (an object key)
+// or an int (an array index).
+type keyPath struct {
+	text  string
+	elems []interface{}
+}
+
+// parseKeyPaths parses a comma-separated list of paths
+// in the syntax used by -require-keys.
+func parseKeyPaths(s string) ([]keyPath, error) {
+	var paths []keyPath
+	for _, p := range strings.Split(s, ",") {
+		path, err := parseKeyPath(p)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// parseKeyPath parses a path of dot-separated object keys,
+// each optionally followed by array indexes in square brackets.
+func parseKeyPath(s string) (keyPath, error) {
+	path := keyPath{text: s}
+	for _, part := range strings.Split(s, ".") {
+		key := part
+		if i := strings.Index(part, "["); i >= 0 {
+			key, part = part[:i], part[i:]
+		} else {
+			part = ""
+		}
+		if key == "" && (part == "" || len(path.elems) > 0) {
+			return keyPath{}, fmt.Errorf("invalid path %q: empty key", s)
+		}
+		if key != "" {
+			path.elems = append(path.elems, key)
+		}
+		for part != "" {
+			end := strings.Index(part, "]")
+			if part[0] != '[' || end < 0 {
+				return keyPath{}, fmt.Errorf("invalid path %q: bad index syntax", s)
+			}
+			index, err := strconv.Atoi(part[1:end])
+			if err != nil || index < 0 {
+				return keyPath{}, fmt.Errorf("invalid path %q: bad index %q", s, part[1:end])
+			}
+			path.elems = append(path.elems, index)
+			part = part[end+1:]
+		}
+	}
+	return path, nil
+}
+
+// exists reports whether the path exists within v.
+func (p keyPath) exists(v interface{}) bool {
+	for _, elem := range p.elems {
+		switch elem := elem.(type) {
+		case string:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			if v, ok = obj[elem]; !ok {
+				return false
+			}
+		case int:
+			arr, ok := v.([]interface{})
+			if !ok || elem >= len(arr) {
+				return false
+			}
+			v = arr[elem]
+		}
+	}
+	return true
+}
+
+// checkRequiredKeys returns an error naming any of the
+// paths that do not exist in each of the values.
+func checkRequiredKeys(vals []interface{}, paths []keyPath) error {
+	for i, v := range vals {
+		var missing []string
+		for _, p := range paths {
+			if !p.exists(v) {
+				missing = append(missing, p.text)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		what := "value"
+		if len(vals) > 1 {
+			what = fmt.Sprintf("value %d", i)
+		}
+		return fmt.Errorf("%s is missing required keys: %s", what, strings.Join(missing, ", "))
+	}
+	return nil
+}
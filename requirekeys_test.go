@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var parseKeyPathTests = []struct {
+	path        string
+	expect      []interface{}
+	expectError string
+}{{
+	path:   "a",
+	expect: []interface{}{"a"},
+}, {
+	path:   "b.c",
+	expect: []interface{}{"b", "c"},
+}, {
+	path:   "d[0].e",
+	expect: []interface{}{"d", 0, "e"},
+}, {
+	path:   "[1][2]",
+	expect: []interface{}{1, 2},
+}, {
+	path:        "a..b",
+	expectError: `invalid path "a..b": empty key`,
+}, {
+	path:        "a.[0]",
+	expectError: `invalid path "a.\[0\]": empty key`,
+}, {
+	path:        "a[x]",
+	expectError: `invalid path "a\[x\]": bad index "x"`,
+}, {
+	path:        "a[0",
+	expectError: `invalid path "a\[0": bad index syntax`,
+}, {
+	path:        "a[0]b",
+	expectError: `invalid path "a\[0\]b": bad index syntax`,
+}}
+
+func TestParseKeyPath(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseKeyPathTests {
+		c.Run(test.path, func(c *qt.C) {
+			p, err := parseKeyPath(test.path)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(p.elems, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestCheckRequiredKeys(t *testing.T) {
+	c := qt.New(t)
+	paths, err := parseKeyPaths("a,b.c,d[0].e")
+	c.Assert(err, qt.Equals, nil)
+	complete := map[string]interface{}{
+		"a": nil,
+		"b": map[string]interface{}{"c": false},
+		"d": []interface{}{map[string]interface{}{"e": 1.0}},
+	}
+	err = checkRequiredKeys([]interface{}{complete}, paths)
+	c.Assert(err, qt.Equals, nil)
+
+	err = checkRequiredKeys([]interface{}{map[string]interface{}{"a": 1.0, "d": []interface{}{}}}, paths)
+	c.Assert(err, qt.ErrorMatches, `value is missing required keys: b.c, d\[0\].e`)
+
+	err = checkRequiredKeys([]interface{}{complete, "x"}, paths)
+	c.Assert(err, qt.ErrorMatches, `value 1 is missing required keys: a, b.c, d\[0\].e`)
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// schemaValidator checks values against a JSON Schema. It supports
+// the commonly used validation keywords, references within the
+// document holding the schema, and the OpenAPI nullable keyword.
+type schemaValidator struct {
+	// root holds the document holding the schema, which
+	// is used to resolve references.
+	root interface{}
+	// errs holds a description of each mismatch found.
+	errs []string
+	// active holds the references being expanded by skeleton.
+	active map[string]bool
+	// requiredOnly holds whether skeleton omits optional
+	// object members.
+	requiredOnly bool
+	// comments holds the comments on object members
+	// found by skeleton.
+	comments map[string]string
+}
+
+// maxRefDepth holds the maximum number of references that
+// can be followed without any progress through the value.
+const maxRefDepth = 100
+
+// validateSchema returns a description of each way in which v does
+// not match the schema, which is found in the given root document.
+func validateSchema(schema, root, v interface{}) []string {
+	sv := &schemaValidator{root: root}
+	sv.validate(schema, v, "", 0)
+	return sv.errs
+}
+
+func (sv *schemaValidator) errorf(path string, format string, arg ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+	sv.errs = append(sv.errs, path+": "+fmt.Sprintf(format, arg...))
+}
+
+// matches reports whether v, at the given path,
+// matches the schema, without recording any errors.
+func (sv *schemaValidator) matches(schema, v interface{}, path string, depth int) bool {
+	sub := &schemaValidator{root: sv.root}
+	sub.validate(schema, v, path, depth)
+	return len(sub.errs) == 0
+}
+
+// resolveSchema follows any $ref in schema.
+func (sv *schemaValidator) resolveSchema(schema interface{}, depth int) (map[string]interface{}, int, error) {
+	for {
+		m, ok := schema.(map[string]interface{})
+		if !ok {
+			return nil, depth, nil
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m, depth, nil
+		}
+		if depth++; depth > maxRefDepth {
+			return nil, depth, fmt.Errorf("too many levels of $ref at %q", ref)
+		}
+		if !strings.HasPrefix(ref, "#") {
+			return nil, depth, fmt.Errorf("unsupported $ref %q", ref)
+		}
+		target, err := lookupPointer(sv.root, strings.TrimPrefix(ref, "#"))
+		if err != nil {
+			return nil, depth, fmt.Errorf("cannot resolve $ref %q: %v", ref, err)
+		}
+		schema = target
+	}
+}
+
+func (sv *schemaValidator) validate(schema, v interface{}, path string, depth int) {
+	if b, ok := schema.(bool); ok {
+		if !b {
+			sv.errorf(path, "no value is allowed")
+		}
+		return
+	}
+	s, depth, err := sv.resolveSchema(schema, depth)
+	if err != nil {
+		sv.errorf(path, "%v", err)
+		return
+	}
+	if s == nil {
+		return
+	}
+	if v == nil && s["nullable"] == true {
+		return
+	}
+	if !sv.checkType(s, v, path) {
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sv.errorf(path, "value is not one of the allowed values %s", marshalText(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, v) {
+		sv.errorf(path, "value must be %s", marshalText(c))
+	}
+	switch v := v.(type) {
+	case float64, json.Number:
+		sv.checkNumber(s, floatValue(v), path)
+	case string:
+		sv.checkString(s, v, path)
+	case []interface{}:
+		sv.checkArray(s, v, path)
+	case map[string]interface{}:
+		sv.checkObject(s, v, path)
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			sv.validate(sub, v, path, depth)
+		}
+	}
+	if alts, ok := s["anyOf"].([]interface{}); ok {
+		n := 0
+		for _, sub := range alts {
+			if sv.matches(sub, v, path, depth) {
+				n++
+			}
+		}
+		if n == 0 {
+			sv.errorf(path, "value does not match any schema in anyOf")
+		}
+	}
+	if alts, ok := s["oneOf"].([]interface{}); ok {
+		n := 0
+		for _, sub := range alts {
+			if sv.matches(sub, v, path, depth) {
+				n++
+			}
+		}
+		if n != 1 {
+			sv.errorf(path, "value matches %d schemas in oneOf, not 1", n)
+		}
+	}
+	if not, ok := s["not"]; ok && sv.matches(not, v, path, depth) {
+		sv.errorf(path, "value matches schema in not")
+	}
+}
+
+// checkType checks the type keyword and reports whether
+// the other keywords should be checked.
+func (sv *schemaValidator) checkType(s map[string]interface{}, v interface{}, path string) bool {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, elem := range t {
+			if elem, ok := elem.(string); ok {
+				types = append(types, elem)
+			}
+		}
+	default:
+		return true
+	}
+	for _, t := range types {
+		if schemaType(v, t) {
+			return true
+		}
+	}
+	sv.errorf(path, "got %s, want %s", valueKind(v), strings.Join(types, " or "))
+	return false
+}
+
+// schemaType reports whether v has the given JSON Schema type.
+func schemaType(v interface{}, t string) bool {
+	if t == "integer" {
+		f := floatValue(v)
+		return valueKind(v) == "number" && f == math.Trunc(f) && !math.IsInf(f, 0)
+	}
+	kind := valueKind(v)
+	if kind == "bool" {
+		kind = "boolean"
+	}
+	return kind == t
+}
+
+func (sv *schemaValidator) checkNumber(s map[string]interface{}, f float64, path string) {
+	if min, ok := schemaNumber(s, "minimum"); ok {
+		if s["exclusiveMinimum"] == true && f <= min {
+			sv.errorf(path, "%v is not greater than %v", f, min)
+		} else if f < min {
+			sv.errorf(path, "%v is less than the minimum of %v", f, min)
+		}
+	}
+	if min, ok := schemaNumber(s, "exclusiveMinimum"); ok && f <= min {
+		sv.errorf(path, "%v is not greater than %v", f, min)
+	}
+	if max, ok := schemaNumber(s, "maximum"); ok {
+		if s["exclusiveMaximum"] == true && f >= max {
+			sv.errorf(path, "%v is not less than %v", f, max)
+		} else if f > max {
+			sv.errorf(path, "%v is more than the maximum of %v", f, max)
+		}
+	}
+	if max, ok := schemaNumber(s, "exclusiveMaximum"); ok && f >= max {
+		sv.errorf(path, "%v is not less than %v", f, max)
+	}
+	if m, ok := schemaNumber(s, "multipleOf"); ok && m > 0 {
+		if q := f / m; q != math.Trunc(q) {
+			sv.errorf(path, "%v is not a multiple of %v", f, m)
+		}
+	}
+}
+
+func (sv *schemaValidator) checkString(s map[string]interface{}, str string, path string) {
+	n := utf8.RuneCountInString(str)
+	if min, ok := schemaNumber(s, "minLength"); ok && float64(n) < min {
+		sv.errorf(path, "string has %d characters, fewer than the minimum of %v", n, min)
+	}
+	if max, ok := schemaNumber(s, "maxLength"); ok && float64(n) > max {
+		sv.errorf(path, "string has %d characters, more than the maximum of %v", n, max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			sv.errorf(path, "invalid pattern %q in schema: %v", pattern, err)
+		} else if !re.MatchString(str) {
+			sv.errorf(path, "string %q does not match pattern %q", str, pattern)
+		}
+	}
+}
+
+func (sv *schemaValidator) checkArray(s map[string]interface{}, a []interface{}, path string) {
+	if min, ok := schemaNumber(s, "minItems"); ok && float64(len(a)) < min {
+		sv.errorf(path, "array has %d elements, fewer than the minimum of %v", len(a), min)
+	}
+	if max, ok := schemaNumber(s, "maxItems"); ok && float64(len(a)) > max {
+		sv.errorf(path, "array has %d elements, more than the maximum of %v", len(a), max)
+	}
+	if s["uniqueItems"] == true {
+		for i := range a {
+			for j := 0; j < i; j++ {
+				if jsonEqual(a[i], a[j]) {
+					sv.errorf(fmt.Sprintf("%s/%d", path, i), "duplicate of element %d", j)
+				}
+			}
+		}
+	}
+	if items, ok := s["items"]; ok {
+		for i, elem := range a {
+			sv.validate(items, elem, fmt.Sprintf("%s/%d", path, i), 0)
+		}
+	}
+}
+
+func (sv *schemaValidator) checkObject(s map[string]interface{}, m map[string]interface{}, path string) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, k := range required {
+			if k, ok := k.(string); ok {
+				if _, ok := m[k]; !ok {
+					sv.errorf(path, "missing required member %q", k)
+				}
+			}
+		}
+	}
+	props, _ := s["properties"].(map[string]interface{})
+	var patterns []*regexp.Regexp
+	var patternSchemas []interface{}
+	if pp, ok := s["patternProperties"].(map[string]interface{}); ok {
+		for _, p := range sortedKeys(pp) {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				sv.errorf(path, "invalid pattern %q in schema: %v", p, err)
+				continue
+			}
+			patterns = append(patterns, re)
+			patternSchemas = append(patternSchemas, pp[p])
+		}
+	}
+	for _, k := range sortedKeys(m) {
+		elemPath := path + "/" + pointerEscaper.Replace(k)
+		matched := false
+		if prop, ok := props[k]; ok {
+			matched = true
+			sv.validate(prop, m[k], elemPath, 0)
+		}
+		for i, re := range patterns {
+			if re.MatchString(k) {
+				matched = true
+				sv.validate(patternSchemas[i], m[k], elemPath, 0)
+			}
+		}
+		if matched {
+			continue
+		}
+		if extra, ok := s["additionalProperties"]; ok {
+			if extra == false {
+				sv.errorf(elemPath, "member is not allowed")
+				continue
+			}
+			sv.validate(extra, m[k], elemPath, 0)
+		}
+	}
+}
+
+// schemaNumber returns the number held in the given schema keyword.
+func schemaNumber(s map[string]interface{}, keyword string) (float64, bool) {
+	switch n := s[keyword].(type) {
+	case float64, json.Number:
+		return floatValue(n), true
+	}
+	return 0, false
+}
+
+// jsonEqual reports whether a and b represent the same JSON value.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case float64, json.Number:
+		return valueKind(b) == "number" && floatValue(a) == floatValue(b)
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, elem := range a {
+			belem, ok := b[k]
+			if !ok || !jsonEqual(elem, belem) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// marshalText returns v as JSON text, for use in error messages.
+func marshalText(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// schemaSkeleton returns a value that matches the schema, which
+// is found in the given root document. It uses any default or
+// example in the schema; otherwise it holds the properties of
+// objects (only the required ones if requiredOnly is true), the
+// minimum number of array elements, and the smallest allowed
+// numbers. It also returns a comment for each object member that
+// has a description or is optional, keyed by JSON Pointer.
+func schemaSkeleton(schema, root interface{}, requiredOnly bool) (interface{}, map[string]string, error) {
+	sv := &schemaValidator{
+		root:         root,
+		active:       make(map[string]bool),
+		requiredOnly: requiredOnly,
+		comments:     make(map[string]string),
+	}
+	v, err := sv.skeleton(schema, "", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, sv.comments, nil
+}
+
+func (sv *schemaValidator) skeleton(schema interface{}, path string, depth int) (interface{}, error) {
+	if depth > maxRefDepth {
+		return nil, fmt.Errorf("schema is too deeply nested")
+	}
+	if ref := schemaRef(schema); ref != "" {
+		sv.active[ref] = true
+		defer delete(sv.active, ref)
+	}
+	s, _, err := sv.resolveSchema(schema, 0)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	for _, k := range []string{"const", "default", "example"} {
+		if v, ok := s[k]; ok {
+			return v, nil
+		}
+	}
+	if examples, ok := s["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0], nil
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0], nil
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		var v interface{}
+		for _, sub := range all {
+			subv, err := sv.skeleton(sub, path, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			v = mergePatch(v, subv)
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+	for _, k := range []string{"oneOf", "anyOf"} {
+		if alts, ok := s[k].([]interface{}); ok && len(alts) > 0 {
+			return sv.skeleton(alts[0], path, depth+1)
+		}
+	}
+	switch skeletonType(s) {
+	case "object":
+		m := make(map[string]interface{})
+		props, _ := s["properties"].(map[string]interface{})
+		required := make(map[string]bool)
+		if reqs, ok := s["required"].([]interface{}); ok {
+			for _, k := range reqs {
+				if k, ok := k.(string); ok {
+					required[k] = true
+				}
+			}
+		}
+		for k, prop := range props {
+			if !required[k] && (sv.requiredOnly || sv.active[schemaRef(prop)]) {
+				// Omit optional members that are not wanted
+				// or that would recurse forever.
+				continue
+			}
+			elemPath := path + "/" + pointerEscaper.Replace(k)
+			v, err := sv.skeleton(prop, elemPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+			sv.addComment(elemPath, prop, required[k])
+		}
+		return m, nil
+	case "array":
+		a := []interface{}{}
+		min, _ := schemaNumber(s, "minItems")
+		for i := 0; float64(i) < min; i++ {
+			v, err := sv.skeleton(s["items"], fmt.Sprintf("%s/%d", path, i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	case "string":
+		min, _ := schemaNumber(s, "minLength")
+		return strings.Repeat("x", int(min)), nil
+	case "integer":
+		min, ok := schemaNumber(s, "minimum")
+		if !ok {
+			return json.Number("0"), nil
+		}
+		if s["exclusiveMinimum"] == true && min == math.Ceil(min) {
+			min++
+		}
+		return floatNumber(math.Ceil(min))
+	case "number":
+		min, _ := schemaNumber(s, "minimum")
+		return floatNumber(min)
+	case "boolean":
+		return false, nil
+	}
+	return nil, nil
+}
+
+// addComment records the comment for the object member at the given
+// path, which has the given schema.
+func (sv *schemaValidator) addComment(path string, schema interface{}, required bool) {
+	var lines []string
+	if s, _, err := sv.resolveSchema(schema, 0); err == nil {
+		// A description alongside a $ref takes precedence.
+		m, _ := schema.(map[string]interface{})
+		desc, ok := m["description"].(string)
+		if !ok {
+			desc, _ = s["description"].(string)
+		}
+		if desc != "" {
+			lines = append(lines, strings.TrimSpace(desc))
+		}
+	}
+	if !required {
+		lines = append(lines, "(optional)")
+	}
+	if len(lines) > 0 {
+		sv.comments[path] = strings.Join(lines, "\n")
+	}
+}
+
+// schemaRef returns the reference held in schema,
+// or the empty string if there is none.
+func schemaRef(schema interface{}) string {
+	m, _ := schema.(map[string]interface{})
+	ref, _ := m["$ref"].(string)
+	return ref
+}
+
+// skeletonType returns the type of value that
+// schemaSkeleton should produce for the schema.
+func skeletonType(s map[string]interface{}) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, elem := range t {
+			if elem, ok := elem.(string); ok && elem != "null" {
+				return elem
+			}
+		}
+		return "null"
+	}
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	if _, ok := s["items"]; ok {
+		return "array"
+	}
+	return ""
+}
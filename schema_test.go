@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var validateSchemaTests = []struct {
+	testName string
+	schema   string
+	value    string
+	expect   []string
+}{{
+	testName: "type",
+	schema:   `{"type": "integer"}`,
+	value:    `1.5`,
+	expect:   []string{`/: got number, want integer`},
+}, {
+	testName: "type-list",
+	schema:   `{"type": ["string", "null"]}`,
+	value:    `null`,
+}, {
+	testName: "nullable",
+	schema:   `{"type": "string", "nullable": true}`,
+	value:    `null`,
+}, {
+	testName: "boolean-schema",
+	schema:   `{"properties": {"a": false, "b": true}}`,
+	value:    `{"a": 1, "b": 2}`,
+	expect:   []string{`/a: no value is allowed`},
+}, {
+	testName: "enum-and-const",
+	schema:   `{"properties": {"a": {"enum": [1, "x"]}, "b": {"const": {"c": [1]}}}}`,
+	value:    `{"a": 2, "b": {"c": [1.0]}}`,
+	expect:   []string{`/a: value is not one of the allowed values \[1,"x"\]`},
+}, {
+	testName: "number-bounds",
+	schema:   `{"items": {"minimum": 0, "exclusiveMaximum": 10, "multipleOf": 2}}`,
+	value:    `[-2, 10, 3, 4]`,
+	expect: []string{
+		`/0: -2 is less than the minimum of 0`,
+		`/1: 10 is not less than 10`,
+		`/2: 3 is not a multiple of 2`,
+	},
+}, {
+	testName: "openapi-3.0-exclusive",
+	schema:   `{"minimum": 0, "exclusiveMinimum": true}`,
+	value:    `0`,
+	expect:   []string{`/: 0 is not greater than 0`},
+}, {
+	testName: "string",
+	schema:   `{"minLength": 2, "maxLength": 3, "pattern": "^[a-z]+$"}`,
+	value:    `"héllo"`,
+	expect: []string{
+		`/: string has 5 characters, more than the maximum of 3`,
+		`/: string "héllo" does not match pattern "\^\[a-z\]\+\$"`,
+	},
+}, {
+	testName: "array",
+	schema:   `{"minItems": 4, "uniqueItems": true, "items": {"type": "number"}}`,
+	value:    `[1, 1.0, "x"]`,
+	expect: []string{
+		`/: array has 3 elements, fewer than the minimum of 4`,
+		`/1: duplicate of element 0`,
+		`/2: got string, want number`,
+	},
+}, {
+	testName: "object",
+	schema: `{
+		"required": ["a", "b"],
+		"properties": {"a": {"type": "string"}},
+		"patternProperties": {"^x-": {"type": "integer"}},
+		"additionalProperties": {"type": "boolean"}
+	}`,
+	value: `{"a": "x", "x-1": 1, "x-2": "y", "c": true, "d~/": 1}`,
+	expect: []string{
+		`/: missing required member "b"`,
+		`/d~0~1: got number, want boolean`,
+		`/x-2: got string, want integer`,
+	},
+}, {
+	testName: "any-of",
+	schema:   `{"items": {"anyOf": [{"type": "string"}, {"type": "integer"}]}}`,
+	value:    `["x", 1, true]`,
+	expect:   []string{`/2: value does not match any schema in anyOf`},
+}, {
+	testName: "one-of",
+	schema:   `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+	value:    `1`,
+	expect:   []string{`/: value matches 2 schemas in oneOf, not 1`},
+}, {
+	testName: "all-of-and-not",
+	schema:   `{"allOf": [{"minimum": 1}, {"maximum": 2}], "not": {"const": 3}}`,
+	value:    `3`,
+	expect: []string{
+		`/: 3 is more than the maximum of 2`,
+		`/: value matches schema in not`,
+	},
+}, {
+	testName: "ref",
+	schema:   `{"$defs": {"node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}, "n": {"type": "integer"}}}}, "$ref": "#/$defs/node"}`,
+	value:    `{"n": 1, "next": {"n": 2, "next": {"n": "x"}}}`,
+	expect:   []string{`/next/next/n: got string, want integer`},
+}, {
+	testName: "ref-cycle",
+	schema:   `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+	value:    `1`,
+	expect:   []string{`/: too many levels of \$ref at "#/\$defs/(a|b)"`},
+}, {
+	testName: "external-ref",
+	schema:   `{"$ref": "other.json"}`,
+	value:    `1`,
+	expect:   []string{`/: unsupported \$ref "other.json"`},
+}}
+
+func TestValidateSchema(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range validateSchemaTests {
+		c.Run(test.testName, func(c *qt.C) {
+			schema := unmarshalJSON(c, test.schema)
+			errs := validateSchema(schema, schema, unmarshalJSON(c, test.value))
+			c.Assert(errs, qt.HasLen, len(test.expect), qt.Commentf("%q", errs))
+			for i, err := range errs {
+				c.Assert(err, qt.Matches, test.expect[i])
+			}
+		})
+	}
+}
+
+var schemaSkeletonTests = []struct {
+	testName string
+	schema   string
+	expect   interface{}
+}{{
+	testName: "object",
+	schema: `{
+		"type": "object",
+		"properties": {
+			"s": {"type": "string", "minLength": 2},
+			"i": {"type": "integer", "minimum": 1.5},
+			"n": {"type": "number"},
+			"b": {"type": "boolean"},
+			"a": {"type": "array", "minItems": 1, "items": {"type": ["null", "integer"]}},
+			"e": {"enum": ["x", "y"]},
+			"d": {"type": "string", "default": "def"},
+			"x": {"example": {"k": 1}}
+		}
+	}`,
+	expect: map[string]interface{}{
+		"s": "xx",
+		"i": json.Number("2"),
+		"n": json.Number("0"),
+		"b": false,
+		"a": []interface{}{json.Number("0")},
+		"e": "x",
+		"d": "def",
+		"x": map[string]interface{}{"k": float64(1)},
+	},
+}, {
+	testName: "combinators",
+	schema: `{"properties": {
+		"all": {"allOf": [{"properties": {"a": {"type": "integer"}}}, {"properties": {"b": {"type": "boolean"}}}]},
+		"one": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+	}}`,
+	expect: map[string]interface{}{
+		"all": map[string]interface{}{"a": json.Number("0"), "b": false},
+		"one": "",
+	},
+}, {
+	testName: "recursive",
+	schema: `{
+		"$defs": {"node": {"type": "object", "required": ["name"], "properties": {
+			"name": {"type": "string"},
+			"next": {"$ref": "#/$defs/node"},
+			"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+		}}},
+		"$ref": "#/$defs/node"
+	}`,
+	expect: map[string]interface{}{
+		"name":     "",
+		"children": []interface{}{},
+	},
+}, {
+	testName: "empty",
+	schema:   `{}`,
+	expect:   nil,
+}}
+
+func TestSchemaSkeleton(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range schemaSkeletonTests {
+		c.Run(test.testName, func(c *qt.C) {
+			schema := unmarshalJSON(c, test.schema)
+			v, _, err := schemaSkeleton(schema, schema, false)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+			c.Assert(validateSchema(schema, schema, v), qt.HasLen, 0)
+		})
+	}
+}
+
+func TestSchemaSkeletonComments(t *testing.T) {
+	c := qt.New(t)
+	schema := unmarshalJSON(c, `{
+		"$defs": {"tag": {"type": "string", "description": "A tag."}},
+		"type": "object",
+		"required": ["name", "tag"],
+		"properties": {
+			"name": {"type": "string", "description": " Name of the pet. "},
+			"age": {"type": "integer"},
+			"tag": {"$ref": "#/$defs/tag"},
+			"owner": {
+				"description": "The owner.",
+				"properties": {"email": {"$ref": "#/$defs/tag", "description": "Their email."}}
+			}
+		}
+	}`)
+	v, comments, err := schemaSkeleton(schema, schema, false)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.DeepEquals, map[string]interface{}{
+		"name":  "",
+		"age":   json.Number("0"),
+		"tag":   "",
+		"owner": map[string]interface{}{"email": ""},
+	})
+	c.Assert(comments, qt.DeepEquals, map[string]string{
+		"/name":        "Name of the pet.",
+		"/age":         "(optional)",
+		"/tag":         "A tag.",
+		"/owner":       "The owner.\n(optional)",
+		"/owner/email": "Their email.\n(optional)",
+	})
+
+	v, comments, err = schemaSkeleton(schema, schema, true)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.DeepEquals, map[string]interface{}{
+		"name": "",
+		"tag":  "",
+	})
+	c.Assert(comments, qt.DeepEquals, map[string]string{
+		"/name": "Name of the pet.",
+		"/tag":  "A tag.",
+	})
+}
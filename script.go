@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+var scriptFile = flag.String("f", "", "read arguments from the named file before those on the command line; arguments are separated by white space and may be quoted, and # starts a comment")
+
+// readScript reads the arguments held in the named file.
+func readScript(file string) ([]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	args, err := splitScript(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", file, err)
+	}
+	return args, nil
+}
+
+// splitScript splits s into arguments separated by white space.
+// As in a POSIX shell, single quotes preserve the literal
+// value of the characters between them, a backslash outside single
+// quotes preserves the literal value of the following character,
+// double quotes preserve everything but backslash escapes, and
+// a # at the start of an argument starts a comment that extends
+// to the end of the line.
+func splitScript(s string) ([]string, error) {
+	sargs, err := scanScript(s)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, a := range sargs {
+		args = append(args, a.text)
+	}
+	return args, nil
+}
+
+// scriptArg holds an argument in a script and the
+// byte offsets of its start and end within the script.
+type scriptArg struct {
+	text       string
+	start, end int
+}
+
+// scriptError describes an error in a script.
+type scriptError struct {
+	line int
+	msg  string
+}
+
+func (e *scriptError) Error() string {
+	return fmt.Sprintf("%d: %s", e.line, e.msg)
+}
+
+// scanScript is like splitScript but also returns
+// the position of each argument.
+func scanScript(s string) ([]scriptArg, error) {
+	var args []scriptArg
+	var arg strings.Builder
+	inArg := false
+	start := 0
+	line := 1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !inArg {
+			start = i
+		}
+		switch {
+		case c == '\n' || c == ' ' || c == '\t' || c == '\r':
+			if inArg {
+				args = append(args, scriptArg{arg.String(), start, i})
+				arg.Reset()
+				inArg = false
+			}
+			if c == '\n' {
+				line++
+			}
+		case c == '#' && !inArg:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, &scriptError{line, "backslash at end of file"}
+			}
+			i++
+			if s[i] == '\n' {
+				// A backslash-newline pair is removed.
+				line++
+				continue
+			}
+			arg.WriteByte(s[i])
+			inArg = true
+		case c == '\'' || c == '"':
+			qline := line
+			for i++; ; i++ {
+				if i >= len(s) {
+					return nil, &scriptError{qline, fmt.Sprintf("unterminated %c quote", c)}
+				}
+				q := s[i]
+				if q == c {
+					break
+				}
+				if q == '\n' {
+					line++
+				}
+				if q == '\\' && c == '"' && i+1 < len(s) && strings.IndexByte("\\\"$`\n", s[i+1]) >= 0 {
+					i++
+					q = s[i]
+					if q == '\n' {
+						// A backslash-newline pair is removed.
+						line++
+						continue
+					}
+				}
+				arg.WriteByte(q)
+			}
+			inArg = true
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, scriptArg{arg.String(), start, len(s)})
+	}
+	return args, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var splitScriptTests = []struct {
+	testName    string
+	script      string
+	expect      []string
+	expectError string
+}{{
+	testName: "empty",
+	script:   "",
+	expect:   nil,
+}, {
+	testName: "fields",
+	script:   "a: 1\n\tb: [ c: x ]  \r\n",
+	expect:   []string{"a:", "1", "b:", "[", "c:", "x", "]"},
+}, {
+	testName: "comments",
+	script:   "# leading\na: 1 # trailing\nb: x#y\n#",
+	expect:   []string{"a:", "1", "b:", "x#y"},
+}, {
+	testName: "single-quotes",
+	script:   `a: 'x  "y" \n # z'`,
+	expect:   []string{"a:", `x  "y" \n # z`},
+}, {
+	testName: "double-quotes",
+	script:   `"a b" "x \"y\" \\ \n $"`,
+	expect:   []string{"a b", `x "y" \ \n $`},
+}, {
+	testName: "concatenation",
+	script:   `it'"'s"'"x`,
+	expect:   []string{`it"s'x`},
+}, {
+	testName: "empty-quotes",
+	script:   `a: '' b: ""`,
+	expect:   []string{"a:", "", "b:", ""},
+}, {
+	testName: "backslash",
+	script:   `front\ end \# \'`,
+	expect:   []string{"front end", "#", "'"},
+}, {
+	testName: "line-continuation",
+	script:   "a\\\nb \"c\\\nd\"",
+	expect:   []string{"ab", "cd"},
+}, {
+	testName:    "unterminated-quote",
+	script:      "a\nb 'c\nd",
+	expectError: `2: unterminated ' quote`,
+}, {
+	testName:    "trailing-backslash",
+	script:      "a\nb\\",
+	expectError: `2: backslash at end of file`,
+}}
+
+func TestSplitScript(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range splitScriptTests {
+		c.Run(test.testName, func(c *qt.C) {
+			args, err := splitScript(test.script)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(args, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestReadScript(t *testing.T) {
+	c := qt.New(t)
+	file := filepath.Join(c.Mkdir(), "payload.args")
+	err := ioutil.WriteFile(file, []byte("name: 'web server'\nport: 80 # http\n"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	args, err := readScript(file)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(args, qt.DeepEquals, []string{"name:", "web server", "port:", "80"})
+
+	err = ioutil.WriteFile(file, []byte("\n'x"), 0666)
+	c.Assert(err, qt.Equals, nil)
+	_, err = readScript(file)
+	c.Assert(err, qt.ErrorMatches, `.*payload.args:2: unterminated ' quote`)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+)
+
+var (
+	separator    = flag.String("separator", "\n", "text to print between values in text output formats")
+	finalNewline = flag.Bool("final-newline", true, "print a newline after the last value in text output formats")
+)
+
+// binaryFormats holds the output formats that -separator
+// and -final-newline cannot be used with.
+var binaryFormats = map[string]bool{
+	"avro":     true,
+	"bson":     true,
+	"cbor":     true,
+	"msgpack":  true,
+	"protobuf": true,
+	"smile":    true,
+}
+
+// separatorEncoder writes the values encoded by an encoder
+// that ends each value with a newline, but with the given
+// separator between values instead and a newline after the
+// last value only if finalNewline is true.
+type separatorEncoder struct {
+	w            io.Writer
+	buf          bytes.Buffer
+	enc          encoder
+	sep          string
+	finalNewline bool
+	n            int
+}
+
+func newSeparatorEncoder(w io.Writer, newEncoder func(io.Writer) encoder, sep string, finalNewline bool) *separatorEncoder {
+	e := &separatorEncoder{
+		w:            w,
+		sep:          sep,
+		finalNewline: finalNewline,
+	}
+	e.enc = newEncoder(&e.buf)
+	return e
+}
+
+func (e *separatorEncoder) Encode(v interface{}) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+// Close closes the underlying encoder, if necessary, and
+// writes the final newline.
+func (e *separatorEncoder) Close() error {
+	if closer, ok := e.enc.(encoderCloser); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+		if err := e.flush(); err != nil {
+			return err
+		}
+	}
+	if e.n > 0 && e.finalNewline {
+		_, err := io.WriteString(e.w, "\n")
+		return err
+	}
+	return nil
+}
+
+// flush writes any output from the underlying encoder.
+func (e *separatorEncoder) flush() error {
+	if e.buf.Len() == 0 {
+		return nil
+	}
+	data := bytes.TrimSuffix(e.buf.Bytes(), []byte("\n"))
+	if e.n > 0 {
+		if _, err := io.WriteString(e.w, e.sep); err != nil {
+			return err
+		}
+	}
+	e.n++
+	_, err := e.w.Write(data)
+	e.buf.Reset()
+	return err
+}
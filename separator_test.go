@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var separatorTests = []struct {
+	testName     string
+	format       string
+	sep          string
+	finalNewline bool
+	vals         []interface{}
+	expect       string
+}{{
+	testName:     "comma",
+	format:       "json",
+	sep:          ",",
+	finalNewline: true,
+	vals:         []interface{}{1.0, "a", map[string]interface{}{"b": true}},
+	expect:       "1,\"a\",{\"b\":true}\n",
+}, {
+	testName: "no-final-newline",
+	format:   "json",
+	sep:      "\n",
+	vals:     []interface{}{1.0, 2.0},
+	expect:   "1\n2",
+}, {
+	testName: "no-values",
+	format:   "json",
+	sep:      ",",
+	expect:   "",
+}, {
+	testName:     "record-separator",
+	format:       "jsonc",
+	sep:          "\x1e",
+	finalNewline: true,
+	vals:         []interface{}{map[string]interface{}{"a": 1.0}, 2.0},
+	expect:       "{\n\t\"a\": 1\n}\x1e2\n",
+}, {
+	testName: "csv",
+	format:   "csv",
+	sep:      ";",
+	vals:     []interface{}{[]interface{}{map[string]interface{}{"a": 1.0}}, []interface{}{map[string]interface{}{"a": 2.0}}},
+	expect:   "a\n1;a\n2",
+}, {
+	// The Go struct encoder writes its output only when closed.
+	testName: "closer",
+	format:   "go-struct",
+	sep:      ",",
+	vals:     []interface{}{map[string]interface{}{"a": 1.0}},
+	expect:   "type Value struct {\n\tA int64 `json:\"a\"`\n}",
+}}
+
+func TestSeparatorEncoder(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range separatorTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			newEncoder := formats[test.format]
+			if test.format == "go-struct" {
+				newEncoder = newGoStructEncoder
+			}
+			enc := newSeparatorEncoder(&buf, newEncoder, test.sep, test.finalNewline)
+			for _, v := range test.vals {
+				err := enc.Encode(v)
+				c.Assert(err, qt.Equals, nil)
+			}
+			c.Assert(enc.Close(), qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
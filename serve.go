@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var serveAddr = flag.String("serve", "", "serve HTTP on the given address. A POST request with a JSON array of arguments as its body returns the output for those arguments. Keywords that perform I/O or are nondeterministic are rejected")
+
+// maxServeRequestSize limits the size of the body
+// of a request to the -serve HTTP endpoint.
+const maxServeRequestSize = 10 << 20
+
+// formatContentTypes holds the content types of output
+// formats that have a well known one. Other formats are
+// served as application/octet-stream.
+var formatContentTypes = map[string]string{
+	"csv":     "text/csv; charset=utf-8",
+	"json":    "application/json",
+	"msgpack": "application/msgpack",
+	"cbor":    "application/cbor",
+	"tsv":     "text/tab-separated-values; charset=utf-8",
+	"xml":     "application/xml",
+	"query":   "application/x-www-form-urlencoded",
+}
+
+// argsHandler returns an HTTP handler that evaluates the
+// arguments in each request and responds with the output
+// encoded by newEncoder with the given content type.
+func argsHandler(newEncoder func(io.Writer) encoder, contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var args []string
+		dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxServeRequestSize))
+		if err := dec.Decode(&args); err != nil {
+			http.Error(w, fmt.Sprintf("request body must be a JSON array of strings: %v", err), http.StatusBadRequest)
+			return
+		}
+		out, err := encodeArgs(args, newEncoder, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(out)
+	})
+}
+
+func contentTypeOf(format string) string {
+	if t, ok := formatContentTypes[format]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
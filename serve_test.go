@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var serveTests = []struct {
+	testName          string
+	method            string
+	body              string
+	expectStatus      int
+	expectBody        string
+	expectContentType string
+}{{
+	testName:          "ok",
+	method:            "POST",
+	body:              `["a:", "1", "b:", ".[", "x", "]"]`,
+	expectStatus:      http.StatusOK,
+	expectBody:        "{\"a\":1,\"b\":[\"x\"]}\n",
+	expectContentType: "application/json",
+}, {
+	testName:          "parse-error",
+	method:            "POST",
+	body:              `["num", "x"]`,
+	expectStatus:      http.StatusBadRequest,
+	expectBody:        "invalid number \"x\" at argument 1\n",
+	expectContentType: "text/plain; charset=utf-8",
+}, {
+	testName:          "bad-body",
+	method:            "POST",
+	body:              `{"a": 1}`,
+	expectStatus:      http.StatusBadRequest,
+	expectBody:        "request body must be a JSON array of strings: json: cannot unmarshal object into Go value of type []string\n",
+	expectContentType: "text/plain; charset=utf-8",
+}, {
+	testName:          "bad-method",
+	method:            "GET",
+	expectStatus:      http.StatusMethodNotAllowed,
+	expectBody:        "method not allowed\n",
+	expectContentType: "text/plain; charset=utf-8",
+}}
+
+func TestArgsHandler(t *testing.T) {
+	c := qt.New(t)
+	h := argsHandler(newJSONEncoder, contentTypeOf("json"))
+	for _, test := range serveTests {
+		c.Run(test.testName, func(c *qt.C) {
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			resp := rec.Result()
+			body, err := ioutil.ReadAll(resp.Body)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(resp.StatusCode, qt.Equals, test.expectStatus)
+			c.Assert(string(body), qt.Equals, test.expectBody)
+			c.Assert(resp.Header.Get("Content-Type"), qt.Equals, test.expectContentType)
+		})
+	}
+}
+
+func TestHTTPServeRejectsImpureKeywords(t *testing.T) {
+	c := qt.New(t)
+	keywords["impure"] = &keyword{
+		eval: func([]string, int, interface{}) (interface{}, error) {
+			return "x", nil
+		},
+		impure: true,
+	}
+	c.Defer(func() {
+		delete(keywords, "impure")
+	})
+	rec := httptest.NewRecorder()
+	argsHandler(newJSONEncoder, "application/json").ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`["impure"]`)))
+	c.Assert(rec.Code, qt.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), qt.Equals, "impure at argument 0 is not allowed because it performs I/O or is nondeterministic\n")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+)
+
+var serveStdio = flag.Bool("serve-stdio", false, `serve requests on standard input until it is closed. Each request is a big-endian uint32 argument count followed by each argument as a big-endian uint32 length and its bytes. Each response is a status byte (0 for success, 1 for failure) followed by a big-endian uint32 length and the output or error message`)
+
+// maxServeArgSize limits the size of an argument
+// read by serveArgs so that a corrupt stream
+// does not cause huge allocations.
+const maxServeArgSize = 1 << 30
+
+// serveArgs reads argument lists from r and writes the
+// output for each one to w using the given encoder constructor,
+// until r returns io.EOF at the start of a request.
+func serveArgs(r io.Reader, w io.Writer, newEncoder func(io.Writer) encoder) error {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	for {
+		args, err := readArgs(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read request: %v", err)
+		}
+		status := byte(0)
+		out, err := encodeArgs(args, newEncoder, false)
+		if err != nil {
+			status, out = 1, []byte(err.Error())
+		}
+		var hdr [5]byte
+		hdr[0] = status
+		binary.BigEndian.PutUint32(hdr[1:], uint32(len(out)))
+		bw.Write(hdr[:])
+		bw.Write(out)
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// readArgs reads a single length-prefixed argument list.
+func readArgs(r io.Reader) ([]string, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for i := uint32(0); i < n; i++ {
+		size, err := readUint32(r)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		if size > maxServeArgSize {
+			return nil, fmt.Errorf("argument too large (%d bytes)", size)
+		}
+		arg := make([]byte, size)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, noEOF(err)
+		}
+		args = append(args, string(arg))
+	}
+	return args, nil
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf[:]), nil
+}
+
+// noEOF converts io.EOF to io.ErrUnexpectedEOF, for
+// use when EOF occurs in the middle of a request.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// encodeArgs returns the encoded output for the values
+// specified by args. If hermetic is true, impure keywords
+// are rejected.
+func encodeArgs(args []string, newEncoder func(io.Writer) encoder, hermetic bool) ([]byte, error) {
+	p := newParser(args)
+	p.hermetic = hermetic
+	vals, err := p.run()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := withComments(newEncoder, p.comments)(&buf)
+	for _, v := range vals {
+		if err := enc.Encode(v); err != nil {
+			return nil, fmt.Errorf("cannot encode value %#v: %v", v, err)
+		}
+	}
+	if closer, ok := enc.(encoderCloser); ok {
+		if err := closer.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
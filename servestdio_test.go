@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestServeArgs(t *testing.T) {
+	c := qt.New(t)
+	var in bytes.Buffer
+	writeRequest(&in, "a:", "1")
+	writeRequest(&in, "num", "x")
+	writeRequest(&in)
+	var out bytes.Buffer
+	err := serveArgs(&in, &out, newJSONEncoder)
+	c.Assert(err, qt.Equals, nil)
+
+	var statuses []byte
+	var data []string
+	for out.Len() > 0 {
+		status, _ := out.ReadByte()
+		var size uint32
+		err := binary.Read(&out, binary.BigEndian, &size)
+		c.Assert(err, qt.Equals, nil)
+		statuses = append(statuses, status)
+		data = append(data, string(out.Next(int(size))))
+	}
+	c.Assert(statuses, qt.DeepEquals, []byte{0, 1, 0})
+	c.Assert(data, qt.DeepEquals, []string{
+		"{\"a\":1}\n",
+		`invalid number "x" at argument 1`,
+		"",
+	})
+}
+
+func TestServeArgsTruncatedRequest(t *testing.T) {
+	c := qt.New(t)
+	var in bytes.Buffer
+	writeRequest(&in, "a:", "1")
+	in.Truncate(in.Len() - 1)
+	err := serveArgs(&in, ioutil.Discard, newJSONEncoder)
+	c.Assert(err, qt.ErrorMatches, `cannot read request: unexpected EOF`)
+}
+
+func writeRequest(w *bytes.Buffer, args ...string) {
+	binary.Write(w, binary.BigEndian, uint32(len(args)))
+	for _, a := range args {
+		binary.Write(w, binary.BigEndian, uint32(len(a)))
+		w.WriteString(a)
+	}
+}
+
+func TestServeArgsComments(t *testing.T) {
+	c := qt.New(t)
+	var in bytes.Buffer
+	writeRequest(&in, "comment", "the a", "a:", "1")
+	var out bytes.Buffer
+	err := serveArgs(&in, &out, newJSONCEncoder)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(out.String(), qt.Equals, "\x00\x00\x00\x00\x16{\n\t// the a\n\t\"a\": 1\n}\n")
+}
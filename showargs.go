@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+)
+
+var showArgs = flag.Bool("show-args", false, "print the arguments to standard error, colored according to their syntactic role when it's a terminal and $NO_COLOR is unset, before any output")
+
+// argColors holds the ANSI color escape sequences used for each class
+// of argument.
+var argColors = map[argClass]string{
+	classValue:   "\x1b[32m", // green
+	classKey:     "\x1b[34m", // blue
+	classDelim:   "\x1b[1m",  // bold
+	classKeyword: "\x1b[35m", // magenta
+}
+
+// writeArgs writes the arguments as a shell-quoted command
+// line, coloring each one according to its class if color is true.
+func writeArgs(w io.Writer, args []string, classes []argClass, color bool) error {
+	words := make([]string, len(args))
+	for i, a := range args {
+		words[i] = shellQuote(a)
+		if color {
+			words[i] = argColors[classes[i]] + words[i] + "\x1b[0m"
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(words, " ")+"\n")
+	return err
+}
+
+// useColor reports whether output written to f should be
+// colored: f must be a terminal and, by the convention
+// of https://no-color.org, $NO_COLOR must not be set.
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestArgClasses(t *testing.T) {
+	c := qt.New(t)
+	p := newParser([]string{"a:", "[", "key", "k", "jsonstr", ".[", "str", "]", "]", "]", "b:", "null"})
+	_, err := p.run()
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(p.classes, qt.DeepEquals, []argClass{
+		classKey,
+		classDelim,
+		classKeyword,
+		classKey,
+		classKeyword,
+		classDelim,
+		classKeyword,
+		classValue,
+		classDelim,
+		classDelim,
+		classKey,
+		classValue,
+	})
+}
+
+func TestWriteArgs(t *testing.T) {
+	c := qt.New(t)
+	args := []string{"a:", "[", "it's", "]"}
+	classes := []argClass{classKey, classDelim, classValue, classDelim}
+
+	var buf bytes.Buffer
+	err := writeArgs(&buf, args, classes, false)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(buf.String(), qt.Equals, `a: '[' 'it'\''s' ']'`+"\n")
+
+	buf.Reset()
+	err = writeArgs(&buf, args[:1], classes[:1], true)
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(buf.String(), qt.Equals, "\x1b[34ma:\x1b[0m\n")
+}
+
+func TestUseColor(t *testing.T) {
+	c := qt.New(t)
+	// Output redirected to a file is never colored.
+	f, err := ioutil.TempFile(c.Mkdir(), "")
+	c.Assert(err, qt.Equals, nil)
+	defer f.Close()
+	c.Assert(useColor(f), qt.Equals, false)
+
+	// Nor is output to a device when $NO_COLOR is set.
+	tty, err := os.Open(os.DevNull)
+	c.Assert(err, qt.Equals, nil)
+	defer tty.Close()
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	os.Setenv("NO_COLOR", "1")
+	c.Assert(useColor(tty), qt.Equals, false)
+}
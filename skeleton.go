@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runSkeleton implements the skeleton subcommand, which prints
+// a value that matches the JSON Schema in the file named in args.
+func runSkeleton(args []string) {
+	flags := flag.NewFlagSet("skeleton", flag.ExitOnError)
+	requiredOnly := flags.Bool("required", false, "include only required object members")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "json skeleton [flags] schema-file\n")
+		flags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nThe skeleton subcommand prints a value that matches the JSON Schema\nin the named JSON or YAML file.\n")
+		os.Exit(2)
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+	}
+	newEncoder, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "json: unknown output format %q\n", *format)
+		os.Exit(2)
+	}
+	schema, err := loadBase(flags.Arg(0))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	v, comments, err := schemaSkeleton(schema, schema, *requiredOnly)
+	if err != nil {
+		fatalf("cannot make skeleton of %s: %v", flags.Arg(0), err)
+	}
+	if err := writeOutput(interruptContext(), args, []interface{}{v}, []map[string]string{comments}, newEncoder, 0, 0); err != nil {
+		removePartialOutput()
+		fatalf("%v", err)
+	}
+}
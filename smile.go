@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+)
+
+// smileEncoder encodes values in Jackson's Smile binary format
+// (see https://github.com/FasterXML/smile-format-specification).
+// The header is written before the first value, and neither
+// shared names nor shared string values are used.
+type smileEncoder struct {
+	w           io.Writer
+	buf         []byte
+	wroteHeader bool
+}
+
+func newSmileEncoder(w io.Writer) encoder {
+	return &smileEncoder{w: w}
+}
+
+func (e *smileEncoder) Encode(v interface{}) error {
+	e.buf = e.buf[:0]
+	if !e.wroteHeader {
+		e.buf = append(e.buf, ':', ')', '\n', 0x00)
+		e.wroteHeader = true
+	}
+	if err := e.encode(v); err != nil {
+		return err
+	}
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+func (e *smileEncoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0x21)
+	case bool:
+		if v {
+			e.buf = append(e.buf, 0x23)
+		} else {
+			e.buf = append(e.buf, 0x22)
+		}
+	case float64, json.Number:
+		i, ok := intValue(v)
+		switch {
+		case ok && i >= -16 && i <= 15:
+			e.buf = append(e.buf, 0xc0|byte(zigzag(i)))
+		case ok && i >= math.MinInt32 && i <= math.MaxInt32:
+			e.buf = append(e.buf, 0x24)
+			e.appendVInt(zigzag(i))
+		case ok:
+			e.buf = append(e.buf, 0x25)
+			e.appendVInt(zigzag(i))
+		default:
+			e.buf = append(e.buf, 0x29)
+			bits := math.Float64bits(floatValue(v))
+			var b [10]byte
+			for i := len(b) - 1; i >= 0; i-- {
+				b[i] = byte(bits & 0x7f)
+				bits >>= 7
+			}
+			e.buf = append(e.buf, b[:]...)
+		}
+	case string:
+		e.encodeString(v)
+	case []interface{}:
+		e.buf = append(e.buf, 0xf8)
+		for _, elem := range v {
+			if err := e.encode(elem); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, 0xf9)
+	case map[string]interface{}:
+		e.buf = append(e.buf, 0xfa)
+		for _, k := range sortedKeys(v) {
+			e.encodeKey(k)
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, 0xfb)
+	default:
+		return fmt.Errorf("cannot encode %T as Smile", v)
+	}
+	return nil
+}
+
+func (e *smileEncoder) encodeString(s string) {
+	n := len(s)
+	ascii := isASCII(s)
+	switch {
+	case n == 0:
+		e.buf = append(e.buf, 0x20)
+		return
+	case ascii && n <= 32:
+		e.buf = append(e.buf, 0x40+byte(n-1))
+	case ascii && n <= 64:
+		e.buf = append(e.buf, 0x60+byte(n-33))
+	case !ascii && n <= 33:
+		e.buf = append(e.buf, 0x80+byte(n-2))
+	case !ascii && n <= 65:
+		e.buf = append(e.buf, 0xa0+byte(n-34))
+	case ascii:
+		e.buf = append(e.buf, 0xe0)
+		e.buf = append(e.buf, s...)
+		e.buf = append(e.buf, 0xfc)
+		return
+	default:
+		e.buf = append(e.buf, 0xe4)
+		e.buf = append(e.buf, s...)
+		e.buf = append(e.buf, 0xfc)
+		return
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *smileEncoder) encodeKey(k string) {
+	n := len(k)
+	ascii := isASCII(k)
+	switch {
+	case n == 0:
+		e.buf = append(e.buf, 0x20)
+		return
+	case ascii && n <= 64:
+		e.buf = append(e.buf, 0x80+byte(n-1))
+	case !ascii && n <= 57:
+		e.buf = append(e.buf, 0xc0+byte(n-2))
+	default:
+		e.buf = append(e.buf, 0x34)
+		e.buf = append(e.buf, k...)
+		e.buf = append(e.buf, 0xfc)
+		return
+	}
+	e.buf = append(e.buf, k...)
+}
+
+// appendVInt appends x as a Smile variable length integer:
+// big-endian groups of 7 bits, except for the last byte,
+// which holds 6 bits and has its top bit set.
+func (e *smileEncoder) appendVInt(x uint64) {
+	var b [11]byte
+	i := len(b) - 1
+	b[i] = 0x80 | byte(x&0x3f)
+	for x >>= 6; x != 0; x >>= 7 {
+		i--
+		b[i] = byte(x & 0x7f)
+	}
+	e.buf = append(e.buf, b[i:]...)
+}
+
+func zigzag(i int64) uint64 {
+	return uint64(i<<1) ^ uint64(i>>63)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
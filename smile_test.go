@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var smileTests = []struct {
+	testName string
+	val      interface{}
+	expect   string
+}{{
+	testName: "null",
+	val:      nil,
+	expect:   "21",
+}, {
+	testName: "bool",
+	val:      []interface{}{true, false},
+	expect:   "f82322f9",
+}, {
+	testName: "small-ints",
+	val:      []interface{}{0.0, -1.0, 15.0, -16.0},
+	expect:   "f8c0c1dedff9",
+}, {
+	testName: "int32",
+	val:      100.0,
+	expect:   "240388",
+}, {
+	testName: "int64",
+	val:      json.Number("4294967296"),
+	expect:   "254000000080",
+}, {
+	testName: "double",
+	val:      1.5,
+	expect:   "29003f7c00000000000000",
+}, {
+	testName: "strings",
+	val:      []interface{}{"", "a", "é"},
+	expect:   "f8204061" + "80c3a9" + "f9",
+}, {
+	testName: "long-ascii-string",
+	val:      strings.Repeat("x", 65),
+	expect:   "e0" + strings.Repeat("78", 65) + "fc",
+}, {
+	testName: "object",
+	val: map[string]interface{}{
+		"a": 1.0,
+		"":  true,
+	},
+	expect: "fa20238061c2fb",
+}}
+
+func TestSmileEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range smileTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			err := newSmileEncoder(&buf).Encode(test.val)
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, "3a290a00"+test.expect)
+		})
+	}
+}
+
+func TestSmileHeaderWrittenOnce(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	enc := newSmileEncoder(&buf)
+	c.Assert(enc.Encode(true), qt.Equals, nil)
+	c.Assert(enc.Encode(false), qt.Equals, nil)
+	c.Assert(hex.EncodeToString(buf.Bytes()), qt.Equals, "3a290a002322")
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// readStdin returns the contents of standard input, with any
+// single trailing newline removed, for the argument at the
+// given index.
+func (p *parser) readStdin(index int) (string, error) {
+	if p.hermetic {
+		return "", fmt.Errorf("stdin at argument %d is not allowed because it performs I/O", index)
+	}
+	if p.stdinData != nil {
+		return *p.stdinData, nil
+	}
+	if p.stdin == nil {
+		return "", fmt.Errorf("stdin at argument %d is not available", index)
+	}
+	data, err := ioutil.ReadAll(p.stdin)
+	if err != nil {
+		return "", fmt.Errorf("cannot read stdin at argument %d: %v", index, err)
+	}
+	s := trimNewline(string(data))
+	p.stdinData = &s
+	return s, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var stdinTests = []struct {
+	testName    string
+	args        string
+	stdin       string
+	noStdin     bool
+	hermetic    bool
+	expect      []interface{}
+	expectError string
+}{{
+	testName: "string",
+	args:     "a: stdin",
+	stdin:    "hello [ world\n",
+	expect:   []interface{}{map[string]interface{}{"a": "hello [ world"}},
+}, {
+	testName: "dash",
+	args:     "-",
+	stdin:    "42\n",
+	expect:   []interface{}{"42"},
+}, {
+	testName: "json",
+	args:     "wrapper: [ payload: json stdin ]",
+	stdin:    `{"x": [1, 2]}`,
+	expect: []interface{}{map[string]interface{}{
+		"wrapper": map[string]interface{}{
+			"payload": map[string]interface{}{
+				"x": []interface{}{json.Number("1"), json.Number("2")},
+			},
+		},
+	}},
+}, {
+	testName: "read-once",
+	args:     "stdin num stdin",
+	stdin:    "5\r\n",
+	expect:   []interface{}{"5", json.Number("5")},
+}, {
+	testName: "dash-keyword-argument-is-literal",
+	args:     "str -",
+	expect:   []interface{}{"-"},
+}, {
+	testName: "empty",
+	args:     "a: stdin",
+	expect:   []interface{}{map[string]interface{}{"a": ""}},
+}, {
+	testName:    "not-available",
+	args:        "a: -",
+	noStdin:     true,
+	expectError: `stdin at argument 1 is not available`,
+}, {
+	testName:    "hermetic",
+	args:        "json stdin",
+	hermetic:    true,
+	expectError: `stdin at argument 1 is not allowed because it performs I/O`,
+}}
+
+func TestStdin(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range stdinTests {
+		c.Run(test.testName, func(c *qt.C) {
+			p := newParser(strings.Fields(test.args))
+			if !test.noStdin {
+				p.stdin = strings.NewReader(test.stdin)
+			}
+			p.hermetic = test.hermetic
+			vals, err := p.run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, test.expect)
+		})
+	}
+}
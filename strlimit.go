@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// evalMaxLen implements the maxlen keyword. The string is
+// taken literally, so digits are not treated as a number.
+func evalMaxLen(args []string, index int, _ interface{}) (interface{}, error) {
+	max, err := strconv.Atoi(args[0])
+	if err != nil || max < 0 {
+		return nil, fmt.Errorf("invalid maximum length %q at argument %d", args[0], index)
+	}
+	s := args[1]
+	if n := utf8.RuneCountInString(s); n > max {
+		return nil, fmt.Errorf("string at argument %d has %d characters, more than the maximum of %d", index+1, n, max)
+	}
+	return s, nil
+}
+
+// evalMaxBytes implements the maxbytes keyword. Like maxlen,
+// it takes the string literally.
+func evalMaxBytes(args []string, index int, _ interface{}) (interface{}, error) {
+	max, err := parseSize(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid maximum size at argument %d: %v", index, err)
+	}
+	s := args[1]
+	if len(s) > max {
+		return nil, fmt.Errorf("string at argument %d has %d bytes, more than the maximum of %d", index+1, len(s), max)
+	}
+	return s, nil
+}
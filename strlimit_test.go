@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var strLimitTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "maxlen-ok",
+	args:     "maxlen 5 héllo",
+	expect:   "héllo",
+}, {
+	testName:    "maxlen-too-long",
+	args:        "maxlen 5 abcdef",
+	expectError: `string at argument 2 has 6 characters, more than the maximum of 5`,
+}, {
+	testName: "maxlen-digits",
+	args:     "maxlen 5 01234",
+	expect:   "01234",
+}, {
+	testName:    "maxlen-digits-too-long",
+	args:        "maxlen 5 012345",
+	expectError: `string at argument 2 has 6 characters, more than the maximum of 5`,
+}, {
+	testName:    "maxlen-invalid",
+	args:        "maxlen -1 x",
+	expectError: `invalid maximum length "-1" at argument 1`,
+}, {
+	testName: "maxbytes-ok",
+	args:     "maxbytes 1KB 12",
+	expect:   "12",
+}, {
+	testName:    "maxbytes-multibyte",
+	args:        "maxbytes 5 héllo",
+	expectError: `string at argument 2 has 6 bytes, more than the maximum of 5`,
+}, {
+	testName:    "maxbytes-invalid",
+	args:        "maxbytes 5XB x",
+	expectError: `invalid maximum size at argument 1: invalid size "5XB"`,
+}}
+
+func TestStrLimit(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range strLimitTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
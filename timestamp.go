@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts holds the layouts accepted by the time keyword.
+// Times without a time zone are taken to be in UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC850,
+	time.RFC822,
+	time.RFC822Z,
+	time.ANSIC,
+	time.UnixDate,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+var epochPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// parseTime parses s as a time in one of timeLayouts
+// or as a number of seconds since the Unix epoch.
+func parseTime(s string) (time.Time, error) {
+	if epochPattern.MatchString(s) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return epochTime(f)
+	}
+	for _, layout := range timeLayouts {
+		// Parse in UTC so that the result does not depend
+		// on the local time zone.
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time format")
+}
+
+// epochTime returns the time f seconds after the Unix epoch.
+func epochTime(f float64) (time.Time, error) {
+	if math.Abs(f) > 1<<53 {
+		return time.Time{}, fmt.Errorf("time out of range")
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))), nil
+}
+
+func evalTime(_ []string, index int, v interface{}) (interface{}, error) {
+	var t time.Time
+	var err error
+	switch v := v.(type) {
+	case string:
+		t, err = parseTime(strings.TrimSpace(v))
+	case float64, json.Number:
+		t, err = epochTime(floatValue(v))
+	default:
+		return nil, fmt.Errorf("time value at argument %d is %s, not string or number", index, valueKind(v))
+	}
+	if err == nil {
+		if y := t.UTC().Year(); y < 0 || y > 9999 {
+			err = fmt.Errorf("year %d out of range", y)
+		}
+	}
+	if err != nil {
+		text, _ := scalarText(v)
+		return nil, fmt.Errorf("invalid time %q at argument %d: %v", text, index, err)
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
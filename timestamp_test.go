@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var timeTests = []struct {
+	testName    string
+	args        []string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "rfc3339",
+	args:     []string{"time", "2006-01-02T15:04:05.25+02:00"},
+	expect:   "2006-01-02T13:04:05.25Z",
+}, {
+	testName: "rfc1123",
+	args:     []string{"time", "Mon, 02 Jan 2006 15:04:05 GMT"},
+	expect:   "2006-01-02T15:04:05Z",
+}, {
+	testName: "rfc1123z",
+	args:     []string{"time", "Mon, 02 Jan 2006 15:04:05 -0700"},
+	expect:   "2006-01-02T22:04:05Z",
+}, {
+	testName: "date",
+	args:     []string{"time", "2006-01-02"},
+	expect:   "2006-01-02T00:00:00Z",
+}, {
+	testName: "date-time-without-zone",
+	args:     []string{"time", "2006-01-02 15:04:05"},
+	expect:   "2006-01-02T15:04:05Z",
+}, {
+	testName: "unix-date",
+	args:     []string{"time", "Mon Jan  2 15:04:05 UTC 2006"},
+	expect:   "2006-01-02T15:04:05Z",
+}, {
+	testName: "epoch-number",
+	args:     []string{"time", "1136214245"},
+	expect:   "2006-01-02T15:04:05Z",
+}, {
+	testName: "epoch-fraction",
+	args:     []string{"time", "num", "1136214245.125"},
+	expect:   "2006-01-02T15:04:05.125Z",
+}, {
+	testName: "epoch-string",
+	args:     []string{"time", "str", "-1"},
+	expect:   "1969-12-31T23:59:59Z",
+}, {
+	testName: "in-object",
+	args:     []string{"a:", "time", "2006-01-02", "b:", "1"},
+	expect:   map[string]interface{}{"a": "2006-01-02T00:00:00Z", "b": 1.0},
+}, {
+	testName:    "garbage",
+	args:        []string{"time", "yesterday"},
+	expectError: `invalid time "yesterday" at argument 1: unrecognized time format`,
+}, {
+	testName:    "invalid-date",
+	args:        []string{"time", "2006-02-30"},
+	expectError: `invalid time "2006-02-30" at argument 1: unrecognized time format`,
+}, {
+	testName:    "out-of-range",
+	args:        []string{"time", "1e15"},
+	expectError: `invalid time "1000000000000000" at argument 1: year .* out of range`,
+}, {
+	testName:    "too-large",
+	args:        []string{"time", "num", "1e300"},
+	expectError: `invalid time "1e300" at argument 1: time out of range`,
+}, {
+	testName:    "not-scalar",
+	args:        []string{"time", ".[", "]"},
+	expectError: `time value at argument 1 is array, not string or number`,
+}}
+
+func TestTime(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range timeTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(test.args).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
+
+func TestEpochTimeNumber(t *testing.T) {
+	c := qt.New(t)
+	v, err := evalTime(nil, 1, json.Number("0.5"))
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.Equals, "1970-01-01T00:00:00.5Z")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML parses s as a TOML document and returns
+// the equivalent JSON object. Numbers are returned as
+// json.Number and date-times as RFC 3339 strings.
+func parseTOML(s string) (interface{}, error) {
+	var x map[string]interface{}
+	if _, err := toml.Decode(s, &x); err != nil {
+		return nil, err
+	}
+	return tomlToJSON(x)
+}
+
+func tomlToJSON(x interface{}) (interface{}, error) {
+	switch x := x.(type) {
+	case bool, string:
+		return x, nil
+	case int64:
+		return json.Number(strconv.FormatInt(x, 10)), nil
+	case float64:
+		n, err := floatNumber(x)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	case []interface{}:
+		elems := make([]interface{}, len(x))
+		for i, elem := range x {
+			v, err := tomlToJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = v
+		}
+		return elems, nil
+	case []map[string]interface{}:
+		// An array of tables.
+		elems := make([]interface{}, len(x))
+		for i, elem := range x {
+			v, err := tomlToJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = v
+		}
+		return elems, nil
+	case map[string]interface{}:
+		obj := make(map[string]interface{})
+		for k, elem := range x {
+			v, err := tomlToJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = v
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("unexpected TOML value of type %T", x)
+}
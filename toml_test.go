@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var tomlTests = []struct {
+	testName    string
+	toml        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "scalars",
+	toml: `
+a = true
+b = 12
+c = 1.5
+d = "x"
+e = 1979-05-27T07:32:00Z
+f = [1, 2]
+`,
+	expect: map[string]interface{}{
+		"a": true,
+		"b": json.Number("12"),
+		"c": json.Number("1.5"),
+		"d": "x",
+		"e": "1979-05-27T07:32:00Z",
+		"f": []interface{}{json.Number("1"), json.Number("2")},
+	},
+}, {
+	testName: "tables",
+	toml: `
+[server]
+host = "example.com"
+
+[[users]]
+name = "a"
+
+[[users]]
+name = "b"
+`,
+	expect: map[string]interface{}{
+		"server": map[string]interface{}{
+			"host": "example.com",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b"},
+		},
+	},
+}, {
+	testName:    "invalid",
+	toml:        "a = ",
+	expectError: `.+`,
+}}
+
+func TestParseTOML(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range tomlTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseTOML(test.toml)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
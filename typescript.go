@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var typescript = flag.Bool("typescript", false, "instead of printing the values, print a TypeScript type declaration describing all of them")
+
+func newTypeScriptEncoder(w io.Writer) encoder {
+	return &inferEncoder{
+		t: newInferredType(),
+		print: func(t *inferredType) error {
+			var buf bytes.Buffer
+			if t.object != nil && t.kinds() == 1 && !t.null {
+				buf.WriteString("interface " + *typeName + " ")
+				writeTypeScriptObject(&buf, t.object, "")
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString("type " + *typeName + " = ")
+				writeTypeScriptType(&buf, t, "")
+				buf.WriteString(";\n")
+			}
+			_, err := w.Write(buf.Bytes())
+			return err
+		},
+	}
+}
+
+func writeTypeScriptType(buf *bytes.Buffer, t *inferredType, indent string) {
+	var alts []string
+	add := func(ok bool, name string) {
+		if ok {
+			alts = append(alts, name)
+		}
+	}
+	add(t.bool, "boolean")
+	add(t.number, "number")
+	add(t.string, "string")
+	if t.array != nil {
+		var elem bytes.Buffer
+		writeTypeScriptType(&elem, t.array, indent)
+		if t.array.kinds()+boolToInt(t.array.null) > 1 {
+			alts = append(alts, "("+elem.String()+")[]")
+		} else {
+			alts = append(alts, elem.String()+"[]")
+		}
+	}
+	if t.object != nil {
+		var obj bytes.Buffer
+		writeTypeScriptObject(&obj, t.object, indent)
+		alts = append(alts, obj.String())
+	}
+	add(t.null, "null")
+	if len(alts) == 0 {
+		// No values seen at all (for example the
+		// elements of an empty array).
+		alts = []string{"unknown"}
+	}
+	buf.WriteString(strings.Join(alts, " | "))
+}
+
+func writeTypeScriptObject(buf *bytes.Buffer, o *objectType, indent string) {
+	if len(o.fields) == 0 {
+		buf.WriteString("{}")
+		return
+	}
+	buf.WriteString("{\n")
+	for _, k := range o.keys() {
+		buf.WriteString(indent + "\t" + typeScriptPropertyName(k))
+		if o.optional(k) {
+			buf.WriteString("?")
+		}
+		buf.WriteString(": ")
+		writeTypeScriptType(buf, o.fields[k], indent+"\t")
+		buf.WriteString(";\n")
+	}
+	buf.WriteString(indent + "}")
+}
+
+var typeScriptIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func typeScriptPropertyName(k string) string {
+	if typeScriptIdentifier.MatchString(k) {
+		return k
+	}
+	return strconv.Quote(k)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
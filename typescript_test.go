@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var typeScriptTests = []struct {
+	testName string
+	vals     []interface{}
+	expect   string
+}{{
+	testName: "scalars",
+	vals:     []interface{}{"x", 1.0, nil},
+	expect:   "type Value = number | string | null;\n",
+}, {
+	testName: "object",
+	vals: []interface{}{
+		map[string]interface{}{
+			"a":   1.0,
+			"b":   []interface{}{"x", true},
+			"c-d": map[string]interface{}{"e": nil},
+		},
+		map[string]interface{}{
+			"a": 2.0,
+			"f": []interface{}{},
+		},
+	},
+	expect: `interface Value {
+	a: number;
+	b?: (boolean | string)[];
+	"c-d"?: {
+		e: null;
+	};
+	f?: unknown[];
+}
+`,
+}, {
+	testName: "nullable-object",
+	vals: []interface{}{
+		map[string]interface{}{},
+		nil,
+	},
+	expect: "type Value = {} | null;\n",
+}}
+
+func TestTypeScript(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range typeScriptTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var buf bytes.Buffer
+			enc := newTypeScriptEncoder(&buf).(encoderCloser)
+			for _, v := range test.vals {
+				c.Assert(enc.Encode(v), qt.Equals, nil)
+			}
+			c.Assert(buf.Len(), qt.Equals, 0)
+			c.Assert(enc.Close(), qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
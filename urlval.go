@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// normalizeURL parses s as an absolute URL and returns it with
+// its scheme and host in lower case and any dot segments
+// in its path resolved.
+func normalizeURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		// Remove the redundant prefix from the url.Error.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return nil, err
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("no scheme")
+	}
+	if u.Opaque == "" && u.Host == "" {
+		return nil, fmt.Errorf("no host")
+	}
+	u.Host = strings.ToLower(u.Host)
+	// Resolving an empty reference removes dot segments.
+	return u.ResolveReference(&url.URL{}), nil
+}
+
+// urlParts returns an object holding the components of u.
+// Empty components are omitted.
+func urlParts(u *url.URL) (interface{}, error) {
+	parts := map[string]interface{}{
+		"scheme": u.Scheme,
+	}
+	add := func(k, v string) {
+		if v != "" {
+			parts[k] = v
+		}
+	}
+	add("opaque", u.Opaque)
+	if u.User != nil {
+		add("user", u.User.Username())
+	}
+	add("host", u.Hostname())
+	if port := u.Port(); port != "" {
+		parts["port"] = json.Number(port)
+	}
+	add("path", u.Path)
+	if u.RawQuery != "" {
+		query, err := parseQuery(u.RawQuery)
+		if err != nil {
+			return nil, err
+		}
+		parts["query"] = query
+	}
+	add("fragment", u.Fragment)
+	return parts, nil
+}
+
+func evalURL(name string, parts bool) func([]string, int, interface{}) (interface{}, error) {
+	return func(_ []string, index int, v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s value at argument %d is %s, not string", name, index, valueKind(v))
+		}
+		u, err := normalizeURL(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q at argument %d: %v", s, index, err)
+		}
+		if !parts {
+			return u.String(), nil
+		}
+		v, err = urlParts(u)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q at argument %d: %v", s, index, err)
+		}
+		return v, nil
+	}
+}
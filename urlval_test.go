@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var urlValTests = []struct {
+	testName    string
+	args        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "normalize",
+	args:     "urlval HTTPS://Example.COM:8443/a/./b/../c?q=1#Frag",
+	expect:   "https://example.com:8443/a/c?q=1#Frag",
+}, {
+	testName: "opaque",
+	args:     "urlval mailto:jo@example.com",
+	expect:   "mailto:jo@example.com",
+}, {
+	testName:    "no-scheme",
+	args:        "urlval example.com/x",
+	expectError: `invalid URL "example.com/x" at argument 1: no scheme`,
+}, {
+	testName:    "no-host",
+	args:        "urlval http:///x",
+	expectError: `invalid URL "http:///x" at argument 1: no host`,
+}, {
+	testName:    "bad-escape",
+	args:        "urlval http://x/%zz",
+	expectError: `invalid URL "http://x/%zz" at argument 1: invalid URL escape "%zz"`,
+}, {
+	testName:    "not-string",
+	args:        "urlparts 1",
+	expectError: `urlparts value at argument 1 is number, not string`,
+}, {
+	testName: "parts",
+	args:     "urlparts http://bob@DB:5432/x/../y?a=1&a=2#top",
+	expect: map[string]interface{}{
+		"scheme":   "http",
+		"user":     "bob",
+		"host":     "db",
+		"port":     json.Number("5432"),
+		"path":     "/y",
+		"query":    map[string]interface{}{"a": []interface{}{"1", "2"}},
+		"fragment": "top",
+	},
+}, {
+	testName: "parts-minimal",
+	args:     "urlparts https://example.com",
+	expect: map[string]interface{}{
+		"scheme": "https",
+		"host":   "example.com",
+	},
+}}
+
+func TestURLVal(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range urlValTests {
+		c.Run(test.testName, func(c *qt.C) {
+			vals, err := newParser(strings.Fields(test.args)).run()
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(vals, qt.DeepEquals, []interface{}{test.expect})
+		})
+	}
+}
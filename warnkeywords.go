@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"regexp"
+	"sort"
+)
+
+var warnKeywords = flag.Bool("warn-keywords", false, "warn about string values that look like mistyped keywords")
+
+var keywordLike = regexp.MustCompile(`^[a-z]+$`)
+
+// checkKeyword warns if the string value a at the given
+// argument index might be a mistyped keyword: it is a lower
+// case word followed by an argument that is not a key or a
+// closing delimiter, and so could have been intended as the
+// keyword's argument.
+func (p *parser) checkKeyword(a string, index int) {
+	next, ok := p.peek()
+	if !ok || next == "]" || isKey(next) || !keywordLike.MatchString(a) {
+		return
+	}
+	if kw := closestKeyword(a); kw != "" {
+		p.warnf("argument %d (%q) may be a mistyped keyword; did you mean %q?", index, a, kw)
+		return
+	}
+	p.warnf("argument %d (%q) may be a mistyped keyword", index, a)
+}
+
+// closestKeyword returns the keyword closest to s, or
+// the empty string if there is no keyword within an edit
+// distance of 2.
+func closestKeyword(s string) string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	best, bestDist := "", 3
+	for _, name := range names {
+		if d := editDistance(s, name); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Damerau-Levenshtein (optimal string
+// alignment) distance between a and b, so that transposed
+// letters, as in "nmu", count as a single edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				if t := d[i-2][j-2] + 1; t < d[i][j] {
+					d[i][j] = t
+				}
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
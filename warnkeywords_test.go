@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var warnKeywordsTests = []struct {
+	testName string
+	args     string
+	expect   []string
+}{{
+	testName: "transposed",
+	args:     "nmu 5",
+	expect:   []string{`argument 0 ("nmu") may be a mistyped keyword; did you mean "num"?`},
+}, {
+	testName: "no-suggestion",
+	args:     "hello world",
+	expect:   []string{`argument 0 ("hello") may be a mistyped keyword`},
+}, {
+	testName: "followed-by-key",
+	args:     "a: nmu b: 5",
+}, {
+	testName: "followed-by-close",
+	args:     ".[ xyzzy nmu ]",
+	expect:   []string{`argument 1 ("xyzzy") may be a mistyped keyword`},
+}, {
+	testName: "last-argument",
+	args:     "nmu",
+}, {
+	testName: "not-lower-case",
+	args:     "Num 5 x-y 6",
+}, {
+	testName: "keyword-argument",
+	args:     "str nmu 5",
+}, {
+	testName: "counter",
+	args:     "countr x",
+	expect:   []string{`argument 0 ("countr") may be a mistyped keyword; did you mean "counter"?`},
+}, {
+	testName: "float",
+	args:     "flaot 1",
+	expect:   []string{`argument 0 ("flaot") may be a mistyped keyword; did you mean "float"?`},
+}, {
+	testName: "stdin",
+	args:     ".[ stdin x ]",
+}}
+
+func TestWarnKeywords(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range warnKeywordsTests {
+		c.Run(test.testName, func(c *qt.C) {
+			var warnings []string
+			p := newParser(strings.Fields(test.args))
+			p.stdin = strings.NewReader("hi")
+			p.warnf = func(format string, arg ...interface{}) {
+				warnings = append(warnings, fmt.Sprintf(format, arg...))
+			}
+			_, err := p.run()
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(warnings, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(editDistance("nmu", "num"), qt.Equals, 1)
+	c.Assert(editDistance("", "abc"), qt.Equals, 3)
+	c.Assert(editDistance("strr", "str"), qt.Equals, 1)
+	c.Assert(editDistance("kitten", "sitting"), qt.Equals, 3)
+}
@@ -0,0 +1,48 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "syscall/js"
+
+const jsBuild = true
+
+// argClassNames holds the names used for argument
+// classes in the result of jsonArgs.
+var argClassNames = map[argClass]string{
+	classValue:   "value",
+	classKey:     "key",
+	classDelim:   "delim",
+	classKeyword: "keyword",
+}
+
+// serveJS registers the jsonArgs function for use from JavaScript
+// and waits forever. jsonArgs takes an array of argument strings
+// and returns an object holding the JSON output ("output") or
+// an error message ("error"), and the syntactic class of each
+// argument ("classes"). Keywords that perform I/O or are
+// nondeterministic are rejected as if -hermetic had been given.
+func serveJS() {
+	js.Global().Set("jsonArgs", js.FuncOf(func(this js.Value, jsArgs []js.Value) interface{} {
+		var args []string
+		if len(jsArgs) > 0 {
+			for i, n := 0, jsArgs[0].Length(); i < n; i++ {
+				args = append(args, jsArgs[0].Index(i).String())
+			}
+		}
+		output, classes, err := evalArgs(args, true)
+		classNames := make([]interface{}, len(classes))
+		for i, class := range classes {
+			classNames[i] = argClassNames[class]
+		}
+		result := map[string]interface{}{
+			"output":  output,
+			"classes": classNames,
+		}
+		if err != nil {
+			result["error"] = err.Error()
+		}
+		return result
+	}))
+	select {}
+}
@@ -0,0 +1,8 @@
+//go:build !js
+// +build !js
+
+package main
+
+const jsBuild = false
+
+func serveJS() {}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	xmlRoot  = flag.String("xml-root", "root", "name of the root element in XML output")
+	xmlAttrs = flag.Bool("xml-attrs", false, "in XML output, encode object members with non-composite values as attributes rather than elements")
+)
+
+// xmlEncoder encodes values as XML documents. Each value
+// is encoded as a root element. Object members become child
+// elements (or attributes when -xml-attrs is set), and each
+// element of an array member is encoded as a separate element
+// named after the member. Other array elements are named "item".
+type xmlEncoder struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func newXMLEncoder(w io.Writer) encoder {
+	enc := xml.NewEncoder(w)
+	if *indent {
+		enc.Indent("", "\t")
+	}
+	return &xmlEncoder{
+		w:   w,
+		enc: enc,
+	}
+}
+
+func (e *xmlEncoder) Encode(v interface{}) error {
+	if err := e.encodeElement(*xmlRoot, v); err != nil {
+		return err
+	}
+	if err := e.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *xmlEncoder) encodeElement(name string, v interface{}) error {
+	if !isXMLName(name) {
+		return fmt.Errorf("cannot use %q as an XML element name", name)
+	}
+	start := xml.StartElement{
+		Name: xml.Name{Local: name},
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		var elems []string
+		for _, k := range keys {
+			if !*xmlAttrs || isComposite(v[k]) {
+				elems = append(elems, k)
+				continue
+			}
+			if !isXMLName(k) {
+				return fmt.Errorf("cannot use %q as an XML attribute name", k)
+			}
+			text, err := scalarText(v[k])
+			if err != nil {
+				return err
+			}
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: k},
+				Value: text,
+			})
+		}
+		if err := e.enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, k := range elems {
+			if err := e.encodeMember(k, v[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if err := e.enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if err := e.encodeElement("item", elem); err != nil {
+				return err
+			}
+		}
+	default:
+		text, err := scalarText(v)
+		if err != nil {
+			return err
+		}
+		if err := e.enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if text != "" {
+			if err := e.enc.EncodeToken(xml.CharData(text)); err != nil {
+				return err
+			}
+		}
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+// encodeMember encodes the object member with the given key and value.
+func (e *xmlEncoder) encodeMember(k string, v interface{}) error {
+	elems, ok := v.([]interface{})
+	if !ok {
+		return e.encodeElement(k, v)
+	}
+	for _, elem := range elems {
+		if err := e.encodeElement(k, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isComposite(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// isXMLName reports whether s is a valid XML name
+// not containing a namespace prefix.
+func isXMLName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return utf8.ValidString(s)
+}
+
+// parseXML parses s as an XML document and converts it to an
+// object with a single member named after the root element.
+// An element with no attributes or child elements is converted
+// to its text. Otherwise it's converted to an object holding
+// its attributes as members with an "@" prefix, its child
+// elements as members named after them (an array when an element
+// name occurs more than once) and any text not consisting
+// entirely of white space as the "#text" member. Namespaces
+// are ignored.
+func parseXML(s string) (interface{}, error) {
+	type element struct {
+		name string
+		obj  map[string]interface{}
+		text strings.Builder
+	}
+	var root map[string]interface{}
+	var stack []*element
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && root != nil {
+				return nil, fmt.Errorf("more than one root element")
+			}
+			e := &element{
+				name: tok.Name.Local,
+				obj:  make(map[string]interface{}),
+			}
+			for _, attr := range tok.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					continue
+				}
+				e.obj["@"+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, e)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(tok)
+			} else if len(bytes.TrimSpace(tok)) > 0 {
+				return nil, fmt.Errorf("text outside root element")
+			}
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			var v interface{} = e.text.String()
+			if len(e.obj) > 0 {
+				if text := strings.TrimSpace(e.text.String()); text != "" {
+					e.obj["#text"] = text
+				}
+				v = e.obj
+			}
+			if len(stack) == 0 {
+				root = map[string]interface{}{e.name: v}
+				break
+			}
+			parent := stack[len(stack)-1].obj
+			switch old := parent[e.name].(type) {
+			case nil:
+				parent[e.name] = v
+			case []interface{}:
+				parent[e.name] = append(old, v)
+			default:
+				parent[e.name] = []interface{}{old, v}
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var xmlTests = []struct {
+	testName    string
+	root        string
+	attrs       bool
+	val         interface{}
+	expect      string
+	expectError string
+}{{
+	testName: "scalar",
+	root:     "root",
+	val:      "a<b",
+	expect:   "<root>a&lt;b</root>\n",
+}, {
+	testName: "object",
+	root:     "doc",
+	val: map[string]interface{}{
+		"b": []interface{}{1.0, "two"},
+		"a": map[string]interface{}{"x": nil},
+	},
+	expect: "<doc><a><x></x></a><b>1</b><b>two</b></doc>\n",
+}, {
+	testName: "top-level-array",
+	root:     "root",
+	val:      []interface{}{true, []interface{}{1.0}},
+	expect:   "<root><item>true</item><item><item>1</item></item></root>\n",
+}, {
+	testName: "attributes",
+	root:     "root",
+	attrs:    true,
+	val: map[string]interface{}{
+		"id":   1.0,
+		"name": `"x"`,
+		"tags": []interface{}{"a"},
+	},
+	expect: `<root id="1" name="&#34;x&#34;"><tags>a</tags></root>` + "\n",
+}, {
+	testName:    "invalid-key",
+	root:        "root",
+	val:         map[string]interface{}{"1x": 1.0},
+	expectError: `cannot use "1x" as an XML element name`,
+}}
+
+func TestXMLEncode(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range xmlTests {
+		c.Run(test.testName, func(c *qt.C) {
+			c.Patch(xmlRoot, test.root)
+			c.Patch(xmlAttrs, test.attrs)
+			var buf bytes.Buffer
+			err := newXMLEncoder(&buf).Encode(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(buf.String(), qt.Equals, test.expect)
+		})
+	}
+}
+
+var parseXMLTests = []struct {
+	testName    string
+	xml         string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "text-only",
+	xml:      `<?xml version="1.0"?><a> x </a>`,
+	expect:   map[string]interface{}{"a": " x "},
+}, {
+	testName: "empty",
+	xml:      `<a/>`,
+	expect:   map[string]interface{}{"a": ""},
+}, {
+	testName: "attributes-children-and-text",
+	xml: `<root xmlns="urn:x" xmlns:p="urn:p" id="1">
+	hello
+	<p:item n="a"/>
+	<item>b</item>
+	<item>c</item>
+	<!-- comment -->
+	<other><x>1</x></other>
+</root>
+`,
+	expect: map[string]interface{}{
+		"root": map[string]interface{}{
+			"@id":   "1",
+			"#text": "hello",
+			"item": []interface{}{
+				map[string]interface{}{"@n": "a"},
+				"b",
+				"c",
+			},
+			"other": map[string]interface{}{
+				"x": "1",
+			},
+		},
+	},
+}, {
+	testName:    "no-root",
+	xml:         ` `,
+	expectError: `no root element`,
+}, {
+	testName:    "two-roots",
+	xml:         `<a/><b/>`,
+	expectError: `more than one root element`,
+}, {
+	testName:    "trailing-text",
+	xml:         `<a/>x`,
+	expectError: `text outside root element`,
+}, {
+	testName:    "unclosed",
+	xml:         `<a>`,
+	expectError: `XML syntax error on line 1: unexpected EOF`,
+}}
+
+func TestParseXML(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range parseXMLTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseXML(test.xml)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// parseYAML parses s as a YAML document and returns the
+// equivalent JSON value. Map keys that are not strings are
+// converted to their textual representation, and numbers
+// are returned as json.Number.
+func parseYAML(s string) (interface{}, error) {
+	var x interface{}
+	if err := yaml.Unmarshal([]byte(s), &x); err != nil {
+		return nil, err
+	}
+	return yamlToJSON(x)
+}
+
+func yamlToJSON(x interface{}) (interface{}, error) {
+	switch x := x.(type) {
+	case nil, bool, string:
+		return x, nil
+	case int:
+		return json.Number(strconv.Itoa(x)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(x, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(x, 10)), nil
+	case float64:
+		n, err := floatNumber(x)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case []interface{}:
+		elems := make([]interface{}, len(x))
+		for i, elem := range x {
+			v, err := yamlToJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = v
+		}
+		return elems, nil
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{})
+		for k, elem := range x {
+			key, err := yamlKey(k)
+			if err != nil {
+				return nil, err
+			}
+			v, err := yamlToJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("unexpected YAML value of type %T", x)
+}
+
+// yamlKey returns the string used as the JSON object key
+// for the YAML map key k.
+func yamlKey(k interface{}) (string, error) {
+	switch k := k.(type) {
+	case string:
+		return k, nil
+	case nil:
+		return "null", nil
+	}
+	v, err := yamlToJSON(k)
+	if err != nil {
+		return "", err
+	}
+	return scalarText(v)
+}
+
+// yamlEncoder writes values as a stream of YAML documents.
+type yamlEncoder struct {
+	w io.Writer
+	n int
+}
+
+func newYAMLEncoder(w io.Writer) encoder {
+	return &yamlEncoder{w: w}
+}
+
+func (e *yamlEncoder) Encode(v interface{}) error {
+	data, err := yaml.Marshal(jsonToYAML(v))
+	if err != nil {
+		return err
+	}
+	if e.n > 0 {
+		data = append([]byte("---\n"), data...)
+	}
+	e.n++
+	_, err = e.w.Write(data)
+	return err
+}
+
+// jsonToYAML returns v with its numbers converted to
+// Go numeric types so that they are written as YAML
+// numbers rather than strings.
+func jsonToYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+			return f
+		}
+	case []interface{}:
+		elems := make([]interface{}, len(v))
+		for i, elem := range v {
+			elems[i] = jsonToYAML(elem)
+		}
+		return elems
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			obj[k] = jsonToYAML(elem)
+		}
+		return obj
+	}
+	return v
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var yamlTests = []struct {
+	testName    string
+	yaml        string
+	expect      interface{}
+	expectError string
+}{{
+	testName: "scalars",
+	yaml:     "[null, true, 1, -2, 1.5, 1e100, hello, '1']",
+	expect:   []interface{}{nil, true, json.Number("1"), json.Number("-2"), json.Number("1.5"), json.Number("1e+100"), "hello", "1"},
+}, {
+	testName: "nested",
+	yaml: `
+a:
+  b: [x, z]
+c: 18446744073709551615
+`,
+	expect: map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": []interface{}{"x", "z"},
+		},
+		"c": json.Number("18446744073709551615"),
+	},
+}, {
+	testName: "non-string-keys",
+	yaml:     "{1: a, true: b, ~: c, 2.5: d}",
+	expect: map[string]interface{}{
+		"1":    "a",
+		"true": "b",
+		"null": "c",
+		"2.5":  "d",
+	},
+}, {
+	testName:    "composite-key",
+	yaml:        "{[1, 2]: a}",
+	expectError: `yaml: invalid map key: .*`,
+}, {
+	testName:    "infinity",
+	yaml:        ".inf",
+	expectError: `\+Inf cannot be represented in JSON`,
+}, {
+	testName:    "invalid",
+	yaml:        "a: [",
+	expectError: `yaml: .*`,
+}}
+
+func TestParseYAML(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range yamlTests {
+		c.Run(test.testName, func(c *qt.C) {
+			v, err := parseYAML(test.yaml)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.Equals, nil)
+			c.Assert(v, qt.DeepEquals, test.expect)
+		})
+	}
+}
+
+func TestYAMLEncoder(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	enc := newYAMLEncoder(&buf)
+	vals := []interface{}{
+		map[string]interface{}{
+			"a": []interface{}{json.Number("1"), json.Number("18446744073709551615"), 2.5, "3", true, nil},
+			"b": map[string]interface{}{"c": "d"},
+		},
+		"x",
+	}
+	for _, v := range vals {
+		c.Assert(enc.Encode(v), qt.Equals, nil)
+	}
+	c.Assert(buf.String(), qt.Equals, `a:
+- 1
+- 18446744073709551615
+- 2.5
+- "3"
+- true
+- null
+b:
+  c: d
+---
+x
+`)
+	v, err := parseYAML(strings.Split(buf.String(), "---\n")[0])
+	c.Assert(err, qt.Equals, nil)
+	c.Assert(v, qt.DeepEquals, map[string]interface{}{
+		"a": []interface{}{json.Number("1"), json.Number("18446744073709551615"), json.Number("2.5"), "3", true, nil},
+		"b": map[string]interface{}{"c": "d"},
+	})
+}